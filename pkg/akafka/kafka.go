@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
@@ -17,21 +18,36 @@ import (
 // KafkaBroker é a interface que define os métodos necessários para um broker Kafka
 type KafkaBroker interface {
 	Publish(topic string, message []byte) error
+	PublishBatch(messages []Message) error
 	Close() error
 	Consume(topics []string, msgChan chan *kafka.Message)
+	// ConsumeBroadcast entrega todas as mensagens dos tópicos a cada instância, e não a apenas uma do grupo
+	ConsumeBroadcast(topics []string, msgChan chan *kafka.Message)
 	CreateTopicsIfNotExists(topics []string) error
 }
 
+// Message é uma mensagem publicada em lote; cada uma pode ir para um tópico diferente
+type Message struct {
+	Topic string
+	Value []byte
+}
+
+// QueueDepthReporter é implementado por brokers que sabem quantas mensagens aguardam envio
+type QueueDepthReporter interface {
+	QueueDepth() int
+}
+
 // KafkaBrokerImpl é a implementação concreta do KafkaBroker
 type KafkaBrokerImpl struct {
 	brokerURL string
 	writer    *kafka.Writer
 	mu        sync.Mutex
 	logger    *zap.Logger
+	pending   atomic.Int64
 }
 
 func NewKafkaBroker(brokerURL string) KafkaBroker {
-	return &KafkaBrokerImpl{
+	k := &KafkaBrokerImpl{
 		brokerURL: brokerURL,
 		writer: kafka.NewWriter(kafka.WriterConfig{
 			Brokers:      []string{brokerURL},
@@ -42,6 +58,16 @@ func NewKafkaBroker(brokerURL string) KafkaBroker {
 		}),
 		logger: logger.Log,
 	}
+	// o writer é assíncrono: a mensagem sai da fila só quando o lote dela é confirmado ou falha
+	k.writer.Completion = func(messages []kafka.Message, err error) {
+		k.pending.Add(-int64(len(messages)))
+	}
+	return k
+}
+
+// QueueDepth devolve quantas mensagens foram aceitas pelo writer e ainda não foram confirmadas pelo Kafka
+func (k *KafkaBrokerImpl) QueueDepth() int {
+	return int(k.pending.Load())
 }
 
 func (k *KafkaBrokerImpl) Publish(topic string, message []byte) error {
@@ -49,6 +75,7 @@ func (k *KafkaBrokerImpl) Publish(topic string, message []byte) error {
 	defer k.mu.Unlock()
 
 	k.writer.Topic = topic
+	k.pending.Add(1)
 	err := k.writer.WriteMessages(context.Background(),
 		kafka.Message{
 			Value: message,
@@ -56,6 +83,7 @@ func (k *KafkaBrokerImpl) Publish(topic string, message []byte) error {
 	)
 
 	if err != nil {
+		k.pending.Add(-1)
 		k.logger.Error("erro ao publicar mensagem",
 			zap.Error(err),
 			zap.String("topic", topic),
@@ -69,15 +97,118 @@ func (k *KafkaBrokerImpl) Publish(topic string, message []byte) error {
 	return nil
 }
 
+// PublishBatch envia todas as mensagens numa única escrita do writer
+func (k *KafkaBrokerImpl) PublishBatch(messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// o tópico vai em cada mensagem, então o writer não pode ter um tópico fixo
+	k.writer.Topic = ""
+	batch := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		batch[i] = kafka.Message{Topic: message.Topic, Value: message.Value}
+	}
+
+	k.pending.Add(int64(len(batch)))
+	if err := k.writer.WriteMessages(context.Background(), batch...); err != nil {
+		k.pending.Add(-int64(len(batch)))
+		k.logger.Error("erro ao publicar lote de mensagens",
+			zap.Error(err),
+			zap.Int("count", len(messages)),
+		)
+		return fmt.Errorf("erro ao publicar lote de mensagens: %v", err)
+	}
+
+	k.logger.Info("lote de mensagens publicado com sucesso",
+		zap.Int("count", len(messages)),
+	)
+	return nil
+}
+
 func (k *KafkaBrokerImpl) Close() error {
 	return k.writer.Close()
 }
 
 func (k *KafkaBrokerImpl) Consume(topics []string, msgChan chan *kafka.Message) {
+	k.consume("transaction-group", topics, msgChan)
+}
+
+// ConsumeBroadcast lê cada partição dos tópicos sem grupo de consumo, a partir da última offset: a instância
+// recebe só o que for publicado depois de subir, e nada fica registrado no broker. Partições criadas
+// depois da chamada não são lidas.
+func (k *KafkaBrokerImpl) ConsumeBroadcast(topics []string, msgChan chan *kafka.Message) {
+	partitions := k.readPartitions(topics)
+
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k.consumePartition(partition.Topic, partition.ID, msgChan)
+		}()
+	}
+	wg.Wait()
+}
+
+// readPartitions tenta até conseguir, como a leitura das mensagens, já que o consumo roda numa goroutine própria
+func (k *KafkaBrokerImpl) readPartitions(topics []string) []kafka.Partition {
+	for {
+		conn, err := kafka.Dial("tcp", k.brokerURL)
+		if err == nil {
+			var partitions []kafka.Partition
+			partitions, err = conn.ReadPartitions(topics...)
+			conn.Close()
+			if err == nil {
+				return partitions
+			}
+		}
+
+		k.logger.Error("erro ao ler partições",
+			zap.Error(err),
+			zap.Strings("topics", topics),
+		)
+		time.Sleep(time.Second)
+	}
+}
+
+func (k *KafkaBrokerImpl) consumePartition(topic string, partition int, msgChan chan *kafka.Message) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   []string{k.brokerURL},
+		Topic:     topic,
+		Partition: partition,
+		MaxWait:   1 * time.Second,
+	})
+	if err := reader.SetOffset(kafka.LastOffset); err != nil {
+		k.logger.Error("erro ao posicionar leitura",
+			zap.Error(err),
+			zap.String("topic", topic),
+			zap.Int("partition", partition),
+		)
+	}
+
+	for {
+		msg, err := reader.ReadMessage(context.Background())
+		if err != nil {
+			k.logger.Error("erro ao ler mensagem",
+				zap.Error(err),
+				zap.String("topic", topic),
+				zap.Int("partition", partition),
+			)
+			continue
+		}
+		msgChan <- &msg
+	}
+}
+
+func (k *KafkaBrokerImpl) consume(groupID string, topics []string, msgChan chan *kafka.Message) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:           []string{k.brokerURL},
-		GroupID:           "transaction-group",
-		Topic:             topics[0],
+		GroupID:           groupID,
+		GroupTopics:       topics,
 		MaxWait:           1 * time.Second,
 		HeartbeatInterval: 5 * time.Second,
 	})
@@ -87,7 +218,7 @@ func (k *KafkaBrokerImpl) Consume(topics []string, msgChan chan *kafka.Message)
 		if err != nil {
 			k.logger.Error("erro ao ler mensagem",
 				zap.Error(err),
-				zap.String("topic", topics[0]),
+				zap.Strings("topics", topics),
 			)
 			continue
 		}
@@ -0,0 +1,137 @@
+package bizday
+
+import (
+	"sync"
+	"time"
+)
+
+// Calendar conta dias úteis: segunda a sexta, fora dos feriados. As datas são comparadas pelo dia
+// civil no fuso de cada time.Time recebido.
+type Calendar struct {
+	mu       sync.Mutex
+	holidays map[int]map[date]string
+	rules    func(year int) map[date]string
+	extra    map[date]string
+}
+
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	year, month, day := t.Date()
+	return date{year, month, day}
+}
+
+// NewBrazilCalendar usa os feriados nacionais e os dias sem expediente bancário em todo o país
+// (Carnaval e Corpus Christi), que também não contam para a liquidação. extra acrescenta
+// feriados locais ou pontuais.
+func NewBrazilCalendar(extra ...time.Time) *Calendar {
+	calendar := &Calendar{
+		holidays: make(map[int]map[date]string),
+		rules:    brazilHolidays,
+		extra:    make(map[date]string, len(extra)),
+	}
+	for _, day := range extra {
+		calendar.extra[dateOf(day)] = "Feriado adicional"
+	}
+	return calendar
+}
+
+// Holiday devolve o nome do feriado da data, se houver
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	day := dateOf(t)
+	if name, ok := c.extra[day]; ok {
+		return name, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	holidays, ok := c.holidays[day.year]
+	if !ok {
+		holidays = c.rules(day.year)
+		c.holidays[day.year] = holidays
+	}
+	name, ok := holidays[day]
+	return name, ok
+}
+
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if weekday := t.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// NextBusinessDay devolve t, se já for dia útil, ou o próximo dia útil
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// AddBusinessDays implementa o D+N: parte do primeiro dia útil a partir de t e avança days dias úteis.
+// D+0 num sábado, por exemplo, cai na segunda-feira seguinte.
+func (c *Calendar) AddBusinessDays(t time.Time, days int) time.Time {
+	t = c.NextBusinessDay(t)
+	for added := 0; added < days; {
+		t = t.AddDate(0, 0, 1)
+		if c.IsBusinessDay(t) {
+			added++
+		}
+	}
+	return t
+}
+
+func brazilHolidays(year int) map[date]string {
+	holidays := map[date]string{
+		{year, time.January, 1}:   "Confraternização Universal",
+		{year, time.April, 21}:    "Tiradentes",
+		{year, time.May, 1}:       "Dia do Trabalho",
+		{year, time.September, 7}: "Independência do Brasil",
+		{year, time.October, 12}:  "Nossa Senhora Aparecida",
+		{year, time.November, 2}:  "Finados",
+		{year, time.November, 15}: "Proclamação da República",
+		{year, time.December, 25}: "Natal",
+	}
+	// Lei 14.759/2023
+	if year >= 2024 {
+		holidays[date{year, time.November, 20}] = "Dia Nacional de Zumbi e da Consciência Negra"
+	}
+
+	easter := easterSunday(year)
+	movable := map[int]string{
+		-48: "Carnaval",
+		-47: "Carnaval",
+		-2:  "Sexta-feira Santa",
+		60:  "Corpus Christi",
+	}
+	for offset, name := range movable {
+		holidays[dateOf(easter.AddDate(0, 0, offset))] = name
+	}
+	return holidays
+}
+
+// easterSunday calcula o domingo de Páscoa pelo algoritmo de Meeus/Jones/Butcher
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
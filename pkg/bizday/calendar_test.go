@@ -0,0 +1,71 @@
+package bizday_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/bizday"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendar_Holiday(t *testing.T) {
+	calendar := bizday.NewBrazilCalendar(day(2026, time.January, 25))
+
+	tests := []struct {
+		date    time.Time
+		name    string
+		holiday bool
+	}{
+		{date: day(2026, time.April, 3), name: "Sexta-feira Santa", holiday: true},
+		{date: day(2026, time.February, 16), name: "Carnaval", holiday: true},
+		{date: day(2026, time.February, 17), name: "Carnaval", holiday: true},
+		{date: day(2026, time.June, 4), name: "Corpus Christi", holiday: true},
+		{date: day(2026, time.November, 20), name: "Dia Nacional de Zumbi e da Consciência Negra", holiday: true},
+		{date: day(2023, time.November, 20), holiday: false},
+		{date: day(2025, time.April, 18), name: "Sexta-feira Santa", holiday: true},
+		{date: day(2026, time.January, 25), name: "Feriado adicional", holiday: true},
+		{date: day(2026, time.March, 10), holiday: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date.Format(time.DateOnly), func(t *testing.T) {
+			// Act
+			name, holiday := calendar.Holiday(tt.date)
+
+			// Assert
+			assert.Equal(t, tt.holiday, holiday)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestCalendar_AddBusinessDays(t *testing.T) {
+	calendar := bizday.NewBrazilCalendar()
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		days     int
+		expected time.Time
+	}{
+		{name: "D+0 on a business day", from: day(2026, time.March, 10), days: 0, expected: day(2026, time.March, 10)},
+		{name: "D+0 on a Saturday rolls to Monday", from: day(2026, time.March, 14), days: 0, expected: day(2026, time.March, 16)},
+		{name: "D+1 skips Good Friday and the weekend", from: day(2026, time.April, 2), days: 1, expected: day(2026, time.April, 6)},
+		{name: "D+1 skips Carnival", from: day(2026, time.February, 13), days: 1, expected: day(2026, time.February, 18)},
+		{name: "D+30 skips weekends, Good Friday, Tiradentes and Labour Day", from: day(2026, time.March, 20), days: 30, expected: day(2026, time.May, 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := calendar.AddBusinessDays(tt.from, tt.days)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cardvault
+
+import (
+	"slices"
+	"strconv"
+)
+
+const (
+	BrandVisa       = "VISA"
+	BrandMastercard = "MASTERCARD"
+	BrandAmex       = "AMEX"
+	BrandElo        = "ELO"
+	BrandHipercard  = "HIPERCARD"
+	BrandDiners     = "DINERS"
+	BrandDiscover   = "DISCOVER"
+)
+
+// BINRange é uma faixa de prefixos (From..To, com o mesmo número de dígitos) de uma bandeira
+type BINRange struct {
+	Brand   string
+	From    int
+	To      int
+	Digits  int
+	Lengths []int
+}
+
+// BINTable é consultada na ordem: faixas mais específicas (Elo, Hipercard) vêm antes das genéricas
+var BINTable = []BINRange{
+	{Brand: BrandElo, From: 401178, To: 401179, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 431274, To: 431274, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 438935, To: 438935, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 451416, To: 451416, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 457393, To: 457393, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 457631, To: 457632, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 504175, To: 504175, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 506699, To: 506778, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 509000, To: 509999, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 627780, To: 627780, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 636297, To: 636297, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 636368, To: 636368, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650031, To: 650051, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650405, To: 650439, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650485, To: 650538, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650541, To: 650598, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650700, To: 650727, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 650901, To: 650920, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 651652, To: 651679, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandElo, From: 655000, To: 655058, Digits: 6, Lengths: []int{16}},
+	{Brand: BrandHipercard, From: 606282, To: 606282, Digits: 6, Lengths: []int{13, 16, 19}},
+	{Brand: BrandHipercard, From: 384100, To: 384100, Digits: 6, Lengths: []int{16, 19}},
+	{Brand: BrandHipercard, From: 384140, To: 384140, Digits: 6, Lengths: []int{16, 19}},
+	{Brand: BrandHipercard, From: 384160, To: 384160, Digits: 6, Lengths: []int{16, 19}},
+	{Brand: BrandAmex, From: 34, To: 34, Digits: 2, Lengths: []int{15}},
+	{Brand: BrandAmex, From: 37, To: 37, Digits: 2, Lengths: []int{15}},
+	{Brand: BrandDiners, From: 300, To: 305, Digits: 3, Lengths: []int{14}},
+	{Brand: BrandDiners, From: 36, To: 36, Digits: 2, Lengths: []int{14}},
+	{Brand: BrandDiners, From: 38, To: 38, Digits: 2, Lengths: []int{14}},
+	{Brand: BrandDiscover, From: 6011, To: 6011, Digits: 4, Lengths: []int{16, 19}},
+	{Brand: BrandDiscover, From: 644, To: 649, Digits: 3, Lengths: []int{16, 19}},
+	{Brand: BrandDiscover, From: 65, To: 65, Digits: 2, Lengths: []int{16, 19}},
+	{Brand: BrandMastercard, From: 2221, To: 2720, Digits: 4, Lengths: []int{16}},
+	{Brand: BrandMastercard, From: 51, To: 55, Digits: 2, Lengths: []int{16}},
+	{Brand: BrandVisa, From: 4, To: 4, Digits: 1, Lengths: []int{13, 16, 19}},
+}
+
+// DetectBrand identifica a bandeira pelo BIN e pelo tamanho do número
+func DetectBrand(number string) (string, bool) {
+	for _, binRange := range BINTable {
+		if len(number) < binRange.Digits || !slices.Contains(binRange.Lengths, len(number)) {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:binRange.Digits])
+		if err != nil {
+			return "", false
+		}
+		if prefix >= binRange.From && prefix <= binRange.To {
+			return binRange.Brand, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+package cardvault
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCardNumber  = errors.New("card number is invalid")
+	ErrInvalidCardExpiry  = errors.New("card expiry is invalid")
+	ErrCardExpired        = errors.New("card is expired")
+	ErrHolderNameRequired = errors.New("card holder name is required")
+	ErrUnsupportedBrand   = errors.New("card brand is not supported")
+)
+
+// Card são os dados sensíveis do cartão; só existem em claro na entrada da API e nos adaptadores de processamento
+type Card struct {
+	Number      string `json:"number"`
+	ExpiryMonth int    `json:"expiryMonth"`
+	ExpiryYear  int    `json:"expiryYear"`
+	HolderName  string `json:"holderName"`
+}
+
+// Normalize remove espaços e hífens do número e completa anos com dois dígitos
+func (c *Card) Normalize() {
+	c.Number = strings.NewReplacer(" ", "", "-", "").Replace(c.Number)
+	c.HolderName = strings.TrimSpace(c.HolderName)
+	if c.ExpiryYear > 0 && c.ExpiryYear < 100 {
+		c.ExpiryYear += 2000
+	}
+}
+
+func (c *Card) Validate(now time.Time) []error {
+	var errs []error
+
+	if !Luhn(c.Number) {
+		errs = append(errs, ErrInvalidCardNumber)
+	} else if _, ok := DetectBrand(c.Number); !ok {
+		errs = append(errs, ErrUnsupportedBrand)
+	}
+
+	if c.ExpiryMonth < 1 || c.ExpiryMonth > 12 || c.ExpiryYear < 2000 {
+		errs = append(errs, ErrInvalidCardExpiry)
+	} else if c.Expired(now) {
+		errs = append(errs, ErrCardExpired)
+	}
+
+	if c.HolderName == "" {
+		errs = append(errs, ErrHolderNameRequired)
+	}
+
+	return errs
+}
+
+// Expired considera o cartão válido até o último instante do mês de expiração
+func (c *Card) Expired(now time.Time) bool {
+	endOfMonth := time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.Before(endOfMonth)
+}
+
+func (c *Card) Last4() string {
+	if len(c.Number) < 4 {
+		return c.Number
+	}
+	return c.Number[len(c.Number)-4:]
+}
+
+// Luhn valida o dígito verificador (mod 10) de números de cartão entre 12 e 19 dígitos
+func Luhn(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
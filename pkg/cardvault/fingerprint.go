@@ -0,0 +1,30 @@
+package cardvault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+var ErrFingerprintKeyTooShort = errors.New("card fingerprint key must have at least 32 bytes")
+
+// Fingerprinter identifica o mesmo cartão entre tokens diferentes sem guardar o PAN: a impressão é um
+// HMAC-SHA256 do número com uma chave secreta, então não dá para testar números candidatos sem ela
+type Fingerprinter struct {
+	key []byte
+}
+
+func NewFingerprinter(key []byte) (*Fingerprinter, error) {
+	if len(key) < 32 {
+		return nil, ErrFingerprintKeyTooShort
+	}
+	return &Fingerprinter{key: key}, nil
+}
+
+func (f *Fingerprinter) Fingerprint(card Card) string {
+	card.Normalize()
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(card.Number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
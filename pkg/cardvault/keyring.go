@@ -0,0 +1,195 @@
+package cardvault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// minKeyBits é o menor módulo RSA aceito para as chaves mestras
+const minKeyBits = 2048
+
+var (
+	ErrUnknownKey  = errors.New("vault key not found in keyring")
+	ErrKeyTooSmall = errors.New("vault key must be an RSA key with at least 2048 bits")
+)
+
+// keyfile guarda as chaves em DER codificado em base64: PKIX no arquivo público e PKCS#8 no privado
+type keyfile struct {
+	ActiveKeyID string            `json:"activeKeyId"`
+	Keys        map[string]string `json:"keys"`
+}
+
+// SealingKeyring guarda só as chaves públicas (KEK) usadas para cifrar as chaves de dados de cada cartão.
+// Quem tem apenas este keyring consegue guardar cartões, mas não abri-los.
+type SealingKeyring struct {
+	activeKeyID string
+	keys        map[string]*rsa.PublicKey
+}
+
+func NewSealingKeyring(activeKeyID string, keys map[string]*rsa.PublicKey) (*SealingKeyring, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, ErrUnknownKey
+	}
+	for _, key := range keys {
+		if key.N.BitLen() < minKeyBits {
+			return nil, ErrKeyTooSmall
+		}
+	}
+	return &SealingKeyring{activeKeyID: activeKeyID, keys: keys}, nil
+}
+
+func NewSealingKeyringFromFile(path string) (*SealingKeyring, error) {
+	file, err := readKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(file.Keys))
+	for id, encoded := range file.Keys {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s do cofre não está em base64: %v", id, err)
+		}
+		parsed, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s do cofre não é uma chave pública: %v", id, err)
+		}
+		key, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("chave %s do cofre não é RSA", id)
+		}
+		keys[id] = key
+	}
+
+	return NewSealingKeyring(file.ActiveKeyID, keys)
+}
+
+// Keyring guarda as chaves privadas que abrem as chaves de dados dos cartões. Chaves antigas continuam
+// no arquivo para abrir cartões já armazenados.
+type Keyring struct {
+	keys map[string]*rsa.PrivateKey
+}
+
+func NewKeyring(keys map[string]*rsa.PrivateKey) (*Keyring, error) {
+	for _, key := range keys {
+		if key.N.BitLen() < minKeyBits {
+			return nil, ErrKeyTooSmall
+		}
+	}
+	return &Keyring{keys: keys}, nil
+}
+
+func NewKeyringFromFile(path string) (*Keyring, error) {
+	file, err := readKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PrivateKey, len(file.Keys))
+	for id, encoded := range file.Keys {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s do cofre não está em base64: %v", id, err)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s do cofre não é uma chave privada: %v", id, err)
+		}
+		key, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("chave %s do cofre não é RSA", id)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyring(keys)
+}
+
+func readKeyfile(path string) (*keyfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de chaves do cofre: %v", err)
+	}
+
+	var file keyfile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("erro ao converter chaves do cofre: %v", err)
+	}
+	return &file, nil
+}
+
+// seal cifra com uma chave de dados nova e devolve a chave de dados cifrada pela KEK pública ativa (envelope)
+func (k *SealingKeyring) seal(plaintext, additionalData []byte) (keyID string, wrappedKey, ciphertext []byte, err error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", nil, nil, err
+	}
+
+	ciphertext, err = gcmSeal(dataKey, plaintext, additionalData)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	// o dado autenticado vira o label do OAEP, amarrando a chave de dados ao registro
+	wrappedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, k.keys[k.activeKeyID], dataKey, additionalData)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return k.activeKeyID, wrappedKey, ciphertext, nil
+}
+
+func (k *Keyring) open(keyID string, wrappedKey, ciphertext, additionalData []byte) ([]byte, error) {
+	privateKey, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, privateKey, wrappedKey, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return gcmOpen(dataKey, ciphertext, additionalData)
+}
+
+// gcmSeal devolve nonce || ciphertext
+func gcmSeal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func gcmOpen(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
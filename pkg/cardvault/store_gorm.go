@@ -0,0 +1,30 @@
+package cardvault
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Save(card *VaultedCard) error {
+	return s.db.Create(card).Error
+}
+
+func (s *GormStore) Find(token string) (*VaultedCard, error) {
+	var card VaultedCard
+	if err := s.db.First(&card, "token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &card, nil
+}
@@ -0,0 +1,132 @@
+package cardvault
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const TokenPrefix = "card_"
+
+var ErrTokenNotFound = errors.New("card token not found")
+
+// VaultedCard é o registro persistido no cofre; o PAN e o nome do portador só existem dentro de Ciphertext
+type VaultedCard struct {
+	Token       string    `json:"token" gorm:"primaryKey;type:varchar(40)"`
+	Brand       string    `json:"brand" gorm:"type:varchar(20);not null"`
+	Last4       string    `json:"last4" gorm:"type:varchar(4);not null"`
+	ExpiryMonth int       `json:"expiryMonth" gorm:"not null"`
+	ExpiryYear  int       `json:"expiryYear" gorm:"not null"`
+	KeyID       string    `json:"-" gorm:"type:varchar(64);not null"`
+	WrappedKey  []byte    `json:"-" gorm:"type:bytea;not null"`
+	Ciphertext  []byte    `json:"-" gorm:"type:bytea;not null"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
+
+func (VaultedCard) TableName() string {
+	return "vault_cards"
+}
+
+type Store interface {
+	Save(card *VaultedCard) error
+	Find(token string) (*VaultedCard, error)
+}
+
+// Vault valida e tokeniza cartões. Recebe só as chaves públicas: o ledger guarda cartões sem conseguir
+// abri-los, e a abertura fica no Detokenizer.
+type Vault struct {
+	keyring *SealingKeyring
+	store   Store
+}
+
+func NewVault(keyring *SealingKeyring, store Store) *Vault {
+	return &Vault{keyring: keyring, store: store}
+}
+
+func (v *Vault) Tokenize(ctx context.Context, card Card) (*VaultedCard, []error) {
+	vaulted, errs := v.Seal(ctx, card)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if err := v.store.Save(vaulted); err != nil {
+		return nil, []error{err}
+	}
+	return vaulted, nil
+}
+
+// Seal valida e cifra o cartão sem gravá-lo: quem chama grava o registro junto com o que depende do token
+func (v *Vault) Seal(ctx context.Context, card Card) (*VaultedCard, []error) {
+	card.Normalize()
+	if errs := card.Validate(time.Now()); len(errs) > 0 {
+		return nil, errs
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	plaintext, err := json.Marshal(card)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	// o token entra como dado autenticado para impedir a troca de ciphertexts entre registros
+	keyID, wrappedKey, ciphertext, err := v.keyring.seal(plaintext, []byte(token))
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	brand, _ := DetectBrand(card.Number)
+	return &VaultedCard{
+		Token:       token,
+		Brand:       brand,
+		Last4:       card.Last4(),
+		ExpiryMonth: card.ExpiryMonth,
+		ExpiryYear:  card.ExpiryYear,
+		KeyID:       keyID,
+		WrappedKey:  wrappedKey,
+		Ciphertext:  ciphertext,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Detokenizer devolve os dados em claro de um token. Deve ser construído apenas pelos adaptadores do
+// transaction-processment, que são os únicos a receber o arquivo com as chaves privadas do cofre.
+type Detokenizer struct {
+	keyring *Keyring
+	store   Store
+}
+
+func NewDetokenizer(keyring *Keyring, store Store) *Detokenizer {
+	return &Detokenizer{keyring: keyring, store: store}
+}
+
+func (d *Detokenizer) Detokenize(ctx context.Context, token string) (*Card, error) {
+	vaulted, err := d.store.Find(token)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := d.keyring.open(vaulted.KeyID, vaulted.WrappedKey, vaulted.Ciphertext, []byte(vaulted.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	var card Card
+	if err := json.Unmarshal(plaintext, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func newToken() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return TokenPrefix + hex.EncodeToString(random), nil
+}
@@ -0,0 +1,160 @@
+package cardvault_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	cards map[string]*cardvault.VaultedCard
+}
+
+func (s *memoryStore) Save(card *cardvault.VaultedCard) error {
+	s.cards[card.Token] = card
+	return nil
+}
+
+func (s *memoryStore) Find(token string) (*cardvault.VaultedCard, error) {
+	card, ok := s.cards[token]
+	if !ok {
+		return nil, cardvault.ErrTokenNotFound
+	}
+	return card, nil
+}
+
+func newKeyrings(t *testing.T) (*cardvault.SealingKeyring, *cardvault.Keyring) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sealing, err := cardvault.NewSealingKeyring("k1", map[string]*rsa.PublicKey{"k1": &privateKey.PublicKey})
+	require.NoError(t, err)
+	keyring, err := cardvault.NewKeyring(map[string]*rsa.PrivateKey{"k1": privateKey})
+	require.NoError(t, err)
+	return sealing, keyring
+}
+
+func TestDetectBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		brand  string
+	}{
+		{number: "4111111111111111", brand: cardvault.BrandVisa},
+		{number: "5555555555554444", brand: cardvault.BrandMastercard},
+		{number: "2223000048400011", brand: cardvault.BrandMastercard},
+		{number: "378282246310005", brand: cardvault.BrandAmex},
+		{number: "6362970000457013", brand: cardvault.BrandElo},
+		{number: "6062825624254001", brand: cardvault.BrandHipercard},
+		{number: "6011111111111117", brand: cardvault.BrandDiscover},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			// Act
+			brand, ok := cardvault.DetectBrand(tt.number)
+
+			// Assert
+			assert.True(t, ok)
+			assert.True(t, cardvault.Luhn(tt.number))
+			assert.Equal(t, tt.brand, brand)
+		})
+	}
+}
+
+func TestCard_Validate(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, time.March, 31, 23, 0, 0, 0, time.UTC)
+	card := cardvault.Card{Number: "4111 1111 1111 1112", ExpiryMonth: 2, ExpiryYear: 26}
+	card.Normalize()
+
+	// Act
+	errs := card.Validate(now)
+
+	// Assert
+	assert.Equal(t, []error{cardvault.ErrInvalidCardNumber, cardvault.ErrCardExpired, cardvault.ErrHolderNameRequired}, errs)
+
+	card = cardvault.Card{Number: "4111111111111111", ExpiryMonth: 3, ExpiryYear: 2026, HolderName: "FULANO DE TAL"}
+	assert.Empty(t, card.Validate(now))
+}
+
+func TestVault_TokenizeAndDetokenize(t *testing.T) {
+	// Arrange
+	store := &memoryStore{cards: map[string]*cardvault.VaultedCard{}}
+	sealing, keyring := newKeyrings(t)
+	vault := cardvault.NewVault(sealing, store)
+	detokenizer := cardvault.NewDetokenizer(keyring, store)
+	card := cardvault.Card{Number: "5555 5555 5555 4444", ExpiryMonth: 12, ExpiryYear: time.Now().Year() + 1, HolderName: "FULANO DE TAL"}
+
+	// Act
+	vaulted, errs := vault.Tokenize(context.Background(), card)
+	require.Empty(t, errs)
+	opened, err := detokenizer.Detokenize(context.Background(), vaulted.Token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "4444", vaulted.Last4)
+	assert.Equal(t, cardvault.BrandMastercard, vaulted.Brand)
+	assert.NotContains(t, string(vaulted.Ciphertext), "5555555555554444")
+	assert.Equal(t, "5555555555554444", opened.Number)
+	assert.Equal(t, "FULANO DE TAL", opened.HolderName)
+}
+
+func TestVault_DetokenizeRejectsSwappedCiphertext(t *testing.T) {
+	// Arrange
+	store := &memoryStore{cards: map[string]*cardvault.VaultedCard{}}
+	sealing, keyring := newKeyrings(t)
+	vault := cardvault.NewVault(sealing, store)
+	card := cardvault.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: time.Now().Year() + 1, HolderName: "FULANO"}
+	first, _ := vault.Tokenize(context.Background(), card)
+	second, _ := vault.Tokenize(context.Background(), card)
+	first.Ciphertext, first.WrappedKey = second.Ciphertext, second.WrappedKey
+
+	// Act
+	_, err := cardvault.NewDetokenizer(keyring, store).Detokenize(context.Background(), first.Token)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestNewSealingKeyring_RejectsSmallKeys(t *testing.T) {
+	// Arrange
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	// Act
+	_, sealingErr := cardvault.NewSealingKeyring("k1", map[string]*rsa.PublicKey{"k1": &privateKey.PublicKey})
+	_, keyringErr := cardvault.NewKeyring(map[string]*rsa.PrivateKey{"k1": privateKey})
+
+	// Assert
+	assert.ErrorIs(t, sealingErr, cardvault.ErrKeyTooSmall)
+	assert.ErrorIs(t, keyringErr, cardvault.ErrKeyTooSmall)
+}
+
+func TestFingerprinter(t *testing.T) {
+	// Arrange
+	key := []byte("0123456789abcdef0123456789abcdef")
+	fingerprinter, err := cardvault.NewFingerprinter(key)
+	require.NoError(t, err)
+	other, err := cardvault.NewFingerprinter([]byte("fedcba9876543210fedcba9876543210"))
+	require.NoError(t, err)
+	card := cardvault.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2030, HolderName: "FULANO"}
+
+	// Act
+	fingerprint := fingerprinter.Fingerprint(card)
+	formatted := fingerprinter.Fingerprint(cardvault.Card{Number: "4111 1111-1111 1111", ExpiryMonth: 1, ExpiryYear: 2031, HolderName: "OUTRO"})
+	otherCard := fingerprinter.Fingerprint(cardvault.Card{Number: "5555555555554444"})
+	_, shortErr := cardvault.NewFingerprinter(key[:16])
+
+	// Assert
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, formatted, "the same number should give the same fingerprint")
+	assert.NotEqual(t, fingerprint, otherCard)
+	assert.NotEqual(t, fingerprint, other.Fingerprint(card), "another key should give another fingerprint")
+	assert.ErrorIs(t, shortErr, cardvault.ErrFingerprintKeyTooShort)
+}
@@ -0,0 +1,154 @@
+package pixkey
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+const (
+	TypeCPF   = "CPF"
+	TypeCNPJ  = "CNPJ"
+	TypeEmail = "EMAIL"
+	TypePhone = "PHONE"
+	TypeEVP   = "EVP"
+)
+
+var (
+	ErrInvalidKey   = errors.New("invalid pix key")
+	ErrInvalidCPF   = errors.New("invalid pix key: CPF check digits do not match")
+	ErrInvalidCNPJ  = errors.New("invalid pix key: CNPJ check digits do not match")
+	ErrInvalidEmail = errors.New("invalid pix key: malformed email")
+	ErrInvalidPhone = errors.New("invalid pix key: phone must be in E.164 format")
+)
+
+var (
+	evpPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	cpfPattern   = regexp.MustCompile(`^\d{3}\.?\d{3}\.?\d{3}-?\d{2}$`)
+	cnpjPattern  = regexp.MustCompile(`^\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}$`)
+)
+
+// Key é uma chave Pix já normalizada (CPF/CNPJ só com dígitos, e-mail e EVP em minúsculas)
+type Key struct {
+	Type  string
+	Value string
+}
+
+// Parse detecta o tipo da chave e valida formato e dígitos verificadores
+func Parse(raw string) (Key, error) {
+	value := strings.TrimSpace(raw)
+
+	switch {
+	case value == "":
+		return Key{}, ErrInvalidKey
+	case evpPattern.MatchString(value):
+		return Key{Type: TypeEVP, Value: strings.ToLower(value)}, nil
+	case strings.Contains(value, "@"):
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value || len(value) > 77 {
+			return Key{}, ErrInvalidEmail
+		}
+		return Key{Type: TypeEmail, Value: strings.ToLower(value)}, nil
+	case strings.HasPrefix(value, "+"):
+		if !phonePattern.MatchString(value) {
+			return Key{}, ErrInvalidPhone
+		}
+		return Key{Type: TypePhone, Value: value}, nil
+	case cpfPattern.MatchString(value):
+		digits := onlyDigits(value)
+		if !validCPF(digits) {
+			return Key{}, ErrInvalidCPF
+		}
+		return Key{Type: TypeCPF, Value: digits}, nil
+	case cnpjPattern.MatchString(value):
+		digits := onlyDigits(value)
+		if !validCNPJ(digits) {
+			return Key{}, ErrInvalidCNPJ
+		}
+		return Key{Type: TypeCNPJ, Value: digits}, nil
+	}
+
+	return Key{}, ErrInvalidKey
+}
+
+func (k Key) Masked() string {
+	return maskTyped(k.Type, k.Value)
+}
+
+// Mask mascara uma chave para exibição em respostas e logs; chaves inválidas são totalmente ocultadas
+func Mask(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	key, err := Parse(raw)
+	if err != nil {
+		return "***"
+	}
+	return key.Masked()
+}
+
+func maskTyped(keyType, value string) string {
+	switch keyType {
+	case TypeCPF:
+		return "***." + value[3:6] + "." + value[6:9] + "-**"
+	case TypeCNPJ:
+		return value[:2] + ".***.***/" + value[8:12] + "-**"
+	case TypeEmail:
+		local, domain, _ := strings.Cut(value, "@")
+		if len(local) <= 2 {
+			return strings.Repeat("*", len(local)) + "@" + domain
+		}
+		return local[:1] + strings.Repeat("*", len(local)-2) + local[len(local)-1:] + "@" + domain
+	case TypePhone:
+		return value[:3] + strings.Repeat("*", len(value)-7) + value[len(value)-4:]
+	case TypeEVP:
+		return value[:8] + "-****-****-****-********" + value[len(value)-4:]
+	}
+	return "***"
+}
+
+func onlyDigits(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func allSameDigit(digits string) bool {
+	return strings.Count(digits, digits[:1]) == len(digits)
+}
+
+func checkDigit(digits string, weights []int) byte {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+func validCPF(digits string) bool {
+	if len(digits) != 11 || allSameDigit(digits) {
+		return false
+	}
+	first := checkDigit(digits, []int{10, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(digits, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+	return digits[9] == first && digits[10] == second
+}
+
+func validCNPJ(digits string) bool {
+	if len(digits) != 14 || allSameDigit(digits) {
+		return false
+	}
+	first := checkDigit(digits, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(digits, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	return digits[12] == first && digits[13] == second
+}
@@ -0,0 +1,53 @@
+package pixkey_test
+
+import (
+	"testing"
+
+	"github.com/NathanGdS/transaction-hub/pkg/pixkey"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_DetectsKeyType(t *testing.T) {
+	tests := []struct {
+		raw      string
+		keyType  string
+		value    string
+		expected error
+	}{
+		{raw: "529.982.247-25", keyType: pixkey.TypeCPF, value: "52998224725"},
+		{raw: "52998224725", keyType: pixkey.TypeCPF, value: "52998224725"},
+		{raw: "529.982.247-24", expected: pixkey.ErrInvalidCPF},
+		{raw: "111.111.111-11", expected: pixkey.ErrInvalidCPF},
+		{raw: "11.222.333/0001-81", keyType: pixkey.TypeCNPJ, value: "11222333000181"},
+		{raw: "11222333000180", expected: pixkey.ErrInvalidCNPJ},
+		{raw: "Fulano@Example.com", keyType: pixkey.TypeEmail, value: "fulano@example.com"},
+		{raw: "fulano@", expected: pixkey.ErrInvalidEmail},
+		{raw: "+5511998765432", keyType: pixkey.TypePhone, value: "+5511998765432"},
+		{raw: "+55 11 99876-5432", expected: pixkey.ErrInvalidPhone},
+		{raw: "123E4567-E89B-12D3-A456-426614174000", keyType: pixkey.TypeEVP, value: "123e4567-e89b-12d3-a456-426614174000"},
+		{raw: "chave-qualquer", expected: pixkey.ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			// Act
+			key, err := pixkey.Parse(tt.raw)
+
+			// Assert
+			assert.Equal(t, tt.expected, err)
+			assert.Equal(t, tt.keyType, key.Type)
+			assert.Equal(t, tt.value, key.Value)
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	// Assert
+	assert.Equal(t, "***.982.247-**", pixkey.Mask("529.982.247-25"))
+	assert.Equal(t, "11.***.***/0001-**", pixkey.Mask("11222333000181"))
+	assert.Equal(t, "f****o@example.com", pixkey.Mask("fulano@example.com"))
+	assert.Equal(t, "+55*******5432", pixkey.Mask("+5511998765432"))
+	assert.Equal(t, "123e4567-****-****-****-********4000", pixkey.Mask("123e4567-e89b-12d3-a456-426614174000"))
+	assert.Equal(t, "***", pixkey.Mask("chave-qualquer"))
+	assert.Equal(t, "", pixkey.Mask(""))
+}
@@ -0,0 +1,155 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	DisputesTopic           = "transaction-disputes"
+	DisputesDeadLetterTopic = "transaction-disputes-dlq"
+)
+
+// DisputeConsumerConfig define quantas vezes uma notificação é tentada antes de ir para o tópico de
+// mensagens mortas; a espera entre as tentativas dobra a cada falha, até 30 segundos
+type DisputeConsumerConfig struct {
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+// DeadLetter é a mensagem publicada em DisputesDeadLetterTopic quando a notificação não pôde ser aplicada
+type DeadLetter struct {
+	Topic    string    `json:"topic"`
+	Value    string    `json:"value"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// DisputeConsumer recebe as notificações de chargeback enviadas pelo processador
+type DisputeConsumer struct {
+	kafkaBroker akafka.KafkaBroker
+	logger      *zap.Logger
+	service     *services.DisputeService
+	config      DisputeConsumerConfig
+}
+
+func NewDisputeConsumer(kafkaBroker *akafka.KafkaBroker, service *services.DisputeService, config DisputeConsumerConfig) *DisputeConsumer {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 5
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	return &DisputeConsumer{
+		kafkaBroker: *kafkaBroker,
+		logger:      logger.Log,
+		service:     service,
+		config:      config,
+	}
+}
+
+func (c *DisputeConsumer) Start() {
+	msgChan := make(chan *kafka.Message)
+	go c.kafkaBroker.Consume([]string{DisputesTopic}, msgChan)
+
+	// sequencial: a abertura e a decisão de uma mesma disputa precisam ser aplicadas na ordem do tópico
+	for msg := range msgChan {
+		c.processMessage(msg)
+	}
+}
+
+// processMessage tenta aplicar a notificação até MaxAttempts vezes. A offset já foi confirmada na
+// leitura, então uma notificação que não pode ser aplicada vai para o tópico de mensagens mortas em vez
+// de se perder; erros de validação vão direto, já que repetir não muda o resultado.
+func (c *DisputeConsumer) processMessage(msg *kafka.Message) {
+	c.logger.Info("consumindo mensagem no tópico "+DisputesTopic,
+		zap.String("message", string(msg.Value)),
+	)
+
+	var notification dto.DisputeNotificationDto
+	err := json.Unmarshal(msg.Value, &notification)
+	if err != nil {
+		c.logger.Error("erro ao converter para JSON",
+			zap.Error(err),
+		)
+		c.deadLetter(msg, err, 1)
+		return
+	}
+
+	backoff := c.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		_, err = c.service.Ingest(context.Background(), notification)
+		if err == nil {
+			return
+		}
+
+		c.logger.Error("erro ao aplicar notificação de disputa",
+			zap.Error(err),
+			zap.String("processorDisputeId", notification.ProcessorDisputeID),
+			zap.String("event", notification.Event),
+			zap.Int("attempt", attempt),
+		)
+		if permanentDisputeError(err) || attempt >= c.config.MaxAttempts {
+			c.deadLetter(msg, err, attempt)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (c *DisputeConsumer) deadLetter(msg *kafka.Message, cause error, attempts int) {
+	payload, err := json.Marshal(DeadLetter{
+		Topic:    DisputesTopic,
+		Value:    string(msg.Value),
+		Error:    cause.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	})
+	if err == nil {
+		err = c.kafkaBroker.Publish(DisputesDeadLetterTopic, payload)
+	}
+	if err != nil {
+		// último recurso: a notificação fica pelo menos no log
+		c.logger.Error("erro ao enviar notificação de disputa para mensagens mortas",
+			zap.Error(err),
+			zap.String("message", string(msg.Value)),
+		)
+		return
+	}
+
+	c.logger.Warn("notificação de disputa enviada para mensagens mortas",
+		zap.String("topic", DisputesDeadLetterTopic),
+		zap.Int("attempts", attempts),
+	)
+}
+
+// permanentDisputeError indica notificações inválidas, que falhariam igual em qualquer nova tentativa
+func permanentDisputeError(err error) bool {
+	for _, permanent := range []error{
+		services.ErrProcessorDisputeRequired,
+		services.ErrInvalidDisputeEvent,
+		domain.ErrorInvalidDisputeReason,
+		domain.ErrorInvalidDisputeAmount,
+		domain.ErrorDisputedAmountExceeded,
+		domain.ErrorTransactionNotDisputable,
+		domain.ErrorDisputeResolved,
+	} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
-	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -17,13 +16,15 @@ type ProcessTransactionConsumer struct {
 	kafkaBroker akafka.KafkaBroker
 	logger      *zap.Logger
 	service     *services.TransactionService
+	waiter      *services.StatusWaiter
 }
 
-func NewProcessTransactionConsumer(kafkaBroker *akafka.KafkaBroker, repository dRepo.TransactionRepository) *ProcessTransactionConsumer {
+func NewProcessTransactionConsumer(kafkaBroker *akafka.KafkaBroker, service *services.TransactionService, waiter *services.StatusWaiter) *ProcessTransactionConsumer {
 	return &ProcessTransactionConsumer{
 		kafkaBroker: *kafkaBroker,
 		logger:      logger.Log,
-		service:     services.NewTransactionService(*kafkaBroker, repository),
+		service:     service,
+		waiter:      waiter,
 	}
 }
 
@@ -47,19 +48,19 @@ func (c *ProcessTransactionConsumer) processMessage(msg *kafka.Message) {
 		c.logger.Error("erro ao converter para JSON",
 			zap.Error(err),
 		)
+		return
 	}
 
-	transaction, err := c.service.FindByID(context.Background(), processTransactionDto.TransactionID)
+	err = c.service.ApplyProcessingResult(context.Background(), processTransactionDto)
 	if err != nil {
-		c.logger.Error("erro ao buscar transação",
+		c.logger.Error("erro ao aplicar retorno do processamento",
 			zap.Error(err),
+			zap.String("id", processTransactionDto.TransactionID),
 		)
 	}
 
-	if processTransactionDto.Status == dto.TransactionStatusProcessed {
-		transaction.TransactionProcessed()
-	} else {
-		transaction.ErrorProcessingTransaction(processTransactionDto.ErrorMessage)
+	// acorda os long-polls mesmo em caso de erro; eles releem a transação e voltam a esperar se preciso
+	if c.waiter != nil {
+		c.waiter.Notify(processTransactionDto.TransactionID)
 	}
-	c.service.UpdateTransaction(context.Background(), transaction)
 }
@@ -0,0 +1,166 @@
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// IDs dos campos EMV do BR Code definidos no Manual de Padrões para Iniciação do Pix
+const (
+	idPayloadFormatIndicator = "00"
+	idPointOfInitiation      = "01"
+	idMerchantAccountInfo    = "26"
+	idMerchantCategoryCode   = "52"
+	idTransactionCurrency    = "53"
+	idTransactionAmount      = "54"
+	idCountryCode            = "58"
+	idMerchantName           = "59"
+	idMerchantCity           = "60"
+	idAdditionalData         = "62"
+	idCRC16                  = "63"
+
+	idGUI         = "00"
+	idPixKey      = "01"
+	idInfo        = "02"
+	idLocationURL = "25"
+	idTxID        = "05"
+
+	pixGUI = "br.gov.bcb.pix"
+
+	// maxFieldLength é o maior valor que cabe no tamanho de dois dígitos do TLV
+	maxFieldLength = 99
+	// maxDescriptionLength é o limite do campo de informações adicionais dentro da conta do recebedor
+	maxDescriptionLength = 40
+)
+
+var ErrFieldTooLong = errors.New("pix brcode field exceeds 99 characters")
+
+// BRCode reúne os dados usados para montar o payload "copia e cola"
+type BRCode struct {
+	Key          string
+	LocationURL  string
+	Description  string
+	MerchantName string
+	MerchantCity string
+	Amount       float64
+	TxID         string
+}
+
+func (b BRCode) Dynamic() bool {
+	return b.LocationURL != ""
+}
+
+// Payload monta o TLV EMV e adiciona o CRC16 no final. A chave, a descrição e a URL ficam dentro
+// do campo 26, que também não pode passar de 99 caracteres: a descrição é cortada para caber e
+// chave ou URL longas demais devolvem ErrFieldTooLong em vez de um código que os apps recusam.
+func (b BRCode) Payload() (string, error) {
+	var merchantAccount emvBuilder
+	merchantAccount.add(idGUI, pixGUI)
+	if b.Dynamic() {
+		merchantAccount.add(idLocationURL, b.LocationURL)
+	} else {
+		merchantAccount.add(idPixKey, b.Key)
+		// o que sobra no campo 26, descontados o ID e o tamanho do subcampo da descrição
+		room := min(maxDescriptionLength, maxFieldLength-merchantAccount.Len()-4)
+		if description := truncate(sanitize(b.Description), room); description != "" {
+			merchantAccount.add(idInfo, description)
+		}
+	}
+	if merchantAccount.err != nil {
+		return "", merchantAccount.err
+	}
+
+	txID := b.TxID
+	if b.Dynamic() || txID == "" {
+		// nas cobranças dinâmicas o txid fica associado à location
+		txID = "***"
+	}
+
+	var additionalData emvBuilder
+	additionalData.add(idTxID, txID)
+
+	var payload emvBuilder
+	payload.add(idPayloadFormatIndicator, "01")
+	if b.Dynamic() {
+		// "12" indica BR Code de uso único; o campo é opcional nos estáticos
+		payload.add(idPointOfInitiation, "12")
+	}
+	payload.add(idMerchantAccountInfo, merchantAccount.String())
+	payload.add(idMerchantCategoryCode, "0000")
+	payload.add(idTransactionCurrency, "986")
+	if b.Amount > 0 {
+		payload.add(idTransactionAmount, strconv.FormatFloat(b.Amount, 'f', 2, 64))
+	}
+	payload.add(idCountryCode, "BR")
+	payload.add(idMerchantName, truncate(sanitize(b.MerchantName), 25))
+	payload.add(idMerchantCity, truncate(sanitize(b.MerchantCity), 15))
+	payload.add(idAdditionalData, additionalData.String())
+	if err := errors.Join(additionalData.err, payload.err); err != nil {
+		return "", err
+	}
+	payload.WriteString(idCRC16 + "04")
+
+	return payload.String() + fmt.Sprintf("%04X", CRC16(payload.String())), nil
+}
+
+// emvBuilder escreve campos TLV e guarda o primeiro campo que não cabe no tamanho de dois dígitos
+type emvBuilder struct {
+	strings.Builder
+	err error
+}
+
+func (b *emvBuilder) add(id, value string) {
+	if b.err != nil {
+		return
+	}
+	if len(value) > maxFieldLength {
+		b.err = fmt.Errorf("%w: field %s has %d characters", ErrFieldTooLong, id, len(value))
+		return
+	}
+	fmt.Fprintf(b, "%s%02d%s", id, len(value), value)
+}
+
+func truncate(value string, size int) string {
+	if size <= 0 {
+		return ""
+	}
+	if len(value) > size {
+		return value[:size]
+	}
+	return value
+}
+
+// sanitize remove acentos e caracteres fora do conjunto aceito pelos leitores de QR Code
+func sanitize(value string) string {
+	var builder strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if r < 128 {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// CRC16 calcula o CRC16-CCITT (polinômio 0x1021, valor inicial 0xFFFF) exigido pelo campo 63
+func CRC16(payload string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(payload); i++ {
+		crc ^= uint16(payload[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
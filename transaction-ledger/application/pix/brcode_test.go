@@ -0,0 +1,123 @@
+package pix
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRC16(t *testing.T) {
+	// CRC-16/CCITT-FALSE check value
+	assert.Equal(t, uint16(0x29B1), CRC16("123456789"))
+}
+
+func TestBRCode_Payload(t *testing.T) {
+	t.Run("Should match the static example from the BCB manual", func(t *testing.T) {
+		// Arrange
+		code := BRCode{
+			Key:          "123e4567-e12b-12d1-a456-426655440000",
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}
+
+		// Act
+		payload, err := code.Payload()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D", payload)
+	})
+
+	t.Run("Should build a dynamic payload with amount and location", func(t *testing.T) {
+		// Arrange
+		code := BRCode{
+			LocationURL:  "pix.example.com/qr/v2/abc",
+			MerchantName: "Loja São João",
+			MerchantCity: "São Paulo",
+			Amount:       10.5,
+			TxID:         "ABC",
+		}
+
+		// Act
+		payload, err := code.Payload()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, payload, "010212")
+		assert.Contains(t, payload, "2525pix.example.com/qr/v2/abc")
+		assert.Contains(t, payload, "540510.50")
+		assert.Contains(t, payload, "5913Loja Sao Joao")
+		assert.Contains(t, payload, "62070503***")
+		assert.True(t, strings.HasPrefix(payload[len(payload)-8:], "6304"))
+	})
+
+	t.Run("Should cut the description so the merchant account field fits in 99 characters", func(t *testing.T) {
+		// Arrange
+		code := BRCode{
+			Key:          "123e4567-e12b-12d1-a456-426655440000",
+			Description:  strings.Repeat("d", 60),
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}
+		email := BRCode{
+			Key:          strings.Repeat("a", 65) + "@example.com",
+			Description:  "Pedido 123",
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}
+
+		// Act
+		payload, err := code.Payload()
+		emailPayload, emailErr := email.Payload()
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(payload, "00020126990014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400000237"+strings.Repeat("d", 37)+"5204"))
+		require.NoError(t, emailErr)
+		assert.Contains(t, emailPayload, "26990014br.gov.bcb.pix0177"+email.Key+"5204")
+		assert.NotContains(t, emailPayload, "Pedido")
+	})
+
+	t.Run("Should refuse a key that does not fit in the merchant account field", func(t *testing.T) {
+		// Arrange
+		code := BRCode{
+			Key:          strings.Repeat("a", 70) + "@example.com",
+			MerchantName: "Fulano de Tal",
+			MerchantCity: "BRASILIA",
+		}
+
+		// Act
+		payload, err := code.Payload()
+
+		// Assert
+		assert.ErrorIs(t, err, ErrFieldTooLong)
+		assert.Empty(t, payload)
+	})
+}
+
+func TestChargeGenerator_Attach(t *testing.T) {
+	// Arrange
+	generator, err := NewChargeGenerator(Config{
+		MerchantKey:  "fulano@example.com",
+		MerchantName: "Fulano",
+		MerchantCity: "Brasilia",
+		Expiration:   time.Minute,
+	})
+	require.NoError(t, err)
+	transaction, _ := domain.NewTransaction(100, domain.PaymentMethodPIX, "BRL", "Teste")
+
+	// Act
+	err = generator.Attach(transaction)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, transaction.PixTxID, 25)
+	assert.Contains(t, transaction.PixPayload, "0525"+transaction.PixTxID)
+	assert.False(t, transaction.Expire(time.Now()))
+	assert.True(t, transaction.Expire(time.Now().Add(2*time.Minute)))
+	assert.Equal(t, domain.TransactionExpired, transaction.Status)
+}
@@ -0,0 +1,79 @@
+package pix
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+var ErrMerchantKeyRequired = errors.New("pix merchant key or location url is required")
+
+type Config struct {
+	// MerchantKey é a chave Pix do recebedor, usada nos BR Codes estáticos
+	MerchantKey  string
+	MerchantName string
+	MerchantCity string
+	// LocationBaseURL habilita BR Codes dinâmicos (ex.: pix.example.com/qr/v2/), sem o esquema https://
+	LocationBaseURL string
+	Expiration      time.Duration
+}
+
+// ChargeGenerator gera o BR Code de cobrança das transações PIX
+type ChargeGenerator struct {
+	config Config
+}
+
+func NewChargeGenerator(config Config) (*ChargeGenerator, error) {
+	if config.MerchantKey == "" && config.LocationBaseURL == "" {
+		return nil, ErrMerchantKeyRequired
+	}
+	if config.Expiration <= 0 {
+		config.Expiration = 30 * time.Minute
+	}
+
+	generator := &ChargeGenerator{config: config}
+	// confere na partida se a chave ou a URL cabem no BR Code, em vez de falhar em cada cobrança
+	if _, err := generator.brCode(&domain.Transaction{Amount: 1}).Payload(); err != nil {
+		return nil, err
+	}
+	return generator, nil
+}
+
+// Attach gera a cobrança e grava payload, txid e expiração na transação
+func (g *ChargeGenerator) Attach(transaction *domain.Transaction) error {
+	code := g.brCode(transaction)
+	payload, err := code.Payload()
+	if err != nil {
+		return err
+	}
+
+	transaction.AttachPixCharge(code.TxID, payload, time.Now().Add(g.config.Expiration))
+	return nil
+}
+
+func (g *ChargeGenerator) brCode(transaction *domain.Transaction) BRCode {
+	txID := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+
+	code := BRCode{
+		Key:          g.config.MerchantKey,
+		Description:  transaction.Description,
+		MerchantName: g.config.MerchantName,
+		MerchantCity: g.config.MerchantCity,
+		Amount:       transaction.Amount,
+		TxID:         txID[:25],
+	}
+	if g.config.LocationBaseURL != "" {
+		code.LocationURL = strings.TrimSuffix(g.config.LocationBaseURL, "/") + "/" + strings.ToLower(txID)
+		code.TxID = txID
+	}
+	return code
+}
+
+// QRCodePNG renderiza o payload "copia e cola" como imagem PNG
+func QRCodePNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
@@ -0,0 +1,163 @@
+package risk
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"go.uber.org/zap"
+)
+
+// VelocityCounter conta transações recentes de um merchant ou de um cartão para as regras de velocidade
+type VelocityCounter interface {
+	CountByMerchantSince(merchantID string, since time.Time) (int64, error)
+	// CountByCardSince conta pela impressão do cartão, que é a mesma para todos os tokens do mesmo PAN
+	CountByCardSince(fingerprint string, since time.Time) (int64, error)
+}
+
+type Assessment struct {
+	Score    int
+	Decision string
+	Reasons  []string
+}
+
+// Engine avalia as regras carregadas do YAML; o RuleSet é trocado atomicamente no hot-reload
+type Engine struct {
+	rules    atomic.Pointer[RuleSet]
+	velocity VelocityCounter
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+func NewEngine(ruleSet *RuleSet, velocity VelocityCounter) *Engine {
+	engine := &Engine{velocity: velocity, logger: logger.Log, now: time.Now}
+	engine.rules.Store(ruleSet)
+	return engine
+}
+
+func (e *Engine) SetRuleSet(ruleSet *RuleSet) {
+	e.rules.Store(ruleSet)
+}
+
+func (e *Engine) Evaluate(ctx context.Context, transaction *domain.Transaction) (Assessment, error) {
+	ruleSet := e.rules.Load()
+	assessment := Assessment{Decision: domain.RiskDecisionApprove}
+	if ruleSet == nil {
+		return assessment, nil
+	}
+
+	forced := ""
+	for i := range ruleSet.Rules {
+		rule := &ruleSet.Rules[i]
+
+		matched, err := e.matches(rule, transaction)
+		if err != nil {
+			return Assessment{}, err
+		}
+		if !matched {
+			continue
+		}
+
+		assessment.Score += rule.Score
+		assessment.Reasons = append(assessment.Reasons, rule.Name)
+		if rule.Action == domain.RiskDecisionDecline || (rule.Action == domain.RiskDecisionReview && forced == "") {
+			forced = rule.Action
+		}
+	}
+
+	switch {
+	case forced == domain.RiskDecisionDecline || assessment.Score >= ruleSet.Thresholds.Decline:
+		assessment.Decision = domain.RiskDecisionDecline
+	case forced == domain.RiskDecisionReview || assessment.Score >= ruleSet.Thresholds.Review:
+		assessment.Decision = domain.RiskDecisionReview
+	}
+
+	return assessment, nil
+}
+
+func (e *Engine) matches(rule *Rule, transaction *domain.Transaction) (bool, error) {
+	switch rule.Type {
+	case RuleTypeAmount:
+		return (rule.Currency == "" || rule.Currency == transaction.CurrencyCode) && transaction.Amount > rule.Above, nil
+	case RuleTypeVelocity:
+		return e.exceedsVelocity(rule, transaction)
+	case RuleTypeBlockedDescription:
+		for _, pattern := range rule.patterns {
+			if pattern.MatchString(transaction.Description) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case RuleTypeUnusualHours:
+		return rule.inWindow(e.now()), nil
+	}
+	return false, nil
+}
+
+func (e *Engine) exceedsVelocity(rule *Rule, transaction *domain.Transaction) (bool, error) {
+	if e.velocity == nil {
+		return false, nil
+	}
+
+	since := e.now().Add(-rule.Window)
+	var count int64
+	var err error
+	switch rule.Key {
+	case VelocityKeyCard:
+		if transaction.CardFingerprint == "" {
+			return false, nil
+		}
+		count, err = e.velocity.CountByCardSince(transaction.CardFingerprint, since)
+	default:
+		if transaction.MerchantID == "" {
+			return false, nil
+		}
+		count, err = e.velocity.CountByMerchantSince(transaction.MerchantID, since)
+	}
+	if err != nil {
+		return false, err
+	}
+	// conta a transação que está sendo avaliada
+	return count+1 > rule.Max, nil
+}
+
+// Watch recarrega o arquivo de regras sempre que a data de modificação muda.
+// Um arquivo inválido é ignorado e as regras anteriores continuam valendo.
+func (e *Engine) Watch(ctx context.Context, path string, interval time.Duration) {
+	var lastModified time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil || !info.ModTime().After(lastModified) {
+				continue
+			}
+			lastModified = info.ModTime()
+
+			ruleSet, err := LoadRuleSet(path)
+			if err != nil {
+				e.logger.Error("erro ao recarregar regras de risco",
+					zap.Error(err),
+				)
+				continue
+			}
+
+			e.SetRuleSet(ruleSet)
+			e.logger.Info("regras de risco recarregadas",
+				zap.Int("rules", len(ruleSet.Rules)),
+			)
+		}
+	}
+}
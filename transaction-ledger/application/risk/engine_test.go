@@ -0,0 +1,219 @@
+package risk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVelocityCounter struct {
+	count  int64
+	byCard map[string]int64
+}
+
+func (f *fakeVelocityCounter) CountByMerchantSince(merchantID string, since time.Time) (int64, error) {
+	return f.count, nil
+}
+
+func (f *fakeVelocityCounter) CountByCardSince(fingerprint string, since time.Time) (int64, error) {
+	return f.byCard[fingerprint], nil
+}
+
+const testRules = `
+thresholds:
+  review: 50
+  decline: 100
+rules:
+  - name: high-amount-brl
+    type: amount
+    currency: BRL
+    above: 1000
+    score: 60
+  - name: velocity
+    type: velocity
+    window: 1m
+    max: 3
+    score: 40
+  - name: blocked
+    type: blocked_description
+    patterns: ["gift ?card"]
+    action: DECLINE
+  - name: night
+    type: unusual_hours
+    from: "22:00"
+    to: "05:00"
+    timezone: America/Sao_Paulo
+    score: 20
+`
+
+func newTestEngine(t *testing.T, velocity int64, now time.Time) *Engine {
+	ruleSet, err := ParseRuleSet([]byte(testRules))
+	require.NoError(t, err)
+
+	engine := NewEngine(ruleSet, &fakeVelocityCounter{count: velocity})
+	engine.now = func() time.Time { return now }
+	return engine
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	noon := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	night := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC) // 01:00 em São Paulo
+
+	tests := []struct {
+		name        string
+		transaction *domain.Transaction
+		velocity    int64
+		now         time.Time
+		score       int
+		decision    string
+	}{
+		{
+			name:        "Should approve a regular transaction",
+			transaction: &domain.Transaction{Amount: 100, CurrencyCode: "BRL", Description: "Compra", MerchantID: "m1"},
+			now:         noon,
+			score:       0,
+			decision:    domain.RiskDecisionApprove,
+		},
+		{
+			name:        "Should review a high BRL amount",
+			transaction: &domain.Transaction{Amount: 5000, CurrencyCode: "BRL", Description: "Compra", MerchantID: "m1"},
+			now:         noon,
+			score:       60,
+			decision:    domain.RiskDecisionReview,
+		},
+		{
+			name:        "Should ignore the amount threshold of another currency",
+			transaction: &domain.Transaction{Amount: 5000, CurrencyCode: "USD", Description: "Compra", MerchantID: "m1"},
+			now:         noon,
+			score:       0,
+			decision:    domain.RiskDecisionApprove,
+		},
+		{
+			name:        "Should decline when score reaches the decline threshold",
+			transaction: &domain.Transaction{Amount: 5000, CurrencyCode: "BRL", Description: "Compra", MerchantID: "m1"},
+			velocity:    3,
+			now:         noon,
+			score:       100,
+			decision:    domain.RiskDecisionDecline,
+		},
+		{
+			name:        "Should decline a blocked description regardless of score",
+			transaction: &domain.Transaction{Amount: 10, CurrencyCode: "BRL", Description: "Gift Card", MerchantID: "m1"},
+			now:         noon,
+			score:       0,
+			decision:    domain.RiskDecisionDecline,
+		},
+		{
+			name:        "Should score unusual hours in the configured timezone",
+			transaction: &domain.Transaction{Amount: 10, CurrencyCode: "BRL", Description: "Compra", MerchantID: "m1"},
+			now:         night,
+			score:       20,
+			decision:    domain.RiskDecisionApprove,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			engine := newTestEngine(t, tt.velocity, tt.now)
+
+			// Act
+			assessment, err := engine.Evaluate(context.Background(), tt.transaction)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.score, assessment.Score)
+			assert.Equal(t, tt.decision, assessment.Decision)
+		})
+	}
+}
+
+func TestEngine_CardVelocity(t *testing.T) {
+	ruleSet, err := ParseRuleSet([]byte(`
+rules:
+  - name: card-velocity
+    type: velocity
+    key: card
+    window: 10m
+    max: 2
+    action: DECLINE
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		transaction *domain.Transaction
+		decision    string
+	}{
+		{
+			name:        "Should decline a card used too often, even by another merchant",
+			transaction: &domain.Transaction{Amount: 10, CurrencyCode: "BRL", MerchantID: "m2", CardFingerprint: "busy-card"},
+			decision:    domain.RiskDecisionDecline,
+		},
+		{
+			name:        "Should approve a card below the limit while the merchant is busy",
+			transaction: &domain.Transaction{Amount: 10, CurrencyCode: "BRL", MerchantID: "m1", CardFingerprint: "quiet-card"},
+			decision:    domain.RiskDecisionApprove,
+		},
+		{
+			name:        "Should skip the rule for a transaction without card",
+			transaction: &domain.Transaction{Amount: 10, CurrencyCode: "BRL", MerchantID: "m1"},
+			decision:    domain.RiskDecisionApprove,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			engine := NewEngine(ruleSet, &fakeVelocityCounter{count: 1000, byCard: map[string]int64{"busy-card": 2, "quiet-card": 1}})
+
+			// Act
+			assessment, err := engine.Evaluate(context.Background(), tt.transaction)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.decision, assessment.Decision)
+		})
+	}
+}
+
+func TestParseRuleSet_InvalidRule(t *testing.T) {
+	// Act
+	_, err := ParseRuleSet([]byte("rules:\n  - name: x\n    type: unknown\n"))
+	_, keyErr := ParseRuleSet([]byte("rules:\n  - name: x\n    type: velocity\n    window: 1m\n    max: 1\n    key: ip\n"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Error(t, keyErr)
+}
+
+func TestEngine_Watch(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules: []\n"), 0o600))
+
+	ruleSet, err := LoadRuleSet(path)
+	require.NoError(t, err)
+	engine := NewEngine(ruleSet, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Watch(ctx, path, 10*time.Millisecond)
+
+	// Act
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte(testRules), 0o600))
+	future := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, future, future))
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		return len(engine.rules.Load().Rules) == 4
+	}, time.Second, 10*time.Millisecond)
+}
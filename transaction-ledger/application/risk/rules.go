@@ -0,0 +1,162 @@
+package risk
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RuleTypeAmount             = "amount"
+	RuleTypeVelocity           = "velocity"
+	RuleTypeBlockedDescription = "blocked_description"
+	RuleTypeUnusualHours       = "unusual_hours"
+)
+
+// Chaves das regras de velocidade: o que é contado dentro da janela
+const (
+	VelocityKeyMerchant = "merchant"
+	VelocityKeyCard     = "card"
+)
+
+type Thresholds struct {
+	Review  int `yaml:"review"`
+	Decline int `yaml:"decline"`
+}
+
+// Rule é a definição declarativa de uma regra no arquivo YAML; os campos usados dependem do Type
+type Rule struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Score  int    `yaml:"score"`
+	Action string `yaml:"action"`
+
+	// amount
+	Currency string  `yaml:"currency"`
+	Above    float64 `yaml:"above"`
+
+	// velocity; Key é merchant (padrão) ou card
+	Window time.Duration `yaml:"window"`
+	Max    int64         `yaml:"max"`
+	Key    string        `yaml:"key"`
+
+	// blocked_description
+	Patterns []string `yaml:"patterns"`
+
+	// unusual_hours
+	From     string `yaml:"from"`
+	To       string `yaml:"to"`
+	Timezone string `yaml:"timezone"`
+
+	patterns []*regexp.Regexp
+	fromMin  int
+	toMin    int
+	location *time.Location
+}
+
+type RuleSet struct {
+	Thresholds Thresholds `yaml:"thresholds"`
+	Rules      []Rule     `yaml:"rules"`
+}
+
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler regras de risco: %v", err)
+	}
+	return ParseRuleSet(data)
+}
+
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	var ruleSet RuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("erro ao converter regras de risco: %v", err)
+	}
+
+	if ruleSet.Thresholds.Review <= 0 {
+		ruleSet.Thresholds.Review = 50
+	}
+	if ruleSet.Thresholds.Decline <= 0 {
+		ruleSet.Thresholds.Decline = 100
+	}
+
+	for i := range ruleSet.Rules {
+		if err := ruleSet.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("regra %q inválida: %v", ruleSet.Rules[i].Name, err)
+		}
+	}
+
+	return &ruleSet, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Action != "" && r.Action != domain.RiskDecisionReview && r.Action != domain.RiskDecisionDecline {
+		return fmt.Errorf("ação desconhecida: %s", r.Action)
+	}
+
+	switch r.Type {
+	case RuleTypeAmount:
+		if r.Above <= 0 {
+			return fmt.Errorf("above deve ser maior que 0")
+		}
+	case RuleTypeVelocity:
+		if r.Window <= 0 || r.Max <= 0 {
+			return fmt.Errorf("window e max são obrigatórios")
+		}
+		if r.Key == "" {
+			r.Key = VelocityKeyMerchant
+		}
+		if r.Key != VelocityKeyMerchant && r.Key != VelocityKeyCard {
+			return fmt.Errorf("chave de velocidade desconhecida: %s", r.Key)
+		}
+	case RuleTypeBlockedDescription:
+		for _, pattern := range r.Patterns {
+			compiled, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return err
+			}
+			r.patterns = append(r.patterns, compiled)
+		}
+	case RuleTypeUnusualHours:
+		var err error
+		if r.fromMin, err = parseClock(r.From); err != nil {
+			return err
+		}
+		if r.toMin, err = parseClock(r.To); err != nil {
+			return err
+		}
+		r.location = time.UTC
+		if r.Timezone != "" {
+			if r.location, err = time.LoadLocation(r.Timezone); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("tipo desconhecido: %s", r.Type)
+	}
+
+	return nil
+}
+
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("horário inválido %q", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// inWindow considera janelas que atravessam a meia-noite (ex.: 22:00-05:00)
+func (r *Rule) inWindow(t time.Time) bool {
+	local := t.In(r.location)
+	minute := local.Hour()*60 + local.Minute()
+	if r.fromMin <= r.toMin {
+		return minute >= r.fromMin && minute < r.toMin
+	}
+	return minute >= r.fromMin || minute < r.toMin
+}
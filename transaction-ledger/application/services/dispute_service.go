@@ -0,0 +1,438 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDisputeNotFound          = errors.New("dispute not found")
+	ErrEvidenceNotFound         = errors.New("evidence not found")
+	ErrEvidenceTooLarge         = errors.New("evidence file is too large")
+	ErrEvidenceEmpty            = errors.New("evidence file is empty")
+	ErrEvidenceContentType      = errors.New("evidence file type is not allowed")
+	ErrInvalidDisputeEvent      = errors.New("dispute event must be OPENED, WON or LOST")
+	ErrProcessorDisputeRequired = errors.New("processor_dispute_id is required")
+)
+
+type DisputeConfig struct {
+	MaxEvidenceBytes int64
+	// EvidenceTypes são os tipos aceitos, detectados pelo conteúdo e não pela extensão do arquivo
+	EvidenceTypes []string
+	BatchSize     int
+}
+
+func DefaultEvidenceTypes() []string {
+	return []string{"application/pdf", "image/png", "image/jpeg", "text/plain"}
+}
+
+// DisputeService controla o ciclo de vida dos chargebacks: abertura e decisão vindas do processador,
+// envio de evidências pelo merchant e o débito no ledger das disputas perdidas
+type DisputeService struct {
+	repository   dRepo.DisputeRepository
+	transactions dRepo.TransactionRepository
+	storage      storage.FileStorage
+	config       DisputeConfig
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewDisputeService(repository dRepo.DisputeRepository, transactions dRepo.TransactionRepository, fileStorage storage.FileStorage, config DisputeConfig) *DisputeService {
+	if config.MaxEvidenceBytes < 1 {
+		config.MaxEvidenceBytes = 10 << 20
+	}
+	if len(config.EvidenceTypes) == 0 {
+		config.EvidenceTypes = DefaultEvidenceTypes()
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	return &DisputeService{
+		repository:   repository,
+		transactions: transactions,
+		storage:      fileStorage,
+		config:       config,
+		logger:       logger.Log,
+		now:          time.Now,
+	}
+}
+
+// Ingest aplica uma notificação do processador. Notificações repetidas não mudam nada, já que o
+// tópico pode entregar a mesma mensagem mais de uma vez.
+func (s *DisputeService) Ingest(ctx context.Context, notification dto.DisputeNotificationDto) (*domain.Dispute, error) {
+	if notification.ProcessorDisputeID == "" {
+		return nil, ErrProcessorDisputeRequired
+	}
+
+	switch notification.Event {
+	case dto.DisputeEventOpened:
+		return s.open(notification)
+	case dto.DisputeEventWon, dto.DisputeEventLost:
+		dispute, err := s.repository.FindByProcessorID(notification.ProcessorDisputeID)
+		if err != nil {
+			return nil, ErrDisputeNotFound
+		}
+		return dispute, s.resolve(dispute, notification.Event == dto.DisputeEventWon)
+	default:
+		return nil, ErrInvalidDisputeEvent
+	}
+}
+
+func (s *DisputeService) open(notification dto.DisputeNotificationDto) (*domain.Dispute, error) {
+	if existing, err := s.repository.FindByProcessorID(notification.ProcessorDisputeID); err == nil {
+		return existing, nil
+	}
+
+	transaction, err := s.transactions.FindByID(notification.TransactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	disputed, err := s.repository.SumDisputedAmount(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dueAt time.Time
+	if notification.EvidenceDueAt != nil {
+		dueAt = *notification.EvidenceDueAt
+	}
+	dispute, err := domain.NewDispute(transaction, disputed, notification.ProcessorDisputeID, strings.ToUpper(notification.ReasonCode), notification.Amount, dueAt, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repository.Create(dispute)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		// outra réplica gravou a mesma notificação primeiro
+		return s.repository.FindByProcessorID(notification.ProcessorDisputeID)
+	}
+
+	s.logger.Info("disputa aberta",
+		zap.String("disputeId", dispute.ID),
+		zap.String("transactionId", dispute.TransactionID),
+		zap.String("reasonCode", dispute.ReasonCode),
+		zap.Time("evidenceDueAt", dispute.EvidenceDueAt),
+	)
+	return dispute, nil
+}
+
+func (s *DisputeService) resolve(dispute *domain.Dispute, won bool) error {
+	if (won && dispute.Status == domain.DisputeWon) || (!won && dispute.Status == domain.DisputeLost) {
+		return nil
+	}
+
+	previousStatus := dispute.Status
+	adjustment, err := dispute.Resolve(won, s.now())
+	if err != nil {
+		return err
+	}
+	if err := s.repository.Resolve(dispute, previousStatus, adjustment); err != nil {
+		return err
+	}
+
+	fields := []zap.Field{
+		zap.String("disputeId", dispute.ID),
+		zap.String("transactionId", dispute.TransactionID),
+		zap.String("status", dispute.Status),
+	}
+	if adjustment != nil {
+		fields = append(fields, zap.String("adjustmentId", adjustment.ID), zap.Float64("amount", adjustment.Amount))
+	}
+	s.logger.Info("disputa decidida", fields...)
+	return nil
+}
+
+// FindByID devolve a disputa somente se ela for visível para o principal
+func (s *DisputeService) FindByID(ctx context.Context, id string) (*domain.Dispute, error) {
+	dispute, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, ErrDisputeNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(dispute.MerchantID, dispute.TenantID) {
+		return nil, ErrDisputeNotFound
+	}
+	return dispute, nil
+}
+
+// Detail devolve a disputa com as evidências e o débito, quando houver
+func (s *DisputeService) Detail(ctx context.Context, id string) (*dto.DisputeResponseDto, error) {
+	dispute, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.repository.FindEvidence(dispute.ID)
+	if err != nil {
+		return nil, err
+	}
+	if evidence == nil {
+		evidence = []domain.DisputeEvidence{}
+	}
+
+	adjustment, err := s.repository.FindAdjustment(dispute.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DisputeResponseDto{Dispute: dispute, Evidence: evidence, Adjustment: adjustment}, nil
+}
+
+func (s *DisputeService) FindPaginated(ctx context.Context, filter dRepo.DisputeFilter, page, pageSize int) (*dto.PaginatedDisputesResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	disputes, total, err := s.repository.FindPaginated(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedDisputesResponseDto{
+		Data:       disputes,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// AddEvidence grava o arquivo no armazenamento e o anexa à disputa enquanto ela estiver OPENED e no prazo
+func (s *DisputeService) AddEvidence(ctx context.Context, id, fileName string, r io.Reader) (*domain.DisputeEvidence, error) {
+	dispute, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := dispute.AcceptsEvidence(s.now()); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReaderSize(r, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(head) == 0 {
+		return nil, ErrEvidenceEmpty
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !slices.Contains(s.config.EvidenceTypes, contentType) {
+		return nil, ErrEvidenceContentType
+	}
+
+	evidence := &domain.DisputeEvidence{
+		ID:          uuid.New().String(),
+		DisputeID:   dispute.ID,
+		FileName:    evidenceFileName(fileName),
+		ContentType: contentType,
+	}
+	evidence.StorageKey = path.Join("disputes", dispute.ID, evidence.ID)
+
+	hash := sha256.New()
+	limited := io.LimitReader(reader, s.config.MaxEvidenceBytes+1)
+	size, err := s.storage.Save(ctx, evidence.StorageKey, io.TeeReader(limited, hash))
+	if err != nil {
+		return nil, err
+	}
+	if size > s.config.MaxEvidenceBytes {
+		s.storage.Delete(ctx, evidence.StorageKey)
+		return nil, ErrEvidenceTooLarge
+	}
+	evidence.Size = size
+	evidence.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.repository.AddEvidence(evidence); err != nil {
+		s.storage.Delete(ctx, evidence.StorageKey)
+		return nil, err
+	}
+
+	s.logger.Info("evidência anexada à disputa",
+		zap.String("disputeId", dispute.ID),
+		zap.String("evidenceId", evidence.ID),
+		zap.Int64("size", evidence.Size),
+	)
+	return evidence, nil
+}
+
+// evidenceFileName guarda só o nome do arquivo enviado, sem diretórios, para exibição e download
+func evidenceFileName(fileName string) string {
+	name := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if name == "." || name == "/" {
+		name = "evidence"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+// OpenEvidence devolve os metadados e o conteúdo de um arquivo de evidência; quem chama fecha o conteúdo
+func (s *DisputeService) OpenEvidence(ctx context.Context, id, evidenceID string) (*domain.DisputeEvidence, io.ReadCloser, error) {
+	dispute, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	evidence, err := s.repository.FindEvidence(dispute.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := slices.IndexFunc(evidence, func(e domain.DisputeEvidence) bool { return e.ID == evidenceID })
+	if index < 0 {
+		return nil, nil, ErrEvidenceNotFound
+	}
+
+	content, err := s.storage.Open(ctx, evidence[index].StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrEvidenceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &evidence[index], content, nil
+}
+
+// SubmitEvidence encerra o envio de evidências e deixa a disputa aguardando a decisão do processador
+func (s *DisputeService) SubmitEvidence(ctx context.Context, id string) (*domain.Dispute, error) {
+	dispute, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := s.repository.FindEvidence(dispute.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := dispute.SubmitEvidence(len(evidence), s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateIfStatus(dispute, domain.DisputeOpened); err != nil {
+		if errors.Is(err, domain.ErrorDisputeStatusChanged) {
+			return nil, domain.ErrorDisputeNotOpen
+		}
+		return nil, err
+	}
+
+	s.logger.Info("evidências da disputa enviadas",
+		zap.String("disputeId", dispute.ID),
+		zap.Int("files", len(evidence)),
+	)
+	return dispute, nil
+}
+
+// ExpireOverdue fecha para evidências as disputas OPENED cujo prazo venceu sem envio. Elas seguem em
+// EVIDENCE_OVERDUE até o processador mandar WON ou LOST; nenhum débito é lançado aqui.
+func (s *DisputeService) ExpireOverdue(ctx context.Context) (int, error) {
+	now := s.now()
+	disputes, err := s.repository.FindOverdue(now, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range disputes {
+		if err := ctx.Err(); err != nil {
+			return expired, err
+		}
+
+		dispute := &disputes[i]
+		if err := dispute.CloseEvidence(now); err != nil {
+			continue
+		}
+		if err := s.repository.UpdateIfStatus(dispute, domain.DisputeOpened); err != nil {
+			if !errors.Is(err, domain.ErrorDisputeStatusChanged) {
+				s.logger.Error("erro ao encerrar prazo de evidência da disputa",
+					zap.Error(err),
+					zap.String("disputeId", dispute.ID),
+				)
+			}
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// Adjustments pagina os ajustes do ledger do merchant e do tenant do principal
+func (s *DisputeService) Adjustments(ctx context.Context, page, pageSize int) (*dto.PaginatedLedgerAdjustmentsResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	merchantID, tenantID := "", ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID, tenantID = principal.MerchantID, principal.TenantID
+	}
+
+	adjustments, total, err := s.repository.FindAdjustments(merchantID, tenantID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedLedgerAdjustmentsResponseDto{
+		Data:       adjustments,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *DisputeService) StartDeadlineWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireOverdue(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar disputas com prazo vencido",
+					zap.Error(err),
+				)
+				continue
+			}
+			if expired > 0 {
+				s.logger.Info("disputas com prazo de evidência vencido",
+					zap.Int("expired", expired),
+				)
+			}
+		}
+	}
+}
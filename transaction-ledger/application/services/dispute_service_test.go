@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type disputedTransactionRepository struct {
+	dRepo.TransactionRepository
+
+	transaction *domain.Transaction
+}
+
+func (m *disputedTransactionRepository) FindByID(id string) (*domain.Transaction, error) {
+	if m.transaction.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return m.transaction, nil
+}
+
+type memoryDisputeRepository struct {
+	dRepo.DisputeRepository
+
+	disputes    map[string]*domain.Dispute
+	evidence    []domain.DisputeEvidence
+	adjustments []domain.LedgerAdjustment
+}
+
+func (m *memoryDisputeRepository) Create(dispute *domain.Dispute) (bool, error) {
+	for _, existing := range m.disputes {
+		if existing.ProcessorDisputeID == dispute.ProcessorDisputeID {
+			return false, nil
+		}
+	}
+	copied := *dispute
+	m.disputes[dispute.ID] = &copied
+	return true, nil
+}
+
+func (m *memoryDisputeRepository) SumDisputedAmount(transactionID string) (float64, error) {
+	var total float64
+	for _, dispute := range m.disputes {
+		if dispute.TransactionID == transactionID && dispute.Status != domain.DisputeWon {
+			total += dispute.Amount
+		}
+	}
+	return total, nil
+}
+
+func (m *memoryDisputeRepository) FindByID(id string) (*domain.Dispute, error) {
+	dispute, ok := m.disputes[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *dispute
+	return &copied, nil
+}
+
+func (m *memoryDisputeRepository) FindByProcessorID(processorDisputeID string) (*domain.Dispute, error) {
+	for _, dispute := range m.disputes {
+		if dispute.ProcessorDisputeID == processorDisputeID {
+			copied := *dispute
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *memoryDisputeRepository) UpdateIfStatus(dispute *domain.Dispute, expectedStatus string) error {
+	if m.disputes[dispute.ID].Status != expectedStatus {
+		return domain.ErrorDisputeStatusChanged
+	}
+	copied := *dispute
+	m.disputes[dispute.ID] = &copied
+	return nil
+}
+
+func (m *memoryDisputeRepository) Resolve(dispute *domain.Dispute, expectedStatus string, adjustment *domain.LedgerAdjustment) error {
+	if err := m.UpdateIfStatus(dispute, expectedStatus); err != nil {
+		return err
+	}
+	if adjustment != nil {
+		m.adjustments = append(m.adjustments, *adjustment)
+	}
+	return nil
+}
+
+func (m *memoryDisputeRepository) FindOverdue(now time.Time, limit int) ([]domain.Dispute, error) {
+	var overdue []domain.Dispute
+	for _, dispute := range m.disputes {
+		if dispute.Status == domain.DisputeOpened && dispute.EvidenceDueAt.Before(now) {
+			overdue = append(overdue, *dispute)
+		}
+	}
+	return overdue, nil
+}
+
+func (m *memoryDisputeRepository) AddEvidence(evidence *domain.DisputeEvidence) error {
+	if m.disputes[evidence.DisputeID].Status != domain.DisputeOpened {
+		return domain.ErrorDisputeNotOpen
+	}
+	m.evidence = append(m.evidence, *evidence)
+	return nil
+}
+
+func (m *memoryDisputeRepository) FindEvidence(disputeID string) ([]domain.DisputeEvidence, error) {
+	var evidence []domain.DisputeEvidence
+	for _, e := range m.evidence {
+		if e.DisputeID == disputeID {
+			evidence = append(evidence, e)
+		}
+	}
+	return evidence, nil
+}
+
+func TestDisputeService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	opened := dto.DisputeNotificationDto{ProcessorDisputeID: "dp-1", TransactionID: "tx-1", Event: dto.DisputeEventOpened, ReasonCode: "fraud"}
+
+	newService := func(t *testing.T) (*DisputeService, *memoryDisputeRepository) {
+		fileStorage, err := storage.NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+
+		repository := &memoryDisputeRepository{disputes: make(map[string]*domain.Dispute)}
+		transactions := &disputedTransactionRepository{transaction: &domain.Transaction{
+			ID: "tx-1", MerchantID: "merchant-1", TenantID: "tenant-a", Status: domain.TransactionFinished, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Amount: 120,
+		}}
+		service := NewDisputeService(repository, transactions, fileStorage, DisputeConfig{MaxEvidenceBytes: 64})
+		service.now = func() time.Time { return now }
+		return service, repository
+	}
+
+	t.Run("Should ingest repeated notifications once and debit the merchant a single time when lost", func(t *testing.T) {
+		// Arrange
+		service, repository := newService(t)
+		lost := dto.DisputeNotificationDto{ProcessorDisputeID: "dp-1", Event: dto.DisputeEventLost}
+
+		// Act
+		first, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+		second, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+		_, lostErr := service.Ingest(ctx, lost)
+		_, repeatedErr := service.Ingest(ctx, lost)
+
+		// Assert
+		assert.Equal(t, first.ID, second.ID)
+		assert.Len(t, repository.disputes, 1)
+		assert.Equal(t, "FRAUD", first.ReasonCode)
+		assert.Equal(t, 120.0, first.Amount)
+		require.NoError(t, lostErr)
+		require.NoError(t, repeatedErr)
+		assert.Equal(t, domain.DisputeLost, repository.disputes[first.ID].Status)
+		require.Len(t, repository.adjustments, 1)
+		assert.Equal(t, domain.LedgerAdjustmentDebit, repository.adjustments[0].Type)
+		assert.Equal(t, 120.0, repository.adjustments[0].Amount)
+	})
+
+	t.Run("Should refuse a new dispute once open and lost disputes cover the settled amount", func(t *testing.T) {
+		// Arrange
+		service, repository := newService(t)
+		second := dto.DisputeNotificationDto{ProcessorDisputeID: "dp-2", TransactionID: "tx-1", Event: dto.DisputeEventOpened, ReasonCode: "duplicate"}
+		_, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+
+		// Act
+		_, coveredErr := service.Ingest(ctx, second)
+		_, err = service.Ingest(ctx, dto.DisputeNotificationDto{ProcessorDisputeID: "dp-1", Event: dto.DisputeEventWon})
+		require.NoError(t, err)
+		reopened, reopenErr := service.Ingest(ctx, second)
+
+		// Assert
+		assert.ErrorIs(t, coveredErr, domain.ErrorDisputedAmountExceeded)
+		require.NoError(t, reopenErr, "a won dispute should give the amount back")
+		assert.Equal(t, 120.0, reopened.Amount)
+		assert.Len(t, repository.disputes, 2)
+	})
+
+	t.Run("Should hide disputes and their debits from another tenant of the same merchant", func(t *testing.T) {
+		// Arrange
+		service, repository := newService(t)
+		dispute, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+		_, err = service.Ingest(ctx, dto.DisputeNotificationDto{ProcessorDisputeID: "dp-1", Event: dto.DisputeEventLost})
+		require.NoError(t, err)
+		owner := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-a"})
+		other := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-b"})
+
+		// Act
+		_, ownerErr := service.FindByID(owner, dispute.ID)
+		_, otherErr := service.FindByID(other, dispute.ID)
+
+		// Assert
+		assert.Equal(t, "tenant-a", dispute.TenantID)
+		require.NoError(t, ownerErr)
+		assert.ErrorIs(t, otherErr, ErrDisputeNotFound)
+		require.Len(t, repository.adjustments, 1)
+		assert.Equal(t, "tenant-a", repository.adjustments[0].TenantID)
+	})
+
+	t.Run("Should store evidence files by content type and close uploads once submitted", func(t *testing.T) {
+		// Arrange
+		service, repository := newService(t)
+		dispute, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+
+		// Act
+		_, typeErr := service.AddEvidence(ctx, dispute.ID, "invoice.pdf", strings.NewReader("MZ\x90\x00\x03\x00\x00\x00"))
+		_, sizeErr := service.AddEvidence(ctx, dispute.ID, "big.txt", strings.NewReader(strings.Repeat("a", 65)))
+		evidence, err := service.AddEvidence(ctx, dispute.ID, `C:\docs\invoice.pdf`, strings.NewReader("%PDF-1.4 nota fiscal"))
+		require.NoError(t, err)
+		submitted, submitErr := service.SubmitEvidence(ctx, dispute.ID)
+		_, lateErr := service.AddEvidence(ctx, dispute.ID, "extra.txt", strings.NewReader("comprovante"))
+		_, content, openErr := service.OpenEvidence(ctx, dispute.ID, evidence.ID)
+
+		// Assert
+		assert.ErrorIs(t, typeErr, ErrEvidenceContentType)
+		assert.ErrorIs(t, sizeErr, ErrEvidenceTooLarge)
+		assert.Equal(t, "invoice.pdf", evidence.FileName)
+		assert.Equal(t, "application/pdf", evidence.ContentType)
+		assert.Len(t, evidence.SHA256, 64)
+		require.NoError(t, submitErr)
+		assert.Equal(t, domain.DisputeEvidenceSubmitted, submitted.Status)
+		assert.ErrorIs(t, lateErr, domain.ErrorDisputeNotOpen)
+		assert.Len(t, repository.evidence, 1)
+
+		require.NoError(t, openErr)
+		defer content.Close()
+		stored, err := io.ReadAll(content)
+		require.NoError(t, err)
+		assert.Equal(t, "%PDF-1.4 nota fiscal", string(stored))
+	})
+
+	t.Run("Should close overdue disputes to evidence and leave the decision to the processor", func(t *testing.T) {
+		// Arrange
+		service, repository := newService(t)
+		dispute, err := service.Ingest(ctx, opened)
+		require.NoError(t, err)
+		service.now = func() time.Time { return dispute.EvidenceDueAt.Add(time.Minute) }
+
+		// Act
+		expired, err := service.ExpireOverdue(ctx)
+		_, evidenceErr := service.AddEvidence(ctx, dispute.ID, "nota.pdf", strings.NewReader("%PDF-1.4 nota fiscal"))
+		_, wonErr := service.Ingest(ctx, dto.DisputeNotificationDto{ProcessorDisputeID: "dp-1", Event: dto.DisputeEventWon})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.ErrorIs(t, evidenceErr, domain.ErrorDisputeNotOpen)
+		require.NoError(t, wonErr)
+		assert.Equal(t, domain.DisputeWon, repository.disputes[dispute.ID].Status)
+		assert.Empty(t, repository.adjustments)
+	})
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"go.uber.org/zap"
+)
+
+const eventCleanupBatchSize = 1000
+
+// EventBus espalha os eventos entre as réplicas do ledger; cada réplica entrega aos seus próprios streams
+type EventBus interface {
+	Publish(ctx context.Context, payload []byte) error
+	Listen(ctx context.Context, handler func(payload []byte)) error
+}
+
+// EventSubscription recebe os eventos ao vivo de um stream. O canal é fechado quando o assinante
+// não acompanha o ritmo; o cliente então reconecta com Last-Event-ID e recupera o que perdeu do banco.
+type EventSubscription struct {
+	Events <-chan domain.TransactionEvent
+
+	events  chan domain.TransactionEvent
+	filter  dRepo.TransactionEventFilter
+	service *EventStreamService
+}
+
+func (s *EventSubscription) Close() {
+	s.service.unsubscribe(s)
+}
+
+// EventStreamService grava cada mudança de status, publica no barramento e distribui para os streams SSE
+type EventStreamService struct {
+	repository dRepo.TransactionEventRepository
+	bus        EventBus
+	logger     *zap.Logger
+	bufferSize int
+
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+}
+
+func NewEventStreamService(repository dRepo.TransactionEventRepository, bus EventBus, bufferSize int) *EventStreamService {
+	if bufferSize < 1 {
+		bufferSize = 64
+	}
+	return &EventStreamService{
+		repository:  repository,
+		bus:         bus,
+		logger:      logger.Log,
+		bufferSize:  bufferSize,
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// TransactionStatusChanged grava o evento e o publica; a entrega local também passa pelo barramento
+func (s *EventStreamService) TransactionStatusChanged(ctx context.Context, transaction *domain.Transaction, previousStatus string) {
+	event := domain.NewTransactionEvent(transaction, previousStatus)
+	if err := s.repository.Create(event); err != nil {
+		s.logger.Error("erro ao gravar evento da transação",
+			zap.Error(err),
+			zap.String("transactionId", transaction.ID),
+		)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("erro ao serializar evento da transação",
+			zap.Error(err),
+			zap.Int64("eventId", event.ID),
+		)
+		return
+	}
+	if err := s.bus.Publish(ctx, payload); err != nil {
+		// o evento está no banco: quem reconectar com Last-Event-ID ainda o recebe
+		s.logger.Error("erro ao publicar evento da transação",
+			zap.Error(err),
+			zap.Int64("eventId", event.ID),
+		)
+	}
+}
+
+// Start escuta o barramento até o contexto ser cancelado
+func (s *EventStreamService) Start(ctx context.Context) {
+	if err := s.bus.Listen(ctx, s.receive); err != nil {
+		s.logger.Error("erro ao escutar eventos das transações",
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *EventStreamService) receive(payload []byte) {
+	var event domain.TransactionEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		s.logger.Error("evento da transação inválido",
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscribers {
+		if !eventMatches(subscription.filter, &event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			s.logger.Warn("stream de eventos lento, desconectando assinante",
+				zap.String("transactionId", subscription.filter.TransactionID),
+				zap.String("merchantId", subscription.filter.MerchantID),
+			)
+			delete(s.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// Subscribe deve ser chamado antes de Replay, para que nenhum evento caia entre o histórico e o ao vivo
+func (s *EventStreamService) Subscribe(filter dRepo.TransactionEventFilter) *EventSubscription {
+	events := make(chan domain.TransactionEvent, s.bufferSize)
+	subscription := &EventSubscription{Events: events, events: events, filter: filter, service: s}
+
+	s.mu.Lock()
+	s.subscribers[subscription] = struct{}{}
+	s.mu.Unlock()
+	return subscription
+}
+
+func (s *EventStreamService) unsubscribe(subscription *EventSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[subscription]; ok {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Replay devolve os eventos gravados depois de lastEventID, para retomar um stream interrompido
+func (s *EventStreamService) Replay(filter dRepo.TransactionEventFilter, lastEventID int64, limit int) ([]domain.TransactionEvent, error) {
+	return s.repository.FindAfter(filter, lastEventID, limit)
+}
+
+// StartCleanup apaga periodicamente os eventos mais antigos que retention, até o contexto ser cancelado
+func (s *EventStreamService) StartCleanup(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup(ctx, time.Now().Add(-retention))
+		}
+	}
+}
+
+// Cleanup apaga em lotes os eventos criados antes de before. Um stream retomado com um Last-Event-ID
+// mais antigo recebe só o que ainda está guardado.
+func (s *EventStreamService) Cleanup(ctx context.Context, before time.Time) int64 {
+	var deleted int64
+	for ctx.Err() == nil {
+		count, err := s.repository.DeleteBefore(before, eventCleanupBatchSize)
+		if err != nil {
+			s.logger.Error("erro ao apagar eventos antigos das transações",
+				zap.Error(err),
+			)
+			break
+		}
+		deleted += count
+		if count < eventCleanupBatchSize {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		s.logger.Info("eventos antigos das transações apagados",
+			zap.Int64("count", deleted),
+		)
+	}
+	return deleted
+}
+
+func eventMatches(filter dRepo.TransactionEventFilter, event *domain.TransactionEvent) bool {
+	if filter.TransactionID != "" && event.TransactionID != filter.TransactionID {
+		return false
+	}
+	if filter.MerchantID != "" && event.MerchantID != filter.MerchantID {
+		return false
+	}
+	return filter.TenantID == "" || event.TenantID == filter.TenantID
+}
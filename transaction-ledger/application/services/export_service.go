@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrExportNotFound     = errors.New("export not found")
+	ErrExportQueueFull    = errors.New("export queue is full")
+	ErrExportNotAvailable = errors.New("export file is not available")
+)
+
+type ExportConfig struct {
+	// Dir recebe os arquivos; com várias réplicas deve ser um volume compartilhado para o download funcionar em todas
+	Dir        string
+	Workers    int
+	QueueSize  int
+	TTL        time.Duration
+	StaleAfter time.Duration
+}
+
+// ExportService gera exportações grandes em segundo plano, gravando o arquivo em Dir
+type ExportService struct {
+	repository   dRepo.ExportRepository
+	transactions *TransactionService
+	config       ExportConfig
+	queue        chan *domain.ExportJob
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewExportService(repository dRepo.ExportRepository, transactions *TransactionService, config ExportConfig) *ExportService {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	return &ExportService{
+		repository:   repository,
+		transactions: transactions,
+		config:       config,
+		queue:        make(chan *domain.ExportJob, config.QueueSize),
+		logger:       logger.Log,
+		now:          time.Now,
+	}
+}
+
+// Submit grava o job e o coloca na fila dos workers, com o escopo do chamador
+func (s *ExportService) Submit(ctx context.Context, format string, compress bool) (*domain.ExportJob, error) {
+	var merchantID, tenantID string
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID = principal.MerchantID
+		tenantID = principal.TenantID
+	}
+
+	job, err := domain.NewExportJob(merchantID, tenantID, format, compress)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Create(job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- job:
+		return job, nil
+	default:
+		job.Fail(ErrExportQueueFull.Error(), s.now())
+		if err := s.repository.Update(job); err != nil {
+			s.logger.Error("erro ao gravar exportação recusada",
+				zap.Error(err),
+				zap.String("exportId", job.ID),
+			)
+		}
+		return nil, ErrExportQueueFull
+	}
+}
+
+func (s *ExportService) FindByID(ctx context.Context, id string) (*domain.ExportJob, error) {
+	job, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, ErrExportNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(job.MerchantID, job.TenantID) {
+		return nil, ErrExportNotFound
+	}
+	return job, nil
+}
+
+// File devolve o caminho do arquivo de uma exportação concluída
+func (s *ExportService) File(ctx context.Context, id string) (*domain.ExportJob, string, error) {
+	job, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.Status != domain.ExportCompleted {
+		return nil, "", ErrExportNotAvailable
+	}
+
+	path := filepath.Join(s.config.Dir, job.FileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, "", ErrExportNotAvailable
+	}
+	return job, path, nil
+}
+
+// Start sobe os workers e o limpador de arquivos expirados até o contexto ser cancelado
+func (s *ExportService) Start(ctx context.Context, cleanupInterval time.Duration) {
+	for range s.config.Workers {
+		go s.work(ctx)
+	}
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+		}
+	}
+}
+
+func (s *ExportService) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			s.Run(ctx, job)
+		}
+	}
+}
+
+// Run grava o arquivo com nome temporário e só o renomeia ao final, para nunca servir um arquivo incompleto
+func (s *ExportService) Run(ctx context.Context, job *domain.ExportJob) {
+	job.Status = domain.ExportRunning
+	if err := s.repository.Update(job); err != nil {
+		s.logger.Error("erro ao iniciar exportação",
+			zap.Error(err),
+			zap.String("exportId", job.ID),
+		)
+		return
+	}
+
+	fileName := job.ID + "-" + domain.ExportFileName(job.Format, job.Gzip)
+	path := filepath.Join(s.config.Dir, fileName)
+	partial := path + ".partial"
+
+	rows, size, err := s.write(ctx, job, partial)
+	if err == nil {
+		err = os.Rename(partial, path)
+	}
+	if err != nil {
+		os.Remove(partial)
+		s.logger.Error("erro ao gerar exportação",
+			zap.Error(err),
+			zap.String("exportId", job.ID),
+		)
+		job.Fail(err.Error(), s.now())
+	} else {
+		job.Complete(fileName, rows, size, s.now(), s.config.TTL)
+		s.logger.Info("exportação concluída",
+			zap.String("exportId", job.ID),
+			zap.Int64("rows", rows),
+			zap.Int64("bytes", size),
+		)
+	}
+
+	if err := s.repository.Update(job); err != nil {
+		s.logger.Error("erro ao gravar resultado da exportação",
+			zap.Error(err),
+			zap.String("exportId", job.ID),
+		)
+	}
+}
+
+func (s *ExportService) write(ctx context.Context, job *domain.ExportJob, path string) (int64, int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{MerchantID: job.MerchantID, TenantID: job.TenantID})
+	rows, err := s.transactions.ExportTransactions(ctx, job.Format, job.Gzip, buffered)
+	if err != nil {
+		return rows, 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return rows, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return rows, 0, err
+	}
+	return rows, info.Size(), file.Close()
+}
+
+// Cleanup remove os arquivos expirados e encerra como FAILED os jobs perdidos num restart
+func (s *ExportService) Cleanup() {
+	now := s.now()
+
+	expired, err := s.repository.FindExpired(now, 100)
+	if err != nil {
+		s.logger.Error("erro ao buscar exportações expiradas",
+			zap.Error(err),
+		)
+	}
+	for i := range expired {
+		job := &expired[i]
+		if err := os.Remove(filepath.Join(s.config.Dir, job.FileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error("erro ao remover arquivo de exportação",
+				zap.Error(err),
+				zap.String("exportId", job.ID),
+			)
+			continue
+		}
+		job.Expire()
+		if err := s.repository.Update(job); err != nil {
+			s.logger.Error("erro ao expirar exportação",
+				zap.Error(err),
+				zap.String("exportId", job.ID),
+			)
+		}
+	}
+
+	stale, err := s.repository.FindStale(now.Add(-s.config.StaleAfter), 100)
+	if err != nil {
+		s.logger.Error("erro ao buscar exportações paradas",
+			zap.Error(err),
+		)
+	}
+	for i := range stale {
+		job := &stale[i]
+		job.Fail("exportação interrompida, solicite novamente", now)
+		if err := s.repository.Update(job); err != nil {
+			s.logger.Error("erro ao encerrar exportação parada",
+				zap.Error(err),
+				zap.String("exportId", job.ID),
+			)
+		}
+	}
+}
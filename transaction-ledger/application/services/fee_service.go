@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"go.uber.org/zap"
+)
+
+// FeeService calcula o MDR das transações finalizadas a partir dos planos de taxa vigentes
+type FeeService struct {
+	repository dRepo.FeePlanRepository
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewFeeService(repository dRepo.FeePlanRepository) *FeeService {
+	return &FeeService{repository: repository, logger: logger.Log, now: time.Now}
+}
+
+func (s *FeeService) CreatePlan(ctx context.Context, plan *domain.FeePlan) []error {
+	if errs := plan.Prepare(s.now()); len(errs) > 0 {
+		return errs
+	}
+	if err := s.repository.Create(plan); err != nil {
+		return []error{err}
+	}
+
+	s.logger.Info("plano de taxas criado",
+		zap.String("id", plan.ID),
+		zap.String("merchantId", plan.MerchantID),
+		zap.String("paymentMethod", plan.PaymentMethod),
+		zap.Time("effectiveFrom", plan.EffectiveFrom),
+	)
+	return nil
+}
+
+func (s *FeeService) ListPlans(ctx context.Context, filter dRepo.FeePlanFilter) ([]domain.FeePlan, error) {
+	return s.repository.FindAll(filter)
+}
+
+// Apply precifica pela data de criação da transação, para que mudanças de preço não sejam retroativas
+func (s *FeeService) Apply(ctx context.Context, transaction *domain.Transaction) error {
+	pricedAt := transaction.CreatedAt
+	if pricedAt.IsZero() {
+		pricedAt = s.now()
+	}
+
+	plans, err := s.repository.FindEffective(transaction.MerchantID, transaction.PaymentMethod, pricedAt)
+	if err != nil {
+		return err
+	}
+
+	var selected *domain.FeePlan
+	for i := range plans {
+		plan := &plans[i]
+		if !plan.Matches(transaction, pricedAt) {
+			continue
+		}
+		if selected == nil || plan.Specificity() > selected.Specificity() ||
+			(plan.Specificity() == selected.Specificity() && plan.EffectiveFrom.After(selected.EffectiveFrom)) {
+			selected = plan
+		}
+	}
+
+	gross := transaction.SettledGross()
+	if selected == nil {
+		s.logger.Warn("nenhum plano de taxas vigente para a transação",
+			zap.String("id", transaction.ID),
+			zap.String("merchantId", transaction.MerchantID),
+			zap.String("paymentMethod", transaction.PaymentMethod),
+		)
+		transaction.ApplyFees("", gross, 0)
+		return nil
+	}
+
+	transaction.ApplyFees(selected.ID, gross, selected.Fee(gross, transaction.CurrencyCode))
+	return nil
+}
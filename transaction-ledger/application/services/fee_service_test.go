@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryFeePlanRepository struct {
+	plans []domain.FeePlan
+}
+
+func (m *memoryFeePlanRepository) Create(plan *domain.FeePlan) error {
+	m.plans = append(m.plans, *plan)
+	return nil
+}
+
+func (m *memoryFeePlanRepository) FindAll(filter dRepo.FeePlanFilter) ([]domain.FeePlan, error) {
+	return m.plans, nil
+}
+
+func (m *memoryFeePlanRepository) FindEffective(merchantID, paymentMethod string, at time.Time) ([]domain.FeePlan, error) {
+	return m.plans, nil
+}
+
+func TestFeeService_Apply(t *testing.T) {
+	// Arrange
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryFeePlanRepository{}
+	service := NewFeeService(repo)
+	service.now = func() time.Time { return start }
+
+	create := func(plan domain.FeePlan) {
+		require.Empty(t, service.CreatePlan(context.Background(), &plan))
+	}
+	create(domain.FeePlan{PaymentMethod: "CREDIT_CARD", Percentage: 3.5, FixedFee: 0.5})
+	create(domain.FeePlan{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", Percentage: 2.5})
+	create(domain.FeePlan{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", MinInstallments: 2, Percentage: 4})
+	create(domain.FeePlan{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", Percentage: 1.99, EffectiveFrom: start.AddDate(0, 1, 0)})
+
+	t.Run("Should use the merchant plan effective when the transaction was created", func(t *testing.T) {
+		transaction := &domain.Transaction{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", CurrencyCode: "BRL", Amount: 200, Installments: 1, CreatedAt: start.AddDate(0, 0, 10)}
+
+		// Act
+		require.NoError(t, service.Apply(context.Background(), transaction))
+
+		// Assert
+		assert.Equal(t, repo.plans[1].ID, transaction.FeePlanID)
+		assert.Equal(t, 200.0, transaction.GrossAmount)
+		assert.Equal(t, 5.0, transaction.FeeAmount)
+		assert.Equal(t, 195.0, transaction.NetAmount)
+	})
+
+	t.Run("Should price by installments and by the new plan after its effective date", func(t *testing.T) {
+		installments := &domain.Transaction{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", CurrencyCode: "BRL", Amount: 200, Installments: 3, CreatedAt: start.AddDate(0, 0, 10)}
+		later := &domain.Transaction{MerchantID: "merchant-1", PaymentMethod: "CREDIT_CARD", CurrencyCode: "BRL", Amount: 200, Installments: 1, CreatedAt: start.AddDate(0, 2, 0)}
+
+		// Act
+		require.NoError(t, service.Apply(context.Background(), installments))
+		require.NoError(t, service.Apply(context.Background(), later))
+
+		// Assert
+		assert.Equal(t, 8.0, installments.FeeAmount)
+		assert.Equal(t, 3.98, later.FeeAmount)
+	})
+
+	t.Run("Should fall back to the default plan and charge on the captured amount", func(t *testing.T) {
+		transaction := &domain.Transaction{MerchantID: "merchant-2", PaymentMethod: "CREDIT_CARD", CurrencyCode: "BRL", Amount: 200, CapturedAmount: 100, CreatedAt: start}
+
+		// Act
+		require.NoError(t, service.Apply(context.Background(), transaction))
+
+		// Assert
+		assert.Equal(t, 100.0, transaction.GrossAmount)
+		assert.Equal(t, 4.0, transaction.FeeAmount)
+		assert.Equal(t, 96.0, transaction.NetAmount)
+	})
+
+	t.Run("Should reject retroactive fee plans", func(t *testing.T) {
+		// Act
+		errs := service.CreatePlan(context.Background(), &domain.FeePlan{PaymentMethod: "PIX", Percentage: 1, EffectiveFrom: start.AddDate(0, 0, -1)})
+
+		// Assert
+		assert.Equal(t, []error{domain.ErrorFeePlanRetroactive}, errs)
+	})
+}
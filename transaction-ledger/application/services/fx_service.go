@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"go.uber.org/zap"
+)
+
+type Conversion struct {
+	From   string
+	To     string
+	Rate   float64
+	Amount float64
+	RateAt time.Time
+}
+
+// FXService mantém as cotações em memória, persistidas no Postgres para que todas as réplicas as compartilhem
+type FXService struct {
+	repository   dRepo.FXRateRepository
+	baseCurrency string
+	logger       *zap.Logger
+
+	mu    sync.RWMutex
+	rates map[string]domain.FXRate
+}
+
+func NewFXService(repository dRepo.FXRateRepository, baseCurrency string) *FXService {
+	return &FXService{
+		repository:   repository,
+		baseCurrency: baseCurrency,
+		logger:       logger.Log,
+		rates:        make(map[string]domain.FXRate),
+	}
+}
+
+func (s *FXService) BaseCurrency() string {
+	return s.baseCurrency
+}
+
+func pairKey(base, quote string) string {
+	return base + "/" + quote
+}
+
+// Refresh recarrega as cotações do banco
+func (s *FXService) Refresh() error {
+	rates, err := s.repository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]domain.FXRate, len(rates))
+	for _, rate := range rates {
+		loaded[pairKey(rate.Base, rate.Quote)] = rate
+	}
+
+	s.mu.Lock()
+	s.rates = loaded
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *FXService) UpsertRates(ctx context.Context, rates []domain.FXRate, source string) error {
+	now := time.Now()
+	for i := range rates {
+		if err := rates[i].Validate(); err != nil {
+			return fmt.Errorf("cotação %s/%s: %w", rates[i].Base, rates[i].Quote, err)
+		}
+		if rates[i].Source == "" {
+			rates[i].Source = source
+		}
+		rates[i].UpdatedAt = now
+	}
+
+	if err := s.repository.Upsert(rates); err != nil {
+		return err
+	}
+	return s.Refresh()
+}
+
+// LoadFile importa cotações de um arquivo JSON no formato [{"base": "USD", "quote": "BRL", "rate": 5.1}]
+func (s *FXService) LoadFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo de cotações: %v", err)
+	}
+
+	var rates []domain.FXRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return fmt.Errorf("erro ao converter cotações: %v", err)
+	}
+
+	return s.UpsertRates(ctx, rates, "file")
+}
+
+func (s *FXService) Rates() []domain.FXRate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make([]domain.FXRate, 0, len(s.rates))
+	for _, rate := range s.rates {
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// rate procura a cotação direta, a inversa ou a cruzada pela moeda base
+func (s *FXService) rate(from, to string) (float64, time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if rate, at, ok := s.directOrInverse(from, to); ok {
+		return rate, at, true
+	}
+
+	if from != s.baseCurrency && to != s.baseCurrency {
+		fromBase, fromAt, okFrom := s.directOrInverse(from, s.baseCurrency)
+		baseTo, toAt, okTo := s.directOrInverse(s.baseCurrency, to)
+		if okFrom && okTo {
+			at := fromAt
+			if toAt.Before(at) {
+				at = toAt
+			}
+			return fromBase * baseTo, at, true
+		}
+	}
+
+	return 0, time.Time{}, false
+}
+
+func (s *FXService) directOrInverse(from, to string) (float64, time.Time, bool) {
+	if rate, ok := s.rates[pairKey(from, to)]; ok {
+		return rate.Rate, rate.UpdatedAt, true
+	}
+	if rate, ok := s.rates[pairKey(to, from)]; ok {
+		return 1 / rate.Rate, rate.UpdatedAt, true
+	}
+	return 0, time.Time{}, false
+}
+
+func (s *FXService) Convert(amount float64, from, to string) (Conversion, error) {
+	currency, ok := domain.Currencies.Lookup(to)
+	if !ok {
+		return Conversion{}, domain.ErrorInvalidCurrencyCode
+	}
+
+	if from == to {
+		return Conversion{From: from, To: to, Rate: 1, Amount: currency.Round(amount), RateAt: time.Now()}, nil
+	}
+
+	rate, at, ok := s.rate(from, to)
+	if !ok {
+		return Conversion{}, fmt.Errorf("%w: %s/%s", domain.ErrorFXRateUnavailable, from, to)
+	}
+
+	return Conversion{
+		From:   from,
+		To:     to,
+		Rate:   rate,
+		Amount: currency.Round(amount * rate),
+		RateAt: at,
+	}, nil
+}
+
+func (s *FXService) StartRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				s.logger.Error("erro ao recarregar cotações",
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
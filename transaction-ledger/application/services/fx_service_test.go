@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryFXRateRepository struct {
+	rates map[string]domain.FXRate
+}
+
+func (m *memoryFXRateRepository) Upsert(rates []domain.FXRate) error {
+	for _, rate := range rates {
+		m.rates[pairKey(rate.Base, rate.Quote)] = rate
+	}
+	return nil
+}
+
+func (m *memoryFXRateRepository) FindAll() ([]domain.FXRate, error) {
+	rates := make([]domain.FXRate, 0, len(m.rates))
+	for _, rate := range m.rates {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func TestFXService_Convert(t *testing.T) {
+	// Arrange
+	service := NewFXService(&memoryFXRateRepository{rates: map[string]domain.FXRate{}}, "BRL")
+	err := service.UpsertRates(context.Background(), []domain.FXRate{
+		{Base: "USD", Quote: "BRL", Rate: 5},
+		{Base: "EUR", Quote: "BRL", Rate: 6},
+		{Base: "JPY", Quote: "BRL", Rate: 0.04},
+	}, "test")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		amount float64
+		from   string
+		to     string
+		rate   float64
+		result float64
+	}{
+		{name: "Should use the direct rate", amount: 10, from: "USD", to: "BRL", rate: 5, result: 50},
+		{name: "Should use the inverse rate", amount: 50, from: "BRL", to: "USD", rate: 0.2, result: 10},
+		{name: "Should cross through the base currency", amount: 10, from: "EUR", to: "USD", rate: 1.2, result: 12},
+		{name: "Should round to the target minor units", amount: 10.01, from: "BRL", to: "JPY", rate: 25, result: 250},
+		{name: "Should keep the amount for the same currency", amount: 10, from: "BRL", to: "BRL", rate: 1, result: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			conversion, err := service.Convert(tt.amount, tt.from, tt.to)
+
+			// Assert
+			require.NoError(t, err)
+			assert.InDelta(t, tt.rate, conversion.Rate, 1e-9)
+			assert.InDelta(t, tt.result, conversion.Amount, 1e-9)
+		})
+	}
+
+	t.Run("Should fail without a rate for the pair", func(t *testing.T) {
+		// Act
+		_, err := service.Convert(10, "GBP", "USD")
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrorFXRateUnavailable)
+	})
+
+	t.Run("Should reject an invalid rate", func(t *testing.T) {
+		// Act
+		err := service.UpsertRates(context.Background(), []domain.FXRate{{Base: "USD", Quote: "BRL", Rate: 0}}, "test")
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrorInvalidFXRate)
+	})
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+)
+
+// importColumns são as colunas aceitas no CSV, com os mesmos nomes dos campos do JSON de criação.
+// Dados de cartão só são aceitos no JSONL.
+var importColumns = []string{"amount", "paymentMethod", "currencyCode", "description", "settlementCurrency", "pixKey", "installments", "capture"}
+
+var requiredImportColumns = []string{"amount", "paymentMethod", "currencyCode", "description"}
+
+// importRow é uma linha lida do arquivo; errs traz os erros de leitura, antes da validação da transação
+type importRow struct {
+	number  int
+	content []byte
+	request dto.TransactionRequestDto
+	errs    []string
+}
+
+type importReader interface {
+	// Next devolve io.EOF no fim do arquivo; qualquer outro erro interrompe a importação
+	Next() (*importRow, error)
+}
+
+func newImportReader(format string, r io.Reader) (importReader, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return newCSVImportReader(r)
+	case domain.ImportFormatJSONL:
+		return &jsonlImportReader{reader: bufio.NewReader(r)}, nil
+	}
+	return nil, domain.ErrorInvalidImportFormat
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		column := ""
+		for _, known := range importColumns {
+			if strings.EqualFold(name, known) {
+				column = known
+			}
+		}
+		if column == "" {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		columns[column] = i
+	}
+	for _, required := range requiredImportColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing csv column %q", required)
+		}
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) Next() (*importRow, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	r.row++
+
+	row := &importRow{number: r.row}
+	var parseErr *csv.ParseError
+	if err != nil {
+		if !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		// o leitor do csv continua na próxima linha depois de um erro de aspas ou de colunas
+		row.content = []byte(err.Error())
+		row.errs = []string{parseErr.Err.Error()}
+		return row, nil
+	}
+
+	row.content = []byte(strings.Join(record, "\x1f"))
+
+	field := func(name string) string {
+		index, ok := r.columns[name]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	request := dto.TransactionRequestDto{
+		PaymentMethod:      field("paymentMethod"),
+		CurrencyCode:       field("currencyCode"),
+		Description:        field("description"),
+		SettlementCurrency: field("settlementCurrency"),
+		PixKey:             field("pixKey"),
+	}
+	if request.Amount, err = strconv.ParseFloat(field("amount"), 64); err != nil {
+		row.errs = append(row.errs, "amount must be a number")
+	}
+	if raw := field("installments"); raw != "" {
+		if request.Installments, err = strconv.Atoi(raw); err != nil {
+			row.errs = append(row.errs, "installments must be an integer")
+		}
+	}
+	if raw := field("capture"); raw != "" {
+		capture, err := strconv.ParseBool(raw)
+		if err != nil {
+			row.errs = append(row.errs, "capture must be true or false")
+		}
+		request.Capture = &capture
+	}
+	row.request = request
+	return row, nil
+}
+
+type jsonlImportReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (r *jsonlImportReader) Next() (*importRow, error) {
+	for {
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(line) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		r.line++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			// linhas em branco contam na numeração, mas não são importadas
+			continue
+		}
+
+		row := &importRow{number: r.line, content: line}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.request); err != nil {
+			row.errs = []string{"invalid json: " + err.Error()}
+		}
+		return row, nil
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrImportNotFound = errors.New("import not found")
+	ErrUploadTooLarge = errors.New("uploaded file is too large")
+)
+
+// importNamespace gera os IDs determinísticos das transações importadas
+var importNamespace = uuid.MustParse("6f1c1a52-8d0e-4b53-9a57-2f1f7b1c0e11")
+
+type ImportConfig struct {
+	// ChunkSize é quantas linhas são gravadas por vez; o checkpoint avança a cada bloco
+	ChunkSize int
+	// StaleAfter é quanto tempo sem progresso faz um job RUNNING ser considerado órfão
+	StaleAfter time.Duration
+	// TempDir guarda os arquivos enviados pela API enquanto são importados
+	TempDir string
+	// MaxUploadBytes limita o tamanho do arquivo enviado pela API
+	MaxUploadBytes int64
+}
+
+// ImportService carrega arquivos CSV ou JSONL de transações. Cada linha vira uma transação com ID derivado
+// do merchant, do hash do conteúdo da linha e da ocorrência dela no arquivo, então reenviar o arquivo,
+// inteiro ou em parte, não duplica o que já foi gravado.
+type ImportService struct {
+	repository   dRepo.ImportRepository
+	transactions *TransactionService
+	config       ImportConfig
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewImportService(repository dRepo.ImportRepository, transactions *TransactionService, config ImportConfig) *ImportService {
+	if config.ChunkSize < 1 || config.ChunkSize > transactions.batchMaxItems {
+		config.ChunkSize = transactions.batchMaxItems
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 5 * time.Minute
+	}
+	if config.MaxUploadBytes < 1 {
+		config.MaxUploadBytes = 100 << 20
+	}
+	return &ImportService{
+		repository:   repository,
+		transactions: transactions,
+		config:       config,
+		logger:       logger.Log,
+		now:          time.Now,
+	}
+}
+
+// Stage copia o upload para um arquivo temporário calculando o hash do conteúdo
+func (s *ImportService) Stage(r io.Reader) (path, contentHash string, err error) {
+	return stageUpload(s.config.TempDir, "import-*", r, s.config.MaxUploadBytes)
+}
+
+// stageUpload copia o upload para um arquivo temporário calculando o SHA-256 no caminho. Para de ler
+// um byte depois de maxBytes e devolve ErrUploadTooLarge, sem deixar o arquivo parcial no disco.
+func stageUpload(dir, pattern string, r io.Reader, maxBytes int64) (path, contentHash string, err error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(r, maxBytes+1))
+	if err == nil && written > maxBytes {
+		err = ErrUploadTooLarge
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", "", err
+	}
+	return file.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HashFile calcula o hash do conteúdo de um arquivo local, usado pela linha de comando
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Submit cria o job do arquivo ou reaproveita o existente com o mesmo conteúdo. start indica que o job
+// foi reservado para este chamador, que deve então chamar Import; caso contrário ele já terminou ou
+// está em andamento em outro lugar.
+func (s *ImportService) Submit(ctx context.Context, fileName, format, contentHash string) (job *domain.ImportJob, start bool, err error) {
+	merchantID, tenantID := "", ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID, tenantID = principal.MerchantID, principal.TenantID
+	}
+
+	job, err = s.repository.FindByContentHash(merchantID, tenantID, contentHash)
+	if err != nil {
+		job, err = domain.NewImportJob(merchantID, tenantID, fileName, format, contentHash)
+		if err != nil {
+			return nil, false, err
+		}
+		job.Start(s.now())
+		if err := s.repository.Create(job); err != nil {
+			// outro envio do mesmo arquivo criou o job primeiro
+			if existing, findErr := s.repository.FindByContentHash(merchantID, tenantID, contentHash); findErr == nil {
+				return existing, false, nil
+			}
+			return nil, false, err
+		}
+		return job, true, nil
+	}
+
+	staleBefore := s.now().Add(-s.config.StaleAfter)
+	if !job.Resumable(staleBefore) {
+		return job, false, nil
+	}
+
+	job.Start(s.now())
+	claimed, err := s.repository.Claim(job, staleBefore)
+	if err != nil {
+		return nil, false, err
+	}
+	if !claimed {
+		current, err := s.repository.FindByID(job.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		return current, false, nil
+	}
+	return job, true, nil
+}
+
+// Import lê o arquivo do início, pula as linhas até o checkpoint e grava o restante em blocos.
+// Um erro de leitura ou de gravação interrompe o job como FAILED sem perder o checkpoint.
+func (s *ImportService) Import(ctx context.Context, job *domain.ImportJob, file io.Reader) error {
+	reader, err := newImportReader(job.Format, file)
+	if err != nil {
+		return s.fail(job, err)
+	}
+
+	occurrences := make(map[[sha256.Size]byte]int)
+	chunk := make([]*importRow, 0, s.config.ChunkSize)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return s.fail(job, err)
+		}
+
+		// a ocorrência diferencia linhas idênticas do mesmo arquivo, que são transações distintas
+		hash := sha256.Sum256(row.content)
+		occurrences[hash]++
+		if row.number <= job.Checkpoint {
+			continue
+		}
+		row.request.ID = importTransactionID(job, hash, occurrences[hash])
+
+		chunk = append(chunk, row)
+		if len(chunk) == s.config.ChunkSize {
+			if err := s.flush(ctx, job, chunk); err != nil {
+				return s.fail(job, err)
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if len(chunk) > 0 {
+		if err := s.flush(ctx, job, chunk); err != nil {
+			return s.fail(job, err)
+		}
+	}
+
+	job.Complete(s.now())
+	if err := s.repository.Update(job); err != nil {
+		return err
+	}
+
+	s.logger.Info("importação concluída",
+		zap.String("importId", job.ID),
+		zap.Int("imported", job.ImportedRows),
+		zap.Int("duplicates", job.DuplicateRows),
+		zap.Int("failed", job.FailedRows),
+	)
+	return nil
+}
+
+// flush grava um bloco: linhas já importadas são contadas como duplicadas e as demais passam pela
+// mesma criação em lote da API, em BEST_EFFORT, que valida cada uma com domain.NewTransaction
+func (s *ImportService) flush(ctx context.Context, job *domain.ImportJob, chunk []*importRow) error {
+	ids := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		if len(row.errs) == 0 {
+			ids = append(ids, row.request.ID)
+		}
+	}
+	existing, err := s.transactions.repository.FindExistingIDs(ids)
+	if err != nil {
+		return err
+	}
+	imported := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		imported[id] = true
+	}
+
+	var rowErrors []domain.ImportRowError
+	var pending []*importRow
+	duplicates := 0
+	for _, row := range chunk {
+		switch {
+		case len(row.errs) > 0:
+			rowErrors = append(rowErrors, domain.ImportRowError{ImportJobID: job.ID, Row: row.number, Errors: row.errs})
+		case imported[row.request.ID]:
+			duplicates++
+		default:
+			pending = append(pending, row)
+		}
+	}
+
+	created := 0
+	if len(pending) > 0 {
+		items := make([]dto.TransactionRequestDto, len(pending))
+		for i, row := range pending {
+			items[i] = row.request
+		}
+		response, err := s.transactions.CreateBatch(ctx, &dto.TransactionBatchRequestDto{Mode: dto.BatchModeBestEffort, Items: items})
+		if err != nil {
+			return err
+		}
+		for i, result := range response.Results {
+			if result.ID != "" {
+				created++
+				continue
+			}
+			rowErrors = append(rowErrors, domain.ImportRowError{ImportJobID: job.ID, Row: pending[i].number, Errors: result.Errors})
+		}
+	}
+
+	job.Advance(chunk[len(chunk)-1].number, created, duplicates, len(rowErrors))
+	return s.repository.SaveProgress(job, rowErrors)
+}
+
+func (s *ImportService) fail(job *domain.ImportJob, cause error) error {
+	s.logger.Error("erro na importação",
+		zap.Error(cause),
+		zap.String("importId", job.ID),
+		zap.Int("checkpoint", job.Checkpoint),
+	)
+
+	job.Fail(cause.Error(), s.now())
+	if err := s.repository.Update(job); err != nil {
+		s.logger.Error("erro ao gravar falha da importação",
+			zap.Error(err),
+			zap.String("importId", job.ID),
+		)
+	}
+	return cause
+}
+
+func (s *ImportService) FindByID(ctx context.Context, id string) (*domain.ImportJob, error) {
+	job, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, ErrImportNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(job.MerchantID, job.TenantID) {
+		return nil, ErrImportNotFound
+	}
+	return job, nil
+}
+
+func (s *ImportService) RowErrors(ctx context.Context, id string, page, pageSize int) (*dto.PaginatedImportRowErrorsResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	job, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrors, total, err := s.repository.FindRowErrors(job.ID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedImportRowErrorsResponseDto{
+		Data:       rowErrors,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// importTransactionID deriva o ID da linha do arquivo (o mesmo job ao retomar), do conteúdo da linha e de
+// quantas vezes esse conteúdo já apareceu; linhas iguais em outro arquivo são cobranças novas
+func importTransactionID(job *domain.ImportJob, hash [sha256.Size]byte, occurrence int) string {
+	name := job.MerchantID + "|" + job.TenantID + "|" + job.ContentHash + "|" + hex.EncodeToString(hash[:]) + "|" + strconv.Itoa(occurrence)
+	return uuid.NewSHA1(importNamespace, []byte(name)).String()
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryImportRepository struct {
+	mu        sync.Mutex
+	jobs      map[string]domain.ImportJob
+	rowErrors []domain.ImportRowError
+}
+
+func (m *memoryImportRepository) Create(job *domain.ImportJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *memoryImportRepository) FindByID(id string) (*domain.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &job, nil
+}
+
+func (m *memoryImportRepository) FindByContentHash(merchantID, tenantID, contentHash string) (*domain.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.jobs {
+		if job.MerchantID == merchantID && job.TenantID == tenantID && job.ContentHash == contentHash {
+			return &job, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *memoryImportRepository) Claim(job *domain.ImportJob, staleBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.jobs[job.ID]
+	if !current.Resumable(staleBefore) {
+		return false, nil
+	}
+	job.UpdatedAt = time.Now()
+	m.jobs[job.ID] = *job
+	return true, nil
+}
+
+func (m *memoryImportRepository) SaveProgress(job *domain.ImportJob, rowErrors []domain.ImportRowError) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rowErrors = append(m.rowErrors, rowErrors...)
+	job.UpdatedAt = time.Now()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *memoryImportRepository) Update(job *domain.ImportJob) error {
+	return m.SaveProgress(job, nil)
+}
+
+func (m *memoryImportRepository) FindRowErrors(jobID string, page, pageSize int) ([]domain.ImportRowError, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.rowErrors, int64(len(m.rowErrors)), nil
+}
+
+// memoryTransactionRepository implementa só o que a criação em lote usa
+type memoryTransactionRepository struct {
+	dRepo.TransactionRepository
+
+	transactions  map[string]*domain.Transaction
+	installments  []domain.Installment
+	reviews       []*domain.Review
+	cards         []*cardvault.VaultedCard
+	batchRequests map[string]*domain.BatchRequest
+	failOnBatch   int
+	batches       int
+	// concurrentUsed é o valor, por moeda, gravado por criações concorrentes que a checagem antecipada da
+	// quota não viu
+	concurrentUsed map[string]float64
+}
+
+func (m *memoryTransactionRepository) CreateBatch(creation *dRepo.TransactionCreation) error {
+	m.batches++
+	if m.batches == m.failOnBatch {
+		return errors.New("connection reset")
+	}
+	for _, reservation := range creation.Quotas {
+		used, _ := m.SumAmountByMerchant(reservation.MerchantID, reservation.CurrencyCode, reservation.Day)
+		used += m.concurrentUsed[reservation.CurrencyCode]
+		if used+reservation.Amount > reservation.Limit {
+			return &dRepo.QuotaReservationError{Reservation: reservation, Used: used}
+		}
+	}
+	if request := creation.BatchRequest; request != nil {
+		if _, ok := m.batchRequests[request.MerchantID+"|"+request.IdempotencyKey]; ok {
+			return domain.ErrorBatchRequestExists
+		}
+		if m.batchRequests == nil {
+			m.batchRequests = make(map[string]*domain.BatchRequest)
+		}
+		m.batchRequests[request.MerchantID+"|"+request.IdempotencyKey] = request
+	}
+	for _, transaction := range creation.Transactions {
+		m.transactions[transaction.ID] = transaction
+	}
+	m.installments = append(m.installments, creation.Installments...)
+	m.reviews = append(m.reviews, creation.Reviews...)
+	m.cards = append(m.cards, creation.Cards...)
+	return nil
+}
+
+func (m *memoryTransactionRepository) SumAmountByMerchant(merchantID, currencyCode string, since time.Time) (float64, error) {
+	var total float64
+	for _, transaction := range m.transactions {
+		if transaction.MerchantID == merchantID && transaction.CurrencyCode == currencyCode && transaction.ConsumesQuota() {
+			total += transaction.Amount
+		}
+	}
+	return total, nil
+}
+
+func (m *memoryTransactionRepository) FindBatchRequest(merchantID, idempotencyKey string) (*domain.BatchRequest, error) {
+	return m.batchRequests[merchantID+"|"+idempotencyKey], nil
+}
+
+func (m *memoryTransactionRepository) FindExistingIDs(ids []string) ([]string, error) {
+	var existing []string
+	for _, id := range ids {
+		if _, ok := m.transactions[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+func (m *memoryTransactionRepository) FindByIDs(ids []string) ([]domain.Transaction, error) {
+	var found []domain.Transaction
+	for _, id := range ids {
+		if transaction, ok := m.transactions[id]; ok {
+			found = append(found, domain.Transaction{ID: transaction.ID, Status: transaction.Status})
+		}
+	}
+	return found, nil
+}
+
+type discardKafkaBroker struct {
+	akafka.KafkaBroker
+}
+
+func (discardKafkaBroker) PublishBatch(messages []akafka.Message) error {
+	return nil
+}
+
+const importCSV = `amount,paymentMethod,currencyCode,description
+100,PIX,BRL,Fornecedor A
+abc,PIX,BRL,Valor inválido
+100,PIX,BRL,Fornecedor A
+-5,PIX,BRL,Valor negativo
+250.5,PIX,BRL,Fornecedor B
+`
+
+func newTestImportService(transactions *memoryTransactionRepository) (*ImportService, *memoryImportRepository) {
+	repository := &memoryImportRepository{jobs: make(map[string]domain.ImportJob)}
+	transactionService := NewTransactionService(discardKafkaBroker{}, transactions)
+	return NewImportService(repository, transactionService, ImportConfig{ChunkSize: 2}), repository
+}
+
+func TestImportService_Import(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: "merchant-1"})
+
+	t.Run("Should import valid rows and report row errors", func(t *testing.T) {
+		// Arrange
+		transactions := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		service, repository := newTestImportService(transactions)
+
+		job, start, err := service.Submit(ctx, "partner.csv", "", "hash-1")
+		require.NoError(t, err)
+		require.True(t, start)
+
+		// Act
+		err = service.Import(ctx, job, strings.NewReader(importCSV))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportCompleted, job.Status)
+		assert.Equal(t, domain.ImportFormatCSV, job.Format)
+		assert.Equal(t, 3, job.ImportedRows, "identical rows in the same file are distinct transactions")
+		assert.Equal(t, 2, job.FailedRows)
+		assert.Equal(t, 5, job.Checkpoint)
+		assert.Len(t, transactions.transactions, 3)
+		for _, transaction := range transactions.transactions {
+			assert.Equal(t, "merchant-1", transaction.MerchantID)
+		}
+
+		require.Len(t, repository.rowErrors, 2)
+		assert.Equal(t, 2, repository.rowErrors[0].Row)
+		assert.Equal(t, []string{"amount must be a number"}, repository.rowErrors[0].Errors)
+		assert.Equal(t, 4, repository.rowErrors[1].Row)
+
+		again, start, err := service.Submit(ctx, "partner-copy.csv", "", "hash-1")
+		require.NoError(t, err)
+		assert.False(t, start, "a completed file should not be imported again")
+		assert.Equal(t, job.ID, again.ID)
+	})
+
+	t.Run("Should resume from the checkpoint without duplicating rows", func(t *testing.T) {
+		// Arrange
+		transactions := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), failOnBatch: 2}
+		service, _ := newTestImportService(transactions)
+
+		job, _, err := service.Submit(ctx, "partner.csv", "", "hash-1")
+		require.NoError(t, err)
+		require.Error(t, service.Import(ctx, job, strings.NewReader(importCSV)))
+		require.Equal(t, domain.ImportFailed, job.Status)
+		require.Equal(t, 2, job.Checkpoint)
+
+		// Act
+		resumed, start, err := service.Submit(ctx, "partner.csv", "", "hash-1")
+		require.NoError(t, err)
+		require.True(t, start)
+		err = service.Import(ctx, resumed, strings.NewReader(importCSV))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportCompleted, resumed.Status)
+		assert.Equal(t, 3, resumed.ImportedRows)
+		assert.Equal(t, 2, resumed.FailedRows)
+		assert.Len(t, transactions.transactions, 3)
+	})
+
+	t.Run("Should import the same rows again when they come in another file", func(t *testing.T) {
+		// Arrange
+		transactions := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		service, _ := newTestImportService(transactions)
+
+		first, _, err := service.Submit(ctx, "partner.csv", "", "hash-1")
+		require.NoError(t, err)
+		require.NoError(t, service.Import(ctx, first, strings.NewReader(importCSV)))
+
+		second, start, err := service.Submit(ctx, "partner.jsonl", "", "hash-2")
+		require.NoError(t, err)
+		require.True(t, start)
+		jsonl := `{"amount":10,"paymentMethod":"PIX","currencyCode":"BRL","description":"Nova"}` + "\n\n" + `{"amount":1,"unknown":true}` + "\n"
+
+		// Act
+		err = service.Import(ctx, second, strings.NewReader(jsonl))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportFormatJSONL, second.Format)
+		assert.Equal(t, 1, second.ImportedRows)
+		assert.Equal(t, 1, second.FailedRows, "unknown fields should be rejected")
+		assert.Equal(t, 3, second.Checkpoint, "blank lines should still count in the numbering")
+
+		reupload, start, err := service.Submit(ctx, "partner-v2.csv", "", "hash-3")
+		require.NoError(t, err)
+		require.True(t, start)
+		require.NoError(t, service.Import(ctx, reupload, strings.NewReader(importCSV)))
+		assert.Zero(t, reupload.DuplicateRows)
+		assert.Equal(t, 3, reupload.ImportedRows)
+		assert.Len(t, transactions.transactions, 7)
+	})
+}
+
+func TestImportService_Stage(t *testing.T) {
+	transactionService := NewTransactionService(discardKafkaBroker{}, &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)})
+
+	t.Run("Should stage an upload up to the limit", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		service := NewImportService(&memoryImportRepository{jobs: make(map[string]domain.ImportJob)}, transactionService, ImportConfig{TempDir: dir, MaxUploadBytes: 10})
+
+		// Act
+		path, contentHash, err := service.Stage(strings.NewReader("0123456789"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, contentHash)
+		assert.FileExists(t, path)
+	})
+
+	t.Run("Should refuse an upload above the limit without leaving the partial file", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		service := NewImportService(&memoryImportRepository{jobs: make(map[string]domain.ImportJob)}, transactionService, ImportConfig{TempDir: dir, MaxUploadBytes: 10})
+
+		// Act
+		_, _, err := service.Stage(strings.NewReader("0123456789A"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+		entries, readErr := os.ReadDir(dir)
+		require.NoError(t, readErr)
+		assert.Empty(t, entries)
+	})
+}
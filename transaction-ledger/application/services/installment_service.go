@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+)
+
+var ErrInstallmentsAboveMaximum = errors.New("installments above merchant maximum")
+
+// InstallmentPolicy define o parcelamento aceito pelo merchant: até InterestFreeInstallments parcelas
+// sem juros e, acima disso, juros compostos de MonthlyInterestRate ao mês (ex.: 0.0199 = 1,99% a.m.)
+type InstallmentPolicy struct {
+	MaxInstallments          int     `json:"maxInstallments"`
+	InterestFreeInstallments int     `json:"interestFreeInstallments"`
+	MonthlyInterestRate      float64 `json:"monthlyInterestRate"`
+	MinInstallmentAmount     float64 `json:"minInstallmentAmount"`
+}
+
+// MerchantInstallmentPolicies mapeia merchant -> política de parcelamento
+type MerchantInstallmentPolicies map[string]InstallmentPolicy
+
+func LoadMerchantInstallmentPolicies(path string) (MerchantInstallmentPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de parcelamento: %v", err)
+	}
+
+	var policies MerchantInstallmentPolicies
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("erro ao converter políticas de parcelamento: %v", err)
+	}
+	return policies, nil
+}
+
+type InstallmentService struct {
+	repository    dRepo.InstallmentRepository
+	defaultPolicy InstallmentPolicy
+	policies      MerchantInstallmentPolicies
+	now           func() time.Time
+}
+
+func NewInstallmentService(repository dRepo.InstallmentRepository, defaultPolicy InstallmentPolicy, policies MerchantInstallmentPolicies) *InstallmentService {
+	return &InstallmentService{
+		repository:    repository,
+		defaultPolicy: defaultPolicy,
+		policies:      policies,
+		now:           time.Now,
+	}
+}
+
+func (s *InstallmentService) policyFor(merchantID string) InstallmentPolicy {
+	if policy, ok := s.policies[merchantID]; ok {
+		return policy
+	}
+	return s.defaultPolicy
+}
+
+// Plan valida o número de parcelas contra a política do merchant e calcula o cronograma
+func (s *InstallmentService) Plan(transaction *domain.Transaction) ([]domain.Installment, error) {
+	policy := s.policyFor(transaction.MerchantID)
+	if transaction.Installments > max(policy.MaxInstallments, 1) {
+		return nil, ErrInstallmentsAboveMaximum
+	}
+
+	plan := domain.InstallmentPlanInterestFree
+	rate := 0.0
+	if transaction.Installments > policy.InterestFreeInstallments && policy.MonthlyInterestRate > 0 {
+		plan = domain.InstallmentPlanWithInterest
+		rate = policy.MonthlyInterestRate
+	}
+
+	schedule := domain.NewInstallmentSchedule(transaction, rate, s.now())
+	for _, installment := range schedule {
+		if installment.Amount < policy.MinInstallmentAmount {
+			return nil, domain.ErrorInstallmentBelowMinimum
+		}
+	}
+
+	transaction.ApplyInstallmentPlan(plan, rate, schedule)
+	return schedule, nil
+}
+
+// Replan recalcula o cronograma sobre o valor capturado numa captura parcial, com a mesma taxa e os
+// vencimentos contados da criação. A política não é reaplicada: o processador já fez a captura. Devolve nil
+// quando o cronograma gravado continua valendo.
+func (s *InstallmentService) Replan(transaction *domain.Transaction) []domain.Installment {
+	if transaction.Installments <= 1 || transaction.SettledGross() == transaction.Amount {
+		return nil
+	}
+
+	schedule := domain.NewInstallmentSchedule(transaction, transaction.InstallmentInterestRate, transaction.CreatedAt)
+	transaction.ApplyInstallmentPlan(transaction.InstallmentPlan, transaction.InstallmentInterestRate, schedule)
+	return schedule
+}
+
+func (s *InstallmentService) FindByTransactionID(transactionID string) ([]domain.Installment, error) {
+	return s.repository.FindByTransactionID(transactionID)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallmentService_Plan(t *testing.T) {
+	policy := InstallmentPolicy{MaxInstallments: 12, InterestFreeInstallments: 3, MonthlyInterestRate: 0.0199, MinInstallmentAmount: 5}
+	service := NewInstallmentService(nil, policy, MerchantInstallmentPolicies{
+		"merchant-1": {MaxInstallments: 2},
+	})
+	service.now = func() time.Time { return time.Date(2026, time.January, 31, 15, 0, 0, 0, time.UTC) }
+
+	t.Run("Should split interest-free installments without losing cents", func(t *testing.T) {
+		// Arrange
+		transaction := &domain.Transaction{ID: "tx-1", Amount: 100, CurrencyCode: "BRL", Installments: 3}
+
+		// Act
+		schedule, err := service.Plan(transaction)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, schedule, 3)
+		assert.Equal(t, []float64{33.34, 33.33, 33.33}, []float64{schedule[0].Amount, schedule[1].Amount, schedule[2].Amount})
+		assert.Equal(t, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+		assert.Equal(t, time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC), schedule[1].DueDate)
+		assert.Equal(t, domain.InstallmentPlanInterestFree, transaction.InstallmentPlan)
+		assert.Equal(t, 100.0, transaction.InstallmentsTotal)
+	})
+
+	t.Run("Should apply compound interest above the interest-free limit", func(t *testing.T) {
+		// Arrange
+		transaction := &domain.Transaction{ID: "tx-2", Amount: 1000, CurrencyCode: "BRL", Installments: 10}
+
+		// Act
+		schedule, err := service.Plan(transaction)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 111.27, schedule[9].Amount)
+		assert.Equal(t, domain.InstallmentPlanWithInterest, transaction.InstallmentPlan)
+		assert.Equal(t, 1112.7, transaction.InstallmentsTotal)
+	})
+
+	t.Run("Should reject installments above the merchant maximum or below the minimum amount", func(t *testing.T) {
+		// Act
+		_, errAboveMax := service.Plan(&domain.Transaction{MerchantID: "merchant-1", Amount: 100, CurrencyCode: "BRL", Installments: 3})
+		_, errBelowMin := service.Plan(&domain.Transaction{Amount: 20, CurrencyCode: "BRL", Installments: 6})
+
+		// Assert
+		assert.ErrorIs(t, errAboveMax, ErrInstallmentsAboveMaximum)
+		assert.ErrorIs(t, errBelowMin, domain.ErrorInstallmentBelowMinimum)
+	})
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrIntakeQueueFull       = errors.New("intake queue is full")
+	ErrIntakeRequestNotFound = errors.New("intake request not found")
+)
+
+type IntakeConfig struct {
+	QueueSize int
+	Workers   int
+	BatchSize int
+	// BatchWait é quanto um worker espera para completar o lote depois do primeiro item
+	BatchWait time.Duration
+	// ResultTTL é por quanto tempo o status de requisições concluídas fica em memória
+	ResultTTL time.Duration
+}
+
+type intakeItem struct {
+	request   dto.TransactionRequestDto
+	principal *auth.Principal
+}
+
+// IntakeService recebe as criações assíncronas numa fila em memória e as grava e publica em lotes.
+// A fila não sobrevive a um restart; o status de itens ainda na fila só é conhecido pela réplica que os recebeu.
+type IntakeService struct {
+	transactions *TransactionService
+	queue        chan intakeItem
+	config       IntakeConfig
+	logger       *zap.Logger
+	now          func() time.Time
+	workers      sync.WaitGroup
+
+	// mu protege statuses e stopped; Submit enfileira sob o lock, então nenhum item entra depois do close
+	mu       sync.Mutex
+	stopped  bool
+	statuses map[string]*dto.IntakeStatusDto
+}
+
+func NewIntakeService(transactions *TransactionService, config IntakeConfig) *IntakeService {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.BatchSize < 1 || config.BatchSize > transactions.batchMaxItems {
+		config.BatchSize = transactions.batchMaxItems
+	}
+	return &IntakeService{
+		transactions: transactions,
+		queue:        make(chan intakeItem, config.QueueSize),
+		config:       config,
+		logger:       logger.Log,
+		now:          time.Now,
+		statuses:     make(map[string]*dto.IntakeStatusDto),
+	}
+}
+
+// Submit valida o formato da requisição e a enfileira; quota, risco e demais checagens rodam no worker
+func (s *IntakeService) Submit(ctx context.Context, request dto.TransactionRequestDto) (*dto.IntakeStatusDto, []error) {
+	if _, errs := domain.NewTransaction(request.Amount, request.PaymentMethod, request.CurrencyCode, request.Description, request.Options()...); len(errs) > 0 {
+		return nil, errs
+	}
+
+	request.ID = uuid.New().String()
+	queuedAt := s.now()
+	status := &dto.IntakeStatusDto{ID: request.ID, Status: dto.IntakeQueued, QueuedAt: &queuedAt}
+
+	item := intakeItem{request: request}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		item.principal = principal
+		status.MerchantID = principal.MerchantID
+		status.TenantID = principal.TenantID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, []error{ErrIntakeQueueFull}
+	}
+	select {
+	case s.queue <- item:
+	default:
+		return nil, []error{ErrIntakeQueueFull}
+	}
+	s.statuses[status.ID] = status
+
+	snapshot := *status
+	return &snapshot, nil
+}
+
+// Status consulta a fila desta réplica e, se o item não estiver nela, a transação já gravada com o mesmo ID
+func (s *IntakeService) Status(ctx context.Context, id string) (*dto.IntakeStatusDto, error) {
+	s.mu.Lock()
+	status, ok := s.statuses[id]
+	var snapshot dto.IntakeStatusDto
+	if ok {
+		snapshot = *status
+	}
+	s.mu.Unlock()
+
+	if ok {
+		if principal, found := auth.PrincipalFromContext(ctx); found && !principal.Owns(snapshot.MerchantID, snapshot.TenantID) {
+			return nil, ErrIntakeRequestNotFound
+		}
+		return &snapshot, nil
+	}
+
+	transaction, err := s.transactions.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrIntakeRequestNotFound
+	}
+	return &dto.IntakeStatusDto{
+		ID:                id,
+		Status:            dto.IntakeCompleted,
+		TransactionID:     transaction.ID,
+		TransactionStatus: transaction.Status,
+		CompletedAt:       &transaction.CreatedAt,
+	}, nil
+}
+
+// Start sobe os workers; ao cancelar o contexto a fila para de aceitar itens e os workers esvaziam o que restou
+func (s *IntakeService) Start(ctx context.Context) {
+	for range s.config.Workers {
+		s.workers.Add(1)
+		go s.work(ctx)
+	}
+
+	go s.purgeResults(ctx)
+}
+
+// close fecha a entrada; como Submit enfileira sob o mesmo lock, tudo que foi aceito já está na fila
+func (s *IntakeService) close() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+}
+
+// Wait bloqueia até os workers terminarem de esvaziar a fila
+func (s *IntakeService) Wait() {
+	s.workers.Wait()
+}
+
+func (s *IntakeService) QueueDepth() int {
+	return len(s.queue)
+}
+
+func (s *IntakeService) work(ctx context.Context) {
+	defer s.workers.Done()
+
+	for {
+		batch := s.collect(ctx)
+		if len(batch) == 0 {
+			return
+		}
+		s.process(batch)
+	}
+}
+
+// collect espera o primeiro item e junta outros até BatchSize ou BatchWait; depois do cancelamento só drena
+func (s *IntakeService) collect(ctx context.Context) []intakeItem {
+	batch := make([]intakeItem, 0, s.config.BatchSize)
+
+	select {
+	case item := <-s.queue:
+		batch = append(batch, item)
+	case <-ctx.Done():
+		s.close()
+		for len(batch) < s.config.BatchSize {
+			select {
+			case item := <-s.queue:
+				batch = append(batch, item)
+			default:
+				return batch
+			}
+		}
+		return batch
+	}
+
+	timer := time.NewTimer(s.config.BatchWait)
+	defer timer.Stop()
+
+	for len(batch) < s.config.BatchSize {
+		select {
+		case item := <-s.queue:
+			batch = append(batch, item)
+		case <-timer.C:
+			return batch
+		}
+	}
+	return batch
+}
+
+// intakeGroup são os itens gravados juntos, em nome do mesmo merchant, tenant e escopos
+type intakeGroup struct {
+	principal *auth.Principal
+	requests  []dto.TransactionRequestDto
+}
+
+// principalKey agrupa pelo conteúdo do principal: JWT e API key devolvem um ponteiro novo a cada requisição
+func principalKey(principal *auth.Principal) string {
+	if principal == nil {
+		return ""
+	}
+	scopes := slices.Clone(principal.Scopes)
+	slices.Sort(scopes)
+	return principal.MerchantID + "|" + principal.TenantID + "|" + strings.Join(scopes, " ")
+}
+
+// process agrupa o lote por principal, já que cada grupo é gravado em nome do seu merchant
+func (s *IntakeService) process(batch []intakeItem) {
+	groups := make(map[string]*intakeGroup)
+	var order []*intakeGroup
+	for _, item := range batch {
+		key := principalKey(item.principal)
+		group, ok := groups[key]
+		if !ok {
+			group = &intakeGroup{principal: item.principal}
+			groups[key] = group
+			order = append(order, group)
+		}
+		group.requests = append(group.requests, item.request)
+	}
+
+	for _, group := range order {
+		requests := group.requests
+		s.setStatus(requests, func(status *dto.IntakeStatusDto) {
+			status.Status = dto.IntakeProcessing
+		})
+
+		ctx := context.Background()
+		if group.principal != nil {
+			ctx = auth.WithPrincipal(ctx, group.principal)
+		}
+
+		response, err := s.transactions.CreateBatch(ctx, &dto.TransactionBatchRequestDto{Mode: dto.BatchModeBestEffort, Items: requests})
+		if err != nil {
+			s.logger.Error("erro ao gravar lote da fila de entrada",
+				zap.Error(err),
+				zap.Int("count", len(requests)),
+			)
+			s.resolveFailed(requests)
+			continue
+		}
+
+		completedAt := s.now()
+		s.mu.Lock()
+		for i, result := range response.Results {
+			status, ok := s.statuses[requests[i].ID]
+			if !ok {
+				continue
+			}
+			status.Code = result.Code
+			status.Errors = result.Errors
+			status.CompletedAt = &completedAt
+			if result.ID == "" {
+				status.Status = dto.IntakeRejected
+				continue
+			}
+			status.Status = dto.IntakeCompleted
+			status.TransactionID = result.ID
+			status.TransactionStatus = result.Status
+		}
+		s.mu.Unlock()
+	}
+}
+
+// resolveFailed confere no banco o que ficou gravado depois de um erro no lote: a falha pode ter
+// vindo depois do insert (na publicação, por exemplo). Só os itens sem transação pedem novo envio.
+func (s *IntakeService) resolveFailed(requests []dto.TransactionRequestDto) {
+	ids := make([]string, len(requests))
+	for i := range requests {
+		ids[i] = requests[i].ID
+	}
+
+	persisted := make(map[string]string)
+	transactions, err := s.transactions.repository.FindByIDs(ids)
+	if err != nil {
+		// sem como conferir, o item fica como falho; reenviar com o mesmo conteúdo pode duplicar
+		s.logger.Error("erro ao conferir transações do lote com falha",
+			zap.Error(err),
+			zap.Int("count", len(requests)),
+		)
+	}
+	for i := range transactions {
+		persisted[transactions[i].ID] = transactions[i].Status
+	}
+
+	completedAt := s.now()
+	s.setStatus(requests, func(status *dto.IntakeStatusDto) {
+		status.CompletedAt = &completedAt
+		if transactionStatus, ok := persisted[status.ID]; ok {
+			status.Status = dto.IntakeCompleted
+			status.TransactionID = status.ID
+			status.TransactionStatus = transactionStatus
+			return
+		}
+		status.Status = dto.IntakeFailed
+		if err != nil {
+			status.Errors = []string{"erro ao gravar transação; confira se ela foi criada antes de enviar novamente"}
+			return
+		}
+		status.Errors = []string{"erro ao gravar transação, envie novamente"}
+	})
+}
+
+func (s *IntakeService) setStatus(requests []dto.TransactionRequestDto, update func(*dto.IntakeStatusDto)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range requests {
+		if status, ok := s.statuses[requests[i].ID]; ok {
+			update(status)
+		}
+	}
+}
+
+func (s *IntakeService) purgeResults(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := s.now().Add(-s.config.ResultTTL)
+			s.mu.Lock()
+			for id, status := range s.statuses {
+				if status.CompletedAt != nil && status.CompletedAt.Before(cutoff) {
+					delete(s.statuses, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
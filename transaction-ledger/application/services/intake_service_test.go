@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingKafkaBroker recusa a publicação, depois de o lote já ter sido gravado
+type failingKafkaBroker struct {
+	akafka.KafkaBroker
+}
+
+func (failingKafkaBroker) Publish(topic string, message []byte) error {
+	return errors.New("kafka indisponível")
+}
+
+func (failingKafkaBroker) PublishBatch(messages []akafka.Message) error {
+	return errors.New("kafka indisponível")
+}
+
+func TestIntakeService(t *testing.T) {
+	request := dto.TransactionRequestDto{Amount: 10, PaymentMethod: "PIX", CurrencyCode: "BRL", Description: "async"}
+
+	submit := func(t *testing.T, intake *IntakeService, merchantID string) *dto.IntakeStatusDto {
+		// cada requisição autenticada traz um ponteiro novo do principal, como no JWT e na API key
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: merchantID, Scopes: []string{auth.ScopeTransactionsWrite}})
+		status, errs := intake.Submit(ctx, request)
+		require.Empty(t, errs)
+		return status
+	}
+
+	drain := func(intake *IntakeService) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		intake.Start(ctx)
+		intake.Wait()
+	}
+
+	t.Run("Should write the items of the same merchant in one batch", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		intake := NewIntakeService(NewTransactionService(discardKafkaBroker{}, repository), IntakeConfig{QueueSize: 10, Workers: 1, BatchSize: 10, BatchWait: time.Millisecond})
+		first := submit(t, intake, "merchant-1")
+		second := submit(t, intake, "merchant-1")
+		other := submit(t, intake, "merchant-2")
+
+		// Act
+		drain(intake)
+
+		// Assert
+		assert.Equal(t, 2, repository.batches)
+		for _, queued := range []*dto.IntakeStatusDto{first, second, other} {
+			status, err := intake.Status(context.Background(), queued.ID)
+			require.NoError(t, err)
+			assert.Equal(t, dto.IntakeCompleted, status.Status)
+		}
+	})
+
+	t.Run("Should refuse new items once the intake is closed for draining", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		intake := NewIntakeService(NewTransactionService(discardKafkaBroker{}, repository), IntakeConfig{QueueSize: 10, Workers: 1, BatchSize: 10})
+		drain(intake)
+
+		// Act
+		_, errs := intake.Submit(context.Background(), request)
+
+		// Assert
+		assert.Equal(t, []error{ErrIntakeQueueFull}, errs)
+		assert.Zero(t, intake.QueueDepth())
+	})
+
+	t.Run("Should report items persisted before a batch error as completed", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		intake := NewIntakeService(NewTransactionService(failingKafkaBroker{}, repository), IntakeConfig{QueueSize: 10, Workers: 1, BatchSize: 10})
+		queued := submit(t, intake, "merchant-1")
+
+		// Act
+		drain(intake)
+		status, err := intake.Status(context.Background(), queued.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, dto.IntakeCompleted, status.Status)
+		assert.Equal(t, queued.ID, status.TransactionID)
+		assert.Equal(t, domain.TransactionPending, status.TransactionStatus)
+	})
+}
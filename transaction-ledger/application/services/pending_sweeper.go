@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"go.uber.org/zap"
+)
+
+// PendingSweepConfig define quando uma transação aguardando o processamento é considerada presa e quantas
+// vezes é republicada
+type PendingSweepConfig struct {
+	After       time.Duration
+	MaxAttempts int
+	BatchSize   int
+}
+
+// PendingSweepResult resume uma passada do varredor
+type PendingSweepResult struct {
+	Redriven  int
+	Abandoned int
+}
+
+// WithAuditRepository habilita o registro das ações automáticas na trilha de auditoria
+func WithAuditRepository(audit dRepo.AuditRepository) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.audit = audit
+	}
+}
+
+func WithPendingSweep(config PendingSweepConfig) TransactionServiceOption {
+	return func(s *TransactionService) {
+		if config.BatchSize < 1 {
+			config.BatchSize = 100
+		}
+		s.pendingSweep = config
+	}
+}
+
+// SweepStuckPending republica as transações PENDING, CAPTURE_PENDING e VOID_PENDING sem retorno há mais de
+// After e, esgotadas as MaxAttempts republicações, encerra a etapa: a cobrança vira FAILED e a captura ou o
+// cancelamento volta a AUTHORIZED. Cada ação fica na trilha de auditoria.
+func (s *TransactionService) SweepStuckPending(ctx context.Context) (PendingSweepResult, error) {
+	var result PendingSweepResult
+	now := time.Now()
+
+	transactions, err := s.repository.FindStuckProcessing(now.Add(-s.pendingSweep.After), s.pendingSweep.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.RedriveAttempts >= s.pendingSweep.MaxAttempts {
+			if s.abandonProcessing(ctx, transaction, now) {
+				result.Abandoned++
+			}
+			continue
+		}
+		if s.redrive(ctx, transaction, now) {
+			result.Redriven++
+		}
+	}
+
+	return result, nil
+}
+
+// redrive grava a nova tentativa condicionada ao status lido antes de publicar; se o retorno do
+// processamento chegou nesse meio tempo, a transação é deixada como está
+func (s *TransactionService) redrive(ctx context.Context, transaction *domain.Transaction, now time.Time) bool {
+	stuckSince := transaction.UpdatedAt
+	transaction.Redrive()
+	if err := s.repository.UpdateIfStatus(transaction, transaction.Status); err != nil {
+		if !errors.Is(err, domain.ErrorTransactionStatusChanged) {
+			s.logger.Error("erro ao registrar republicação da transação",
+				zap.Error(err),
+				zap.String("id", transaction.ID),
+			)
+		}
+		return false
+	}
+
+	details := map[string]string{
+		"attempt":     strconv.Itoa(transaction.RedriveAttempts),
+		"maxAttempts": strconv.Itoa(s.pendingSweep.MaxAttempts),
+		"topic":       transaction.ProcessingTopic(),
+		"operation":   transaction.ProcessingOperation(),
+		"pendingFor":  now.Sub(stuckSince).Round(time.Second).String(),
+	}
+
+	jsonData, err := transaction.ToJson()
+	if err == nil {
+		err = s.kafkaBroker.Publish(transaction.ProcessingTopic(), jsonData)
+	}
+	if err != nil {
+		// a tentativa já foi contada; a próxima passada republica ou encerra a transação
+		s.logger.Error("erro ao republicar transação presa",
+			zap.Error(err),
+			zap.String("id", transaction.ID),
+		)
+		details["error"] = err.Error()
+	}
+
+	s.recordAudit(domain.NewTransactionAuditEntry(transaction.ID, domain.AuditActionProcessingRedriven, domain.AuditActorPendingSweeper, details))
+	s.logger.Warn("transação presa republicada",
+		zap.String("id", transaction.ID),
+		zap.String("status", transaction.Status),
+		zap.Int("attempt", transaction.RedriveAttempts),
+	)
+	return err == nil
+}
+
+func (s *TransactionService) abandonProcessing(ctx context.Context, transaction *domain.Transaction, now time.Time) bool {
+	stuckSince := transaction.UpdatedAt
+	stuckStatus := transaction.Status
+	operation := transaction.ProcessingOperation()
+	transaction.AbandonProcessing()
+	if err := s.repository.UpdateIfStatus(transaction, stuckStatus); err != nil {
+		if !errors.Is(err, domain.ErrorTransactionStatusChanged) {
+			s.logger.Error("erro ao encerrar transação presa",
+				zap.Error(err),
+				zap.String("id", transaction.ID),
+			)
+		}
+		return false
+	}
+
+	s.recordAudit(domain.NewTransactionAuditEntry(transaction.ID, domain.AuditActionProcessingAbandoned, domain.AuditActorPendingSweeper, map[string]string{
+		"attempts":   strconv.Itoa(transaction.RedriveAttempts),
+		"pendingFor": now.Sub(stuckSince).Round(time.Second).String(),
+		"operation":  operation,
+		"reason":     domain.ErrorMessageProcessingAbandoned,
+	}))
+	s.listeners.notify(ctx, transaction, stuckStatus)
+	if s.waiter != nil {
+		s.waiter.Notify(transaction.ID)
+	}
+
+	s.logger.Warn("etapa presa encerrada sem retorno do processamento",
+		zap.String("id", transaction.ID),
+		zap.String("operation", operation),
+		zap.String("status", transaction.Status),
+		zap.Int("attempts", transaction.RedriveAttempts),
+	)
+	return true
+}
+
+// recordAudit não interrompe a ação já gravada; uma falha fica no log com a entrada completa
+func (s *TransactionService) recordAudit(entry *domain.AuditEntry) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Create(entry); err != nil {
+		s.logger.Error("erro ao gravar trilha de auditoria",
+			zap.Error(err),
+			zap.Any("entry", entry),
+		)
+	}
+}
+
+// FindAuditTrail devolve a trilha de auditoria de uma transação visível para o principal
+func (s *TransactionService) FindAuditTrail(ctx context.Context, id string) ([]domain.AuditEntry, error) {
+	transaction, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.audit == nil {
+		return []domain.AuditEntry{}, nil
+	}
+	return s.audit.FindByEntity(domain.AuditEntityTransaction, transaction.ID)
+}
+
+func (s *TransactionService) StartPendingSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.SweepStuckPending(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar transações presas aguardando o processamento",
+					zap.Error(err),
+				)
+				continue
+			}
+			if result.Redriven > 0 || result.Abandoned > 0 {
+				s.logger.Info("varredura de transações presas",
+					zap.Int("redriven", result.Redriven),
+					zap.Int("abandoned", result.Abandoned),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckTransactionRepository implementa só o que os varredores e o retorno do processamento usam
+type stuckTransactionRepository struct {
+	dRepo.TransactionRepository
+
+	transactions  map[string]*domain.Transaction
+	installments  map[string][]domain.Installment
+	statusChanged bool
+}
+
+func (m *stuckTransactionRepository) FindStuckProcessing(before time.Time, limit int) ([]domain.Transaction, error) {
+	var stuck []domain.Transaction
+	for _, transaction := range m.transactions {
+		if transaction.AwaitingProcessing() && transaction.UpdatedAt.Before(before) {
+			stuck = append(stuck, *snapshotTransaction(transaction))
+		}
+	}
+	return stuck, nil
+}
+
+func (m *stuckTransactionRepository) FindExpired(now time.Time, limit int) ([]domain.Transaction, error) {
+	var expired []domain.Transaction
+	for _, transaction := range m.transactions {
+		if (transaction.Status == domain.TransactionPending || transaction.Status == domain.TransactionAuthorized) && transaction.ExpiresAt != nil && transaction.ExpiresAt.Before(now) {
+			expired = append(expired, *snapshotTransaction(transaction))
+		}
+	}
+	return expired, nil
+}
+
+func (m *stuckTransactionRepository) UpdateIfStatus(transaction *domain.Transaction, expectedStatus string) error {
+	if m.statusChanged || m.transactions[transaction.ID].Status != expectedStatus {
+		return domain.ErrorTransactionStatusChanged
+	}
+	m.transactions[transaction.ID] = snapshotTransaction(transaction)
+	m.transactions[transaction.ID].UpdatedAt = time.Now()
+	return nil
+}
+
+func (m *stuckTransactionRepository) UpdateIfStatusWithSchedule(transaction *domain.Transaction, expectedStatus string, schedule []domain.Installment) error {
+	if err := m.UpdateIfStatus(transaction, expectedStatus); err != nil {
+		return err
+	}
+	if m.installments == nil {
+		m.installments = make(map[string][]domain.Installment)
+	}
+	m.installments[transaction.ID] = schedule
+	return nil
+}
+
+// FindByID devolve uma cópia, como uma leitura do banco, para a transição não alterar o registro guardado
+func (m *stuckTransactionRepository) FindByID(id string) (*domain.Transaction, error) {
+	transaction, ok := m.transactions[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return snapshotTransaction(transaction), nil
+}
+
+func snapshotTransaction(transaction *domain.Transaction) *domain.Transaction {
+	return &domain.Transaction{ID: transaction.ID, MerchantID: transaction.MerchantID, TenantID: transaction.TenantID, Amount: transaction.Amount, CurrencyCode: transaction.CurrencyCode, CapturedAmount: transaction.CapturedAmount, AuthorizationOnly: transaction.AuthorizationOnly, Status: transaction.Status, PaymentMethod: transaction.PaymentMethod, Installments: transaction.Installments, InstallmentPlan: transaction.InstallmentPlan, InstallmentInterestRate: transaction.InstallmentInterestRate, InstallmentsTotal: transaction.InstallmentsTotal, ErrorMessage: transaction.ErrorMessage, RedriveAttempts: transaction.RedriveAttempts, CancelRequestedAt: transaction.CancelRequestedAt, ExpiresAt: transaction.ExpiresAt, CreatedAt: transaction.CreatedAt, UpdatedAt: transaction.UpdatedAt}
+}
+
+func (m *stuckTransactionRepository) Update(transaction *domain.Transaction) error {
+	m.transactions[transaction.ID] = transaction
+	return nil
+}
+
+type memoryAuditRepository struct {
+	entries []domain.AuditEntry
+}
+
+func (m *memoryAuditRepository) Create(entry *domain.AuditEntry) error {
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *memoryAuditRepository) FindByEntity(entityType, entityID string) ([]domain.AuditEntry, error) {
+	return m.entries, nil
+}
+
+type recordingKafkaBroker struct {
+	akafka.KafkaBroker
+
+	published []akafka.Message
+}
+
+func (r *recordingKafkaBroker) Publish(topic string, message []byte) error {
+	r.published = append(r.published, akafka.Message{Topic: topic, Value: message})
+	return nil
+}
+
+func (r *recordingKafkaBroker) PublishBatch(messages []akafka.Message) error {
+	r.published = append(r.published, messages...)
+	return nil
+}
+
+func TestTransactionService_SweepStuckPending(t *testing.T) {
+	ctx := context.Background()
+	config := PendingSweepConfig{After: time.Minute, MaxAttempts: 2}
+
+	newStuck := func(attempts int) *stuckTransactionRepository {
+		return &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"tx-1": {ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX, RedriveAttempts: attempts, UpdatedAt: time.Now().Add(-10 * time.Minute)},
+		}}
+	}
+
+	t.Run("Should re-publish with the attempt counter while below the limit", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(1)
+		broker := &recordingKafkaBroker{}
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(audit), WithPendingSweep(config))
+
+		// Act
+		result, err := service.SweepStuckPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, PendingSweepResult{Redriven: 1}, result)
+		assert.Equal(t, 2, repository.transactions["tx-1"].RedriveAttempts)
+
+		require.Len(t, broker.published, 1)
+		assert.Equal(t, domain.DefaultProcessingTopic, broker.published[0].Topic)
+		var message domain.Transaction
+		require.NoError(t, json.Unmarshal(broker.published[0].Value, &message))
+		assert.Equal(t, 2, message.RedriveAttempts)
+
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, domain.AuditActionProcessingRedriven, audit.entries[0].Action)
+		assert.Equal(t, "2", audit.entries[0].Details["attempt"])
+	})
+
+	t.Run("Should mark the transaction FAILED once the attempts are exhausted", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(2)
+		broker := &recordingKafkaBroker{}
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(audit), WithPendingSweep(config))
+
+		// Act
+		result, err := service.SweepStuckPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, PendingSweepResult{Abandoned: 1}, result)
+		assert.Equal(t, domain.TransactionFailed, repository.transactions["tx-1"].Status)
+		assert.Equal(t, domain.ErrorMessageProcessingAbandoned, repository.transactions["tx-1"].ErrorMessage)
+		assert.Empty(t, broker.published)
+
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, domain.AuditActionProcessingAbandoned, audit.entries[0].Action)
+		assert.Equal(t, domain.AuditActorPendingSweeper, audit.entries[0].Actor)
+	})
+
+	t.Run("Should re-publish a capture left in CAPTURE_PENDING", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(0)
+		repository.transactions["tx-1"].Status = domain.TransactionCapturePending
+		repository.transactions["tx-1"].PaymentMethod = domain.PaymentMethodCreditCard
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(&memoryAuditRepository{}), WithPendingSweep(config))
+
+		// Act
+		result, err := service.SweepStuckPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, PendingSweepResult{Redriven: 1}, result)
+		assert.Equal(t, domain.TransactionCapturePending, repository.transactions["tx-1"].Status)
+		require.Len(t, broker.published, 1)
+		var message domain.Transaction
+		require.NoError(t, json.Unmarshal(broker.published[0].Value, &message))
+		assert.Equal(t, domain.OperationCapture, message.ProcessingOperation())
+	})
+
+	t.Run("Should return an abandoned capture to AUTHORIZED instead of failing the charge", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(2)
+		repository.transactions["tx-1"].Status = domain.TransactionCapturePending
+		repository.transactions["tx-1"].PaymentMethod = domain.PaymentMethodCreditCard
+		repository.transactions["tx-1"].CapturedAmount = 40
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(&memoryAuditRepository{}), WithPendingSweep(config))
+
+		// Act
+		result, err := service.SweepStuckPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, PendingSweepResult{Abandoned: 1}, result)
+		assert.Equal(t, domain.TransactionAuthorized, repository.transactions["tx-1"].Status)
+		assert.Zero(t, repository.transactions["tx-1"].CapturedAmount)
+		assert.Equal(t, domain.ErrorMessageProcessingAbandoned, repository.transactions["tx-1"].ErrorMessage)
+		assert.Empty(t, broker.published)
+	})
+
+	t.Run("Should leave the transaction alone when the processing result arrives first", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(0)
+		repository.statusChanged = true
+		broker := &recordingKafkaBroker{}
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(audit), WithPendingSweep(config))
+
+		// Act
+		result, err := service.SweepStuckPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, result)
+		assert.Empty(t, broker.published)
+		assert.Empty(t, audit.entries)
+	})
+
+	t.Run("Should accept and audit a processing result that arrives after the sweeper gave up", func(t *testing.T) {
+		// Arrange
+		repository := newStuck(2)
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithAuditRepository(audit), WithPendingSweep(config))
+		_, err := service.SweepStuckPending(ctx)
+		require.NoError(t, err)
+
+		// Act
+		err = service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusProcessed})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionFinished, repository.transactions["tx-1"].Status)
+		assert.Empty(t, repository.transactions["tx-1"].ErrorMessage)
+		require.Len(t, audit.entries, 2)
+		assert.Equal(t, domain.AuditActionLateProcessingResult, audit.entries[1].Action)
+		assert.Equal(t, domain.TransactionFinished, audit.entries[1].Details["newStatus"])
+	})
+}
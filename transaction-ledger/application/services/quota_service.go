@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+)
+
+var ErrDailyQuotaExceeded = errors.New("daily amount quota exceeded")
+
+const ErrorCodeDailyQuotaExceeded = "DAILY_QUOTA_EXCEEDED"
+
+type QuotaExceededError struct {
+	MerchantID   string
+	CurrencyCode string
+	Limit        float64
+	Used         float64
+	ResetAt      time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: merchant %s used %.2f of %.2f %s", ErrDailyQuotaExceeded, e.MerchantID, e.Used, e.Limit, e.CurrencyCode)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrDailyQuotaExceeded
+}
+
+// MerchantQuotas mapeia merchant -> moeda -> valor máximo diário
+type MerchantQuotas map[string]map[string]float64
+
+func LoadMerchantQuotas(path string) (MerchantQuotas, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de quotas: %v", err)
+	}
+
+	var quotas MerchantQuotas
+	if err := json.Unmarshal(data, &quotas); err != nil {
+		return nil, fmt.Errorf("erro ao converter quotas: %v", err)
+	}
+	return quotas, nil
+}
+
+// QuotaService limita o valor total transacionado por merchant a cada dia (UTC)
+type QuotaService struct {
+	repository   dRepo.TransactionRepository
+	defaultLimit float64
+	quotas       MerchantQuotas
+	now          func() time.Time
+}
+
+func NewQuotaService(repository dRepo.TransactionRepository, defaultLimit float64, quotas MerchantQuotas) *QuotaService {
+	return &QuotaService{
+		repository:   repository,
+		defaultLimit: defaultLimit,
+		quotas:       quotas,
+		now:          time.Now,
+	}
+}
+
+func (q *QuotaService) limitFor(merchantID, currencyCode string) float64 {
+	if limits, ok := q.quotas[merchantID]; ok {
+		if limit, ok := limits[currencyCode]; ok {
+			return limit
+		}
+	}
+	return q.defaultLimit
+}
+
+func (q *QuotaService) Check(ctx context.Context, transaction *domain.Transaction) error {
+	return q.CheckWithPending(ctx, transaction, 0)
+}
+
+// CheckWithPending considera também o valor ainda não gravado de outras transações do mesmo lote.
+// É só uma recusa antecipada: quem garante a quota é a reserva gravada junto com as transações.
+func (q *QuotaService) CheckWithPending(ctx context.Context, transaction *domain.Transaction, pending float64) error {
+	if transaction.MerchantID == "" {
+		return nil
+	}
+
+	limit := q.limitFor(transaction.MerchantID, transaction.CurrencyCode)
+	if limit <= 0 {
+		return nil
+	}
+
+	startOfDay := q.startOfDay()
+	used, err := q.repository.SumAmountByMerchant(transaction.MerchantID, transaction.CurrencyCode, startOfDay)
+	if err != nil {
+		return err
+	}
+
+	if used+pending+transaction.Amount > limit {
+		return &QuotaExceededError{
+			MerchantID:   transaction.MerchantID,
+			CurrencyCode: transaction.CurrencyCode,
+			Limit:        limit,
+			Used:         used + pending,
+			ResetAt:      startOfDay.AddDate(0, 0, 1),
+		}
+	}
+
+	return nil
+}
+
+func (q *QuotaService) startOfDay() time.Time {
+	now := q.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Reservations soma, por merchant e moeda, o valor que as transações consomem da quota do dia, para o
+// repositório conferir na mesma transação do banco que grava as transações. Recusadas não consomem quota.
+func (q *QuotaService) Reservations(transactions []*domain.Transaction) []dRepo.QuotaReservation {
+	startOfDay := q.startOfDay()
+	var reservations []dRepo.QuotaReservation
+	index := make(map[string]int)
+	for _, transaction := range transactions {
+		if transaction.MerchantID == "" || !transaction.ConsumesQuota() {
+			continue
+		}
+		limit := q.limitFor(transaction.MerchantID, transaction.CurrencyCode)
+		if limit <= 0 {
+			continue
+		}
+
+		key := transaction.MerchantID + "|" + transaction.CurrencyCode
+		i, ok := index[key]
+		if !ok {
+			i = len(reservations)
+			index[key] = i
+			reservations = append(reservations, dRepo.QuotaReservation{
+				MerchantID:   transaction.MerchantID,
+				CurrencyCode: transaction.CurrencyCode,
+				Day:          startOfDay,
+				Limit:        limit,
+			})
+		}
+		reservations[i].Amount += transaction.Amount
+	}
+	return reservations
+}
+
+// Exceeded traduz a reserva recusada pelo repositório no erro de quota devolvido ao merchant
+func (q *QuotaService) Exceeded(err *dRepo.QuotaReservationError) *QuotaExceededError {
+	return &QuotaExceededError{
+		MerchantID:   err.Reservation.MerchantID,
+		CurrencyCode: err.Reservation.CurrencyCode,
+		Limit:        err.Reservation.Limit,
+		Used:         err.Used,
+		ResetAt:      err.Reservation.Day.AddDate(0, 0, 1),
+	}
+}
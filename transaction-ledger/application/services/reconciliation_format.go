@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+var errSettlementFileEmpty = errors.New("settlement file has no header")
+
+// ReconciliationFormat descreve o CSV de liquidação do processador. Columns mapeia transactionId, amount
+// e status para os nomes no cabeçalho; StatusMap traduz o status do processador para o do ledger.
+type ReconciliationFormat struct {
+	Delimiter        string            `json:"delimiter"`
+	Columns          map[string]string `json:"columns"`
+	DecimalSeparator string            `json:"decimalSeparator"`
+	AmountInCents    bool              `json:"amountInCents"`
+	AmountTolerance  float64           `json:"amountTolerance"`
+	StatusMap        map[string]string `json:"statusMap"`
+	// ExpectedStatuses são os status do ledger que devem constar no arquivo; os demais não geram MISSING_IN_PROCESSOR
+	ExpectedStatuses []string `json:"expectedStatuses"`
+}
+
+// DefaultReconciliationFormat é usado sem RECONCILIATION_FORMAT_FILE: CSV com vírgula, colunas com os
+// nomes do ledger e valores em reais
+func DefaultReconciliationFormat() ReconciliationFormat {
+	return ReconciliationFormat{
+		Delimiter:        ",",
+		Columns:          map[string]string{"transactionId": "transactionId", "amount": "amount", "status": "status"},
+		DecimalSeparator: ".",
+		ExpectedStatuses: []string{domain.TransactionFinished},
+	}
+}
+
+// LoadReconciliationFormat lê o formato do arquivo; campos ausentes ficam com os valores padrão
+func LoadReconciliationFormat(path string) (ReconciliationFormat, error) {
+	format := DefaultReconciliationFormat()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return format, fmt.Errorf("erro ao ler formato de conciliação: %v", err)
+	}
+	if err := json.Unmarshal(data, &format); err != nil {
+		return format, fmt.Errorf("erro ao converter formato de conciliação: %v", err)
+	}
+
+	// o status lido do arquivo é comparado em maiúsculas
+	statusMap := make(map[string]string, len(format.StatusMap))
+	for processorStatus, ledgerStatus := range format.StatusMap {
+		statusMap[strings.ToUpper(processorStatus)] = ledgerStatus
+	}
+	format.StatusMap = statusMap
+	return format, format.Validate()
+}
+
+func (f ReconciliationFormat) Validate() error {
+	if utf8.RuneCountInString(f.Delimiter) != 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	for _, column := range []string{"transactionId", "amount", "status"} {
+		if f.Columns[column] == "" {
+			return fmt.Errorf("column %s is not mapped", column)
+		}
+	}
+	if f.DecimalSeparator != "." && f.DecimalSeparator != "," {
+		return errors.New("decimalSeparator must be . or ,")
+	}
+	return nil
+}
+
+// settlementRecord é uma linha do arquivo do processador; Err indica que ela não pôde ser lida
+type settlementRecord struct {
+	Row           int
+	TransactionID string
+	Amount        float64
+	Status        string
+	Err           error
+}
+
+type settlementReader struct {
+	format  ReconciliationFormat
+	csv     *csv.Reader
+	indexes map[string]int
+	row     int
+}
+
+func newSettlementReader(format ReconciliationFormat, r io.Reader) (*settlementReader, error) {
+	delimiter, _ := utf8.DecodeRuneInString(format.Delimiter)
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errSettlementFileEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		positions[strings.TrimSpace(name)] = i
+	}
+	indexes := make(map[string]int, len(format.Columns))
+	for field, column := range format.Columns {
+		position, ok := positions[column]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found in settlement file header", column)
+		}
+		indexes[field] = position
+	}
+
+	return &settlementReader{format: format, csv: reader, indexes: indexes}, nil
+}
+
+// Next devolve o próximo registro; erros de uma linha vêm em Err, e só falhas de leitura encerram
+func (r *settlementReader) Next() (*settlementRecord, error) {
+	fields, err := r.csv.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	r.row++
+	record := &settlementRecord{Row: r.row}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		record.Err = parseErr.Err
+		return record, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string {
+		if index := r.indexes[name]; index < len(fields) {
+			return strings.TrimSpace(fields[index])
+		}
+		return ""
+	}
+
+	record.TransactionID = field("transactionId")
+	record.Status = strings.ToUpper(field("status"))
+	if record.TransactionID == "" {
+		record.Err = errors.New("transactionId is required")
+		return record, nil
+	}
+
+	amount, err := r.parseAmount(field("amount"))
+	if err != nil {
+		record.Err = fmt.Errorf("invalid amount: %v", err)
+		return record, nil
+	}
+	record.Amount = amount
+	return record, nil
+}
+
+func (r *settlementReader) parseAmount(value string) (float64, error) {
+	if r.format.DecimalSeparator == "," {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if r.format.AmountInCents {
+		amount = amount / 100
+	}
+	return math.Round(amount*10000) / 10000, nil
+}
+
+// ledgerStatus traduz o status do processador; status sem mapeamento são comparados como vieram
+func (f ReconciliationFormat) ledgerStatus(processorStatus string) string {
+	if status, ok := f.StatusMap[processorStatus]; ok {
+		return status
+	}
+	return processorStatus
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+var ErrReconciliationNotFound = errors.New("reconciliation not found")
+
+// Mensagens gravadas no relatório quando a conciliação para antes do fim
+const (
+	reconciliationShutdownMessage = "conciliação interrompida pelo encerramento do servidor, envie o arquivo novamente"
+	reconciliationStaleMessage    = "conciliação interrompida sem progresso, envie o arquivo novamente"
+)
+
+type ReconciliationConfig struct {
+	Format    ReconciliationFormat
+	ChunkSize int
+	TempDir   string
+	// MaxUploadBytes limita o tamanho do arquivo de liquidação enviado
+	MaxUploadBytes int64
+	// StaleAfter é quanto tempo sem progresso faz um relatório RUNNING ser considerado órfão
+	StaleAfter time.Duration
+}
+
+// ReconciliationService compara o arquivo de liquidação do processador com o ledger. Os IDs lidos do
+// arquivo ficam em memória até o fim, para achar as transações do período que não vieram nele.
+type ReconciliationService struct {
+	repository   dRepo.ReconciliationRepository
+	transactions dRepo.TransactionRepository
+	config       ReconciliationConfig
+	logger       *zap.Logger
+	now          func() time.Time
+
+	runs       sync.WaitGroup
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+}
+
+func NewReconciliationService(repository dRepo.ReconciliationRepository, transactions dRepo.TransactionRepository, config ReconciliationConfig) *ReconciliationService {
+	if config.ChunkSize < 1 {
+		config.ChunkSize = 500
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 10 * time.Minute
+	}
+	if config.MaxUploadBytes < 1 {
+		config.MaxUploadBytes = 100 << 20
+	}
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	return &ReconciliationService{
+		repository:   repository,
+		transactions: transactions,
+		config:       config,
+		logger:       logger.Log,
+		now:          time.Now,
+		runCtx:       runCtx,
+		cancelRuns:   cancelRuns,
+	}
+}
+
+// Stage copia o arquivo enviado para um arquivo temporário, já que a conciliação segue depois da resposta
+func (s *ReconciliationService) Stage(r io.Reader) (path, contentHash string, err error) {
+	return stageUpload(s.config.TempDir, "reconciliation-*", r, s.config.MaxUploadBytes)
+}
+
+// Submit grava o relatório em RUNNING, no escopo do merchant e do tenant do chamador
+func (s *ReconciliationService) Submit(ctx context.Context, fileName, contentHash string, from, to time.Time) (*domain.Reconciliation, error) {
+	merchantID, tenantID := "", ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID, tenantID = principal.MerchantID, principal.TenantID
+	}
+
+	reconciliation, err := domain.NewReconciliation(merchantID, tenantID, fileName, contentHash, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Create(reconciliation); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// Launch roda a conciliação em segundo plano a partir do arquivo preparado por Stage, que é removido no fim.
+// Shutdown interrompe e espera as conciliações lançadas.
+func (s *ReconciliationService) Launch(reconciliation *domain.Reconciliation, path string) {
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer os.Remove(path)
+
+		file, err := os.Open(path)
+		if err != nil {
+			s.logger.Error("erro ao abrir arquivo de liquidação",
+				zap.Error(err),
+				zap.String("reconciliationId", reconciliation.ID),
+			)
+			reconciliation.Fail(err.Error(), s.now())
+			s.repository.UpdateIfRunning(reconciliation)
+			return
+		}
+		defer file.Close()
+
+		// o erro já fica registrado no relatório
+		s.Run(s.runCtx, reconciliation, file)
+	}()
+}
+
+// Shutdown interrompe as conciliações em andamento, que são encerradas como FAILED, e espera todas pararem
+func (s *ReconciliationService) Shutdown() {
+	s.cancelRuns()
+	s.runs.Wait()
+}
+
+// Start encerra como FAILED, a cada intervalo, os relatórios RUNNING sem progresso há mais de StaleAfter:
+// a réplica que os rodava caiu, e o arquivo ficou no disco dela. A primeira checagem roda já na subida.
+func (s *ReconciliationService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.FailStale()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReconciliationService) FailStale() int64 {
+	now := s.now()
+	failed, err := s.repository.FailStale(now.Add(-s.config.StaleAfter), reconciliationStaleMessage, now)
+	if err != nil {
+		s.logger.Error("erro ao encerrar conciliações paradas",
+			zap.Error(err),
+		)
+		return 0
+	}
+	if failed > 0 {
+		s.logger.Warn("conciliações paradas encerradas como FAILED",
+			zap.Int64("count", failed),
+		)
+	}
+	return failed
+}
+
+// Run lê o arquivo em blocos de ChunkSize, compara cada bloco com o ledger e depois percorre as
+// transações do período procurando as que não vieram no arquivo. O resultado fica no relatório, e os
+// contadores parciais são gravados a cada bloco, o que mantém o relatório longe de FailStale.
+func (s *ReconciliationService) Run(ctx context.Context, reconciliation *domain.Reconciliation, r io.Reader) error {
+	err := s.run(ctx, reconciliation, r)
+	switch {
+	case errors.Is(err, domain.ErrorReconciliationNotRunning):
+		// já encerrado como FAILED por FailStale; o relatório fica como está
+		s.logger.Warn("conciliação abandonada: relatório já encerrado como parado",
+			zap.String("reconciliationId", reconciliation.ID),
+		)
+		return err
+	case err != nil:
+		s.logger.Error("erro na conciliação",
+			zap.Error(err),
+			zap.String("reconciliationId", reconciliation.ID),
+		)
+		message := err.Error()
+		if ctx.Err() != nil {
+			message = reconciliationShutdownMessage
+		}
+		reconciliation.Fail(message, s.now())
+	default:
+		reconciliation.Complete(s.now())
+		s.logger.Info("conciliação concluída",
+			zap.String("reconciliationId", reconciliation.ID),
+			zap.Int("processorRecords", reconciliation.ProcessorRecords),
+			zap.Int("matched", reconciliation.Matched),
+		)
+	}
+
+	if updateErr := s.repository.UpdateIfRunning(reconciliation); updateErr != nil {
+		return errors.Join(err, updateErr)
+	}
+	return err
+}
+
+func (s *ReconciliationService) run(ctx context.Context, reconciliation *domain.Reconciliation, r io.Reader) error {
+	reader, err := newSettlementReader(s.config.Format, r)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{})
+	chunk := make([]*settlementRecord, 0, s.config.ChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		reconciliation.ProcessorRecords++
+
+		if record.Err == nil {
+			if _, duplicate := seen[record.TransactionID]; duplicate {
+				record.Err = errors.New("transaction appears more than once in the settlement file")
+			} else {
+				seen[record.TransactionID] = struct{}{}
+			}
+		}
+
+		chunk = append(chunk, record)
+		if len(chunk) == s.config.ChunkSize {
+			if err := s.reconcileChunk(reconciliation, chunk); err != nil {
+				return err
+			}
+			if err := s.repository.UpdateIfRunning(reconciliation); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := s.reconcileChunk(reconciliation, chunk); err != nil {
+		return err
+	}
+
+	return s.findMissingInProcessor(ctx, reconciliation, seen)
+}
+
+func (s *ReconciliationService) reconcileChunk(reconciliation *domain.Reconciliation, chunk []*settlementRecord) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(chunk))
+	for _, record := range chunk {
+		if record.Err == nil {
+			ids = append(ids, record.TransactionID)
+		}
+	}
+	found, err := s.transactions.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	// transações de outro merchant ou tenant não são visíveis para quem pediu a conciliação
+	owner := auth.Principal{MerchantID: reconciliation.MerchantID, TenantID: reconciliation.TenantID}
+	ledger := make(map[string]*domain.Transaction, len(found))
+	for i := range found {
+		if !owner.Owns(found[i].MerchantID, found[i].TenantID) {
+			continue
+		}
+		ledger[found[i].ID] = &found[i]
+	}
+
+	var items []domain.ReconciliationItem
+	for _, record := range chunk {
+		item := s.compare(record, ledger[record.TransactionID])
+		reconciliation.Count(item.Result)
+		if item.Result != domain.ReconciliationMatched {
+			item.ReconciliationID = reconciliation.ID
+			items = append(items, item)
+		}
+	}
+	return s.repository.SaveItems(items)
+}
+
+// compare classifica um registro do arquivo; valor divergente prevalece sobre status divergente
+func (s *ReconciliationService) compare(record *settlementRecord, transaction *domain.Transaction) domain.ReconciliationItem {
+	item := domain.ReconciliationItem{
+		Row:             record.Row,
+		TransactionID:   record.TransactionID,
+		ProcessorStatus: record.Status,
+		CreatedAt:       s.now(),
+	}
+	if record.Err != nil {
+		item.Result = domain.ReconciliationInvalidRecord
+		item.Message = record.Err.Error()
+		return item
+	}
+
+	processorAmount := record.Amount
+	item.ProcessorAmount = &processorAmount
+	if transaction == nil {
+		item.Result = domain.ReconciliationMissingInLedger
+		return item
+	}
+
+	ledgerAmount := transaction.SettledGross()
+	item.LedgerAmount = &ledgerAmount
+	item.LedgerStatus = transaction.Status
+
+	expectedStatus := s.config.Format.ledgerStatus(record.Status)
+	switch {
+	case math.Abs(ledgerAmount-processorAmount) > s.config.Format.AmountTolerance+1e-9:
+		item.Result = domain.ReconciliationAmountMismatch
+	case expectedStatus != transaction.Status:
+		item.Result = domain.ReconciliationStatusMismatch
+		item.Message = "processor status maps to " + expectedStatus
+	default:
+		item.Result = domain.ReconciliationMatched
+	}
+	return item
+}
+
+func (s *ReconciliationService) findMissingInProcessor(ctx context.Context, reconciliation *domain.Reconciliation, seen map[string]struct{}) error {
+	filter := dRepo.TransactionFilter{
+		MerchantID:  reconciliation.MerchantID,
+		TenantID:    reconciliation.TenantID,
+		Statuses:    s.config.Format.ExpectedStatuses,
+		CreatedFrom: reconciliation.From,
+		CreatedTo:   reconciliation.To.AddDate(0, 0, 1),
+	}
+
+	var items []domain.ReconciliationItem
+	err := s.transactions.Stream(filter, func(transaction *domain.Transaction) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := seen[transaction.ID]; ok {
+			return nil
+		}
+
+		ledgerAmount := transaction.SettledGross()
+		items = append(items, domain.ReconciliationItem{
+			ReconciliationID: reconciliation.ID,
+			TransactionID:    transaction.ID,
+			Result:           domain.ReconciliationMissingInProcessor,
+			LedgerAmount:     &ledgerAmount,
+			LedgerStatus:     transaction.Status,
+			CreatedAt:        s.now(),
+		})
+		reconciliation.Count(domain.ReconciliationMissingInProcessor)
+
+		if len(items) < s.config.ChunkSize {
+			return nil
+		}
+		if err := s.repository.SaveItems(items); err != nil {
+			return err
+		}
+		items = items[:0]
+		return s.repository.UpdateIfRunning(reconciliation)
+	})
+	if err != nil {
+		return err
+	}
+	return s.repository.SaveItems(items)
+}
+
+func (s *ReconciliationService) FindByID(ctx context.Context, id string) (*domain.Reconciliation, error) {
+	reconciliation, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, ErrReconciliationNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(reconciliation.MerchantID, reconciliation.TenantID) {
+		return nil, ErrReconciliationNotFound
+	}
+	return reconciliation, nil
+}
+
+// Report devolve o relatório com uma página das divergências, opcionalmente de uma única classificação
+func (s *ReconciliationService) Report(ctx context.Context, id, result string, page, pageSize int) (*dto.ReconciliationReportDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	reconciliation, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.repository.FindItems(reconciliation.ID, result, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReconciliationReportDto{
+		Reconciliation: reconciliation,
+		Items: dto.PaginatedReconciliationItemsResponseDto{
+			Data:       items,
+			Page:       page,
+			PageSize:   pageSize,
+			TotalItems: total,
+			TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+		},
+	}, nil
+}
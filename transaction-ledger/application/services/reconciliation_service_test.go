@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryReconciliationRepository struct {
+	reconciliations map[string]domain.Reconciliation
+	items           []domain.ReconciliationItem
+}
+
+func (m *memoryReconciliationRepository) Create(reconciliation *domain.Reconciliation) error {
+	reconciliation.UpdatedAt = time.Now()
+	m.reconciliations[reconciliation.ID] = *reconciliation
+	return nil
+}
+
+func (m *memoryReconciliationRepository) FindByID(id string) (*domain.Reconciliation, error) {
+	reconciliation, ok := m.reconciliations[id]
+	if !ok {
+		return nil, ErrReconciliationNotFound
+	}
+	return &reconciliation, nil
+}
+
+func (m *memoryReconciliationRepository) UpdateIfRunning(reconciliation *domain.Reconciliation) error {
+	if m.reconciliations[reconciliation.ID].Status != domain.ReconciliationRunning {
+		return domain.ErrorReconciliationNotRunning
+	}
+	return m.Create(reconciliation)
+}
+
+func (m *memoryReconciliationRepository) FailStale(before time.Time, message string, now time.Time) (int64, error) {
+	var failed int64
+	for id, reconciliation := range m.reconciliations {
+		if reconciliation.Status == domain.ReconciliationRunning && reconciliation.UpdatedAt.Before(before) {
+			reconciliation.Fail(message, now)
+			m.reconciliations[id] = reconciliation
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (m *memoryReconciliationRepository) SaveItems(items []domain.ReconciliationItem) error {
+	m.items = append(m.items, items...)
+	return nil
+}
+
+func (m *memoryReconciliationRepository) FindItems(reconciliationID, result string, page, pageSize int) ([]domain.ReconciliationItem, int64, error) {
+	return m.items, int64(len(m.items)), nil
+}
+
+// ledgerTransactionRepository implementa só a busca por IDs e o cursor usados na conciliação
+type ledgerTransactionRepository struct {
+	dRepo.TransactionRepository
+
+	transactions []domain.Transaction
+	filter       dRepo.TransactionFilter
+}
+
+func (m *ledgerTransactionRepository) FindByIDs(ids []string) ([]domain.Transaction, error) {
+	var found []domain.Transaction
+	for i := range m.transactions {
+		transaction := &m.transactions[i]
+		for _, id := range ids {
+			if transaction.ID == id {
+				found = append(found, domain.Transaction{ID: transaction.ID, MerchantID: transaction.MerchantID, Amount: transaction.Amount, Status: transaction.Status})
+			}
+		}
+	}
+	return found, nil
+}
+
+func (m *ledgerTransactionRepository) Stream(filter dRepo.TransactionFilter, fn func(transaction *domain.Transaction) error) error {
+	m.filter = filter
+	for i := range m.transactions {
+		if len(filter.Statuses) > 0 && m.transactions[i].Status != filter.Statuses[0] {
+			continue
+		}
+		if err := fn(&m.transactions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const settlementCSV = `merchant_reference;gross_amount;status
+tx-matched;100,00;SETTLED
+tx-amount;1.250,50;SETTLED
+tx-status;30,00;SETTLED
+tx-unknown;10,00;SETTLED
+tx-matched;100,00;SETTLED
+tx-invalid;abc;SETTLED
+`
+
+func TestReconciliationService_Run(t *testing.T) {
+	ctx := context.Background()
+	format := DefaultReconciliationFormat()
+	format.Delimiter = ";"
+	format.DecimalSeparator = ","
+	format.Columns = map[string]string{"transactionId": "merchant_reference", "amount": "gross_amount", "status": "status"}
+	format.StatusMap = map[string]string{"SETTLED": domain.TransactionFinished}
+
+	t.Run("Should classify every record and the ledger transactions missing from the file", func(t *testing.T) {
+		// Arrange
+		transactions := &ledgerTransactionRepository{transactions: []domain.Transaction{
+			{ID: "tx-matched", Amount: 100, Status: domain.TransactionFinished},
+			{ID: "tx-amount", Amount: 1250, Status: domain.TransactionFinished},
+			{ID: "tx-status", Amount: 30, Status: domain.TransactionFailed},
+			{ID: "tx-missing", Amount: 75, Status: domain.TransactionFinished},
+			{ID: "tx-pending", Amount: 20, Status: domain.TransactionPending},
+		}}
+		repository := &memoryReconciliationRepository{reconciliations: make(map[string]domain.Reconciliation)}
+		service := NewReconciliationService(repository, transactions, ReconciliationConfig{Format: format, ChunkSize: 2})
+
+		day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		reconciliation, err := service.Submit(ctx, "settlement.csv", "hash-1", day, day)
+		require.NoError(t, err)
+
+		// Act
+		err = service.Run(ctx, reconciliation, strings.NewReader(settlementCSV))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReconciliationCompleted, reconciliation.Status)
+		assert.Equal(t, 6, reconciliation.ProcessorRecords)
+		assert.Equal(t, 1, reconciliation.Matched)
+		assert.Equal(t, 1, reconciliation.AmountMismatches)
+		assert.Equal(t, 1, reconciliation.StatusMismatches)
+		assert.Equal(t, 1, reconciliation.MissingInLedger)
+		assert.Equal(t, 2, reconciliation.InvalidRecords, "duplicated and unparseable rows")
+		assert.Equal(t, 1, reconciliation.MissingInProcessor, "only expected statuses must be in the file")
+		assert.Equal(t, day.AddDate(0, 0, 1), transactions.filter.CreatedTo)
+
+		results := make(map[string]domain.ReconciliationItem)
+		for _, item := range repository.items {
+			results[item.TransactionID+"/"+item.Result] = item
+		}
+		assert.Len(t, repository.items, 6, "matched records are only counted")
+		assert.Equal(t, 1250.5, *results["tx-amount/"+domain.ReconciliationAmountMismatch].ProcessorAmount)
+		assert.Equal(t, domain.TransactionFailed, results["tx-status/"+domain.ReconciliationStatusMismatch].LedgerStatus)
+		assert.Equal(t, 5, results["tx-matched/"+domain.ReconciliationInvalidRecord].Row)
+		assert.Contains(t, results, "tx-missing/"+domain.ReconciliationMissingInProcessor)
+	})
+
+	t.Run("Should fail the report when a mapped column is missing from the header", func(t *testing.T) {
+		// Arrange
+		repository := &memoryReconciliationRepository{reconciliations: make(map[string]domain.Reconciliation)}
+		service := NewReconciliationService(repository, &ledgerTransactionRepository{}, ReconciliationConfig{Format: format})
+
+		day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		reconciliation, err := service.Submit(ctx, "settlement.csv", "hash-2", day, day)
+		require.NoError(t, err)
+
+		// Act
+		err = service.Run(ctx, reconciliation, strings.NewReader("id;amount;status\n"))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, domain.ReconciliationFailed, repository.reconciliations[reconciliation.ID].Status)
+	})
+	t.Run("Should fail the report when the run is interrupted by a shutdown", func(t *testing.T) {
+		// Arrange
+		repository := &memoryReconciliationRepository{reconciliations: make(map[string]domain.Reconciliation)}
+		service := NewReconciliationService(repository, &ledgerTransactionRepository{}, ReconciliationConfig{Format: format})
+
+		day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		reconciliation, err := service.Submit(ctx, "settlement.csv", "hash-3", day, day)
+		require.NoError(t, err)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		// Act
+		err = service.Run(cancelled, reconciliation, strings.NewReader(settlementCSV))
+
+		// Assert
+		assert.Error(t, err)
+		stored := repository.reconciliations[reconciliation.ID]
+		assert.Equal(t, domain.ReconciliationFailed, stored.Status)
+		assert.Equal(t, reconciliationShutdownMessage, stored.Error)
+	})
+
+	t.Run("Should stop a run whose report was failed as stale meanwhile", func(t *testing.T) {
+		// Arrange
+		repository := &memoryReconciliationRepository{reconciliations: make(map[string]domain.Reconciliation)}
+		service := NewReconciliationService(repository, &ledgerTransactionRepository{}, ReconciliationConfig{Format: format, ChunkSize: 2, StaleAfter: time.Minute})
+
+		day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		reconciliation, err := service.Submit(ctx, "settlement.csv", "hash-4", day, day)
+		require.NoError(t, err)
+		service.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		require.EqualValues(t, 1, service.FailStale())
+
+		// Act
+		err = service.Run(ctx, reconciliation, strings.NewReader(settlementCSV))
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrorReconciliationNotRunning)
+		stored := repository.reconciliations[reconciliation.ID]
+		assert.Equal(t, domain.ReconciliationFailed, stored.Status)
+		assert.Equal(t, reconciliationStaleMessage, stored.Error)
+		assert.Zero(t, stored.ProcessorRecords)
+	})
+}
+
+func TestReconciliationService_FailStale(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	repository := &memoryReconciliationRepository{reconciliations: map[string]domain.Reconciliation{
+		"orphaned": {ID: "orphaned", Status: domain.ReconciliationRunning, UpdatedAt: now.Add(-time.Hour)},
+		"running":  {ID: "running", Status: domain.ReconciliationRunning, UpdatedAt: now.Add(-time.Minute)},
+		"done":     {ID: "done", Status: domain.ReconciliationCompleted, UpdatedAt: now.Add(-time.Hour)},
+	}}
+	service := NewReconciliationService(repository, &ledgerTransactionRepository{}, ReconciliationConfig{StaleAfter: 10 * time.Minute})
+
+	// Act
+	failed := service.FailStale()
+
+	// Assert
+	assert.EqualValues(t, 1, failed)
+	assert.Equal(t, domain.ReconciliationFailed, repository.reconciliations["orphaned"].Status)
+	assert.Equal(t, reconciliationStaleMessage, repository.reconciliations["orphaned"].Error)
+	assert.Equal(t, domain.ReconciliationRunning, repository.reconciliations["running"].Status)
+	assert.Equal(t, domain.ReconciliationCompleted, repository.reconciliations["done"].Status)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+type ReviewService struct {
+	kafkaBroker           akafka.KafkaBroker
+	logger                *zap.Logger
+	repository            dRepo.ReviewRepository
+	transactionRepository dRepo.TransactionRepository
+	sla                   time.Duration
+	listeners             statusListeners
+}
+
+func NewReviewService(kafkaBroker akafka.KafkaBroker, repository dRepo.ReviewRepository, transactionRepository dRepo.TransactionRepository, sla time.Duration, listeners ...StatusListener) *ReviewService {
+	return &ReviewService{
+		kafkaBroker:           kafkaBroker,
+		logger:                logger.Log,
+		repository:            repository,
+		transactionRepository: transactionRepository,
+		sla:                   sla,
+		listeners:             listeners,
+	}
+}
+
+// Prepare monta a revisão de uma transação sinalizada pelo motor de risco, sem gravá-la: ela é gravada
+// junto com a transação, na mesma transação do banco
+func (s *ReviewService) Prepare(transaction *domain.Transaction) *domain.Review {
+	return domain.NewReview(transaction, s.sla)
+}
+
+// Opened registra no log a revisão gravada junto com a transação
+func (s *ReviewService) Opened(review *domain.Review) {
+	s.logger.Info("transação enviada para revisão manual",
+		zap.String("reviewId", review.ID),
+		zap.String("transactionId", review.TransactionID),
+		zap.Time("dueAt", review.DueAt),
+	)
+}
+
+func (s *ReviewService) FindByID(ctx context.Context, id string) (*domain.Review, error) {
+	review, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(review.MerchantID, review.TenantID) {
+		return nil, ErrTransactionNotFound
+	}
+	return review, nil
+}
+
+func (s *ReviewService) FindPaginated(ctx context.Context, status string, page, pageSize int) (*dto.PaginatedReviewsResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	filter := dRepo.ReviewFilter{Status: status}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	reviews, total, err := s.repository.FindPaginated(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedReviewsResponseDto{
+		Data:       reviews,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// Approve libera a transação e a publica para processamento
+func (s *ReviewService) Approve(ctx context.Context, id, reviewer, notes string) (*domain.Review, error) {
+	review, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := review.Approve(reviewer, notes); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.transactionRepository.FindByID(review.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := transaction.Status
+	transaction.ReviewApproved()
+	if err := s.repository.Decide(review, transaction); err != nil {
+		return nil, err
+	}
+	s.listeners.notify(ctx, transaction, previousStatus)
+
+	jsonData, err := transaction.ToJson()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.kafkaBroker.Publish(transaction.ProcessingTopic(), jsonData); err != nil {
+		s.logger.Error("erro ao publicar transação aprovada",
+			zap.Error(err),
+			zap.String("transactionId", transaction.ID),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("revisão aprovada",
+		zap.String("reviewId", review.ID),
+		zap.String("reviewer", reviewer),
+	)
+	return review, nil
+}
+
+func (s *ReviewService) Reject(ctx context.Context, id, reviewer, notes string) (*domain.Review, error) {
+	review, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := review.Reject(reviewer, notes); err != nil {
+		return nil, err
+	}
+	if err := s.fail(ctx, review, "transação reprovada na revisão manual"); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("revisão reprovada",
+		zap.String("reviewId", review.ID),
+		zap.String("reviewer", reviewer),
+	)
+	return review, nil
+}
+
+// fail grava a recusa da revisão e a falha da transação juntas
+func (s *ReviewService) fail(ctx context.Context, review *domain.Review, errorMessage string) error {
+	transaction, err := s.transactionRepository.FindByID(review.TransactionID)
+	if err != nil {
+		return err
+	}
+	previousStatus := transaction.Status
+	transaction.ErrorProcessingTransaction(errorMessage)
+	if err := s.repository.Decide(review, transaction); err != nil {
+		return err
+	}
+	s.listeners.notify(ctx, transaction, previousStatus)
+	return nil
+}
+
+// ExpireOverdue recusa automaticamente as revisões que passaram do SLA
+func (s *ReviewService) ExpireOverdue(ctx context.Context) (int, error) {
+	reviews, err := s.repository.FindOverdue(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range reviews {
+		review := &reviews[i]
+		if err := review.Expire(); err != nil {
+			continue
+		}
+		if err := s.fail(ctx, review, "revisão manual expirada (SLA)"); err != nil {
+			s.logger.Error("erro ao expirar revisão",
+				zap.Error(err),
+				zap.String("reviewId", review.ID),
+			)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (s *ReviewService) StartSLAWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireOverdue(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar revisões expiradas",
+					zap.Error(err),
+				)
+				continue
+			}
+			if expired > 0 {
+				s.logger.Info("revisões expiradas pelo SLA",
+					zap.Int("count", expired),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryReviewRepository grava a decisão e a transação juntas, como a transação do banco em Decide
+type memoryReviewRepository struct {
+	dRepo.ReviewRepository
+
+	reviews      map[string]*domain.Review
+	transactions *stuckTransactionRepository
+}
+
+func (m *memoryReviewRepository) FindByID(id string) (*domain.Review, error) {
+	review, ok := m.reviews[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *review
+	return &copied, nil
+}
+
+func (m *memoryReviewRepository) Decide(review *domain.Review, transaction *domain.Transaction) error {
+	if m.reviews[review.ID].Status != domain.ReviewPending {
+		return domain.ErrorReviewAlreadyDecided
+	}
+	if m.transactions.transactions[transaction.ID].Status != domain.TransactionReview {
+		return domain.ErrorTransactionStatusChanged
+	}
+	copied := *review
+	m.reviews[review.ID] = &copied
+	return m.transactions.UpdateIfStatus(transaction, domain.TransactionReview)
+}
+
+func TestReviewService(t *testing.T) {
+	ctx := context.Background()
+
+	newReviewed := func() (*memoryReviewRepository, *stuckTransactionRepository) {
+		transactions := &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"tx-1": {ID: "tx-1", Status: domain.TransactionReview, PaymentMethod: domain.PaymentMethodPIX},
+		}}
+		reviews := &memoryReviewRepository{
+			reviews:      map[string]*domain.Review{"review-1": {ID: "review-1", TransactionID: "tx-1", Status: domain.ReviewPending}},
+			transactions: transactions,
+		}
+		return reviews, transactions
+	}
+
+	t.Run("Should approve the review and publish the transaction", func(t *testing.T) {
+		// Arrange
+		reviews, transactions := newReviewed()
+		broker := &recordingKafkaBroker{}
+		service := NewReviewService(broker, reviews, transactions, time.Hour)
+
+		// Act
+		review, err := service.Approve(ctx, "review-1", "analyst", "ok")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReviewApproved, review.Status)
+		assert.Equal(t, domain.ReviewApproved, reviews.reviews["review-1"].Status)
+		assert.Equal(t, domain.TransactionPending, transactions.transactions["tx-1"].Status)
+		assert.Len(t, broker.published, 1)
+	})
+
+	t.Run("Should keep the review pending when the transaction already left REVIEW", func(t *testing.T) {
+		// Arrange
+		reviews, transactions := newReviewed()
+		transactions.transactions["tx-1"].Status = domain.TransactionCancelled
+		broker := &recordingKafkaBroker{}
+		service := NewReviewService(broker, reviews, transactions, time.Hour)
+
+		// Act
+		_, err := service.Reject(ctx, "review-1", "analyst", "fraude")
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrorTransactionStatusChanged)
+		assert.Equal(t, domain.ReviewPending, reviews.reviews["review-1"].Status)
+		assert.Equal(t, domain.TransactionCancelled, transactions.transactions["tx-1"].Status)
+		assert.Empty(t, broker.published)
+	})
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/bizday"
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+var ErrSettlementBatchNotFound = errors.New("settlement batch not found")
+
+type SettlementConfig struct {
+	Calendar *bizday.Calendar
+	// Location define o dia de referência das transações
+	Location *time.Location
+	// CloseDelay é a folga depois da virada do dia antes de fechar automaticamente os lotes dele
+	CloseDelay time.Duration
+	BatchSize  int
+}
+
+type settlementKey struct {
+	merchantID    string
+	tenantID      string
+	currencyCode  string
+	paymentMethod string
+	referenceDate time.Time
+}
+
+// SettlementService agrupa as transações FINISHED em lotes diários por merchant, moeda e meio de
+// pagamento e calcula a data prevista do repasse (D+N em dias úteis) de cada lote
+type SettlementService struct {
+	repository dRepo.SettlementRepository
+	config     SettlementConfig
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewSettlementService(repository dRepo.SettlementRepository, config SettlementConfig) *SettlementService {
+	if config.Calendar == nil {
+		config.Calendar = bizday.NewBrazilCalendar()
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 500
+	}
+	return &SettlementService{
+		repository: repository,
+		config:     config,
+		logger:     logger.Log,
+		now:        time.Now,
+	}
+}
+
+// referenceDate é o dia civil da criação da transação no fuso da liquidação, gravado à meia-noite UTC
+func (s *SettlementService) referenceDate(t time.Time) time.Time {
+	year, month, day := t.In(s.config.Location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// AssignPending coloca as transações FINISHED sem lote no lote OPEN do seu dia, criando-o quando preciso.
+// Devolve quantas transações foram atribuídas.
+func (s *SettlementService) AssignPending(ctx context.Context) (int, error) {
+	assigned := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return assigned, err
+		}
+
+		transactions, err := s.repository.FindUnbatched(s.config.BatchSize)
+		if err != nil {
+			return assigned, err
+		}
+
+		groups := make(map[settlementKey][]string)
+		var keys []settlementKey
+		for i := range transactions {
+			transaction := &transactions[i]
+			key := settlementKey{
+				merchantID:    transaction.MerchantID,
+				tenantID:      transaction.TenantID,
+				currencyCode:  transaction.CurrencyCode,
+				paymentMethod: transaction.PaymentMethod,
+				referenceDate: s.referenceDate(transaction.CreatedAt),
+			}
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], transaction.ID)
+		}
+
+		round := 0
+		for _, key := range keys {
+			count, err := s.assign(key, groups[key])
+			if err != nil {
+				s.logger.Error("erro ao atribuir transações ao lote de liquidação",
+					zap.Error(err),
+					zap.String("merchantId", key.merchantID),
+					zap.String("paymentMethod", key.paymentMethod),
+					zap.Time("referenceDate", key.referenceDate),
+				)
+				continue
+			}
+			round += count
+		}
+		assigned += round
+
+		// sem progresso a próxima rodada leria as mesmas transações
+		if len(transactions) < s.config.BatchSize || round == 0 {
+			return assigned, nil
+		}
+	}
+}
+
+func (s *SettlementService) assign(key settlementKey, ids []string) (int, error) {
+	// se o lote fechar entre a busca e a atribuição, as transações abrem um novo lote OPEN para o mesmo dia
+	for attempt := 0; attempt < 2; attempt++ {
+		batch, err := s.repository.FindOrCreateOpen(s.newBatch(key))
+		if err != nil {
+			return 0, err
+		}
+
+		count, err := s.repository.Assign(batch, ids)
+		if errors.Is(err, domain.ErrorSettlementBatchNotOpen) {
+			continue
+		}
+		return int(count), err
+	}
+	return 0, domain.ErrorSettlementBatchNotOpen
+}
+
+func (s *SettlementService) newBatch(key settlementKey) *domain.SettlementBatch {
+	days := 0
+	if method, ok := domain.PaymentMethods.Lookup(key.paymentMethod); ok {
+		days = method.SettlementDays
+	} else {
+		s.logger.Warn("meio de pagamento sem regra de liquidação, usando D+0",
+			zap.String("paymentMethod", key.paymentMethod),
+		)
+	}
+
+	return domain.NewSettlementBatch(key.merchantID, key.tenantID, key.currencyCode, key.paymentMethod, key.referenceDate,
+		s.config.Calendar.AddBusinessDays(key.referenceDate, days))
+}
+
+// CloseDue fecha os lotes OPEN cujo dia de referência terminou há mais de CloseDelay
+func (s *SettlementService) CloseDue(ctx context.Context) (int, error) {
+	cutoff := s.referenceDate(s.now().Add(-s.config.CloseDelay))
+	batches, err := s.repository.FindDueOpen(cutoff, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range batches {
+		if err := ctx.Err(); err != nil {
+			return closed, err
+		}
+
+		batch := &batches[i]
+		if err := s.close(batch); err != nil {
+			if !errors.Is(err, domain.ErrorSettlementBatchNotOpen) {
+				s.logger.Error("erro ao fechar lote de liquidação",
+					zap.Error(err),
+					zap.String("batchId", batch.ID),
+				)
+			}
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+func (s *SettlementService) close(batch *domain.SettlementBatch) error {
+	if err := batch.Close(s.now()); err != nil {
+		return err
+	}
+	if err := s.repository.Close(batch); err != nil {
+		return err
+	}
+
+	s.logger.Info("lote de liquidação fechado",
+		zap.String("batchId", batch.ID),
+		zap.String("merchantId", batch.MerchantID),
+		zap.Int("transactionCount", batch.TransactionCount),
+		zap.Float64("netAmount", batch.NetAmount),
+	)
+	return nil
+}
+
+// Close fecha o lote antes do horário automático
+func (s *SettlementService) Close(ctx context.Context, id string) (*domain.SettlementBatch, error) {
+	batch, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.close(batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// MarkPaid registra o repasse de um lote CLOSED
+func (s *SettlementService) MarkPaid(ctx context.Context, id, payoutReference string) (*domain.SettlementBatch, error) {
+	batch, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.MarkPaid(payoutReference, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateIfStatus(batch, domain.SettlementBatchClosed); err != nil {
+		if errors.Is(err, domain.ErrorTransactionStatusChanged) {
+			return nil, domain.ErrorSettlementBatchNotClosed
+		}
+		return nil, err
+	}
+
+	s.logger.Info("repasse do lote de liquidação registrado",
+		zap.String("batchId", batch.ID),
+		zap.String("payoutReference", payoutReference),
+	)
+	return batch, nil
+}
+
+// FindByID devolve o lote somente se ele for visível para o principal
+func (s *SettlementService) FindByID(ctx context.Context, id string) (*domain.SettlementBatch, error) {
+	batch, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, ErrSettlementBatchNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(batch.MerchantID, batch.TenantID) {
+		return nil, ErrSettlementBatchNotFound
+	}
+	return batch, nil
+}
+
+func (s *SettlementService) FindPaginated(ctx context.Context, filter dRepo.SettlementBatchFilter, page, pageSize int) (*dto.PaginatedSettlementBatchesResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	batches, total, err := s.repository.FindPaginated(filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedSettlementBatchesResponseDto{
+		Data:       batches,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// FindTransactions pagina as transações de um lote visível para o principal
+func (s *SettlementService) FindTransactions(ctx context.Context, id string, page, pageSize int) (*dto.PaginatedTransactionsResponseDto, error) {
+	batch, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	transactions, total, err := s.repository.FindTransactions(batch.ID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedTransactionsResponseDto{
+		Data:       transactions,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// Start atribui as transações finalizadas e fecha os lotes vencidos a cada interval
+func (s *SettlementService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			assigned, err := s.AssignPending(ctx)
+			if err != nil {
+				s.logger.Error("erro ao montar lotes de liquidação",
+					zap.Error(err),
+				)
+			}
+
+			closed, err := s.CloseDue(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar lotes de liquidação vencidos",
+					zap.Error(err),
+				)
+			}
+
+			if assigned > 0 || closed > 0 {
+				s.logger.Info("ciclo de liquidação",
+					zap.Int("assigned", assigned),
+					zap.Int("closed", closed),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/bizday"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySettlementRepository imita as garantias do repositório GORM: um lote OPEN por chave e
+// atribuição apenas a lotes OPEN
+type memorySettlementRepository struct {
+	dRepo.SettlementRepository
+
+	transactions []*domain.Transaction
+	batches      map[string]*domain.SettlementBatch
+}
+
+func (m *memorySettlementRepository) FindUnbatched(limit int) ([]domain.Transaction, error) {
+	var unbatched []domain.Transaction
+	for _, transaction := range m.transactions {
+		if transaction.Status == domain.TransactionFinished && transaction.SettlementBatchID == nil && len(unbatched) < limit {
+			unbatched = append(unbatched, domain.Transaction{ID: transaction.ID, Status: transaction.Status, MerchantID: transaction.MerchantID, PaymentMethod: transaction.PaymentMethod, CurrencyCode: transaction.CurrencyCode, CreatedAt: transaction.CreatedAt})
+		}
+	}
+	return unbatched, nil
+}
+
+func (m *memorySettlementRepository) FindOrCreateOpen(batch *domain.SettlementBatch) (*domain.SettlementBatch, error) {
+	for _, open := range m.batches {
+		if open.Status == domain.SettlementBatchOpen && open.MerchantID == batch.MerchantID && open.CurrencyCode == batch.CurrencyCode &&
+			open.PaymentMethod == batch.PaymentMethod && open.ReferenceDate.Equal(batch.ReferenceDate) {
+			return open, nil
+		}
+	}
+	m.batches[batch.ID] = batch
+	return batch, nil
+}
+
+func (m *memorySettlementRepository) Assign(batch *domain.SettlementBatch, ids []string) (int64, error) {
+	if m.batches[batch.ID].Status != domain.SettlementBatchOpen {
+		return 0, domain.ErrorSettlementBatchNotOpen
+	}
+
+	var assigned int64
+	for _, transaction := range m.transactions {
+		for _, id := range ids {
+			if transaction.ID == id && transaction.SettlementBatchID == nil {
+				transaction.SettlementBatchID = &batch.ID
+				assigned++
+			}
+		}
+	}
+	m.detachUnfinished(batch)
+	m.applyTotals(batch)
+	return assigned, nil
+}
+
+func (m *memorySettlementRepository) Close(batch *domain.SettlementBatch) error {
+	for _, transaction := range m.transactions {
+		if transaction.SettlementBatchID != nil && *transaction.SettlementBatchID == batch.ID && transaction.Status == domain.TransactionFinished {
+			transaction.SettlementLocked = true
+		}
+	}
+	m.detachUnfinished(batch)
+	m.applyTotals(batch)
+	m.batches[batch.ID] = batch
+	return nil
+}
+
+func (m *memorySettlementRepository) detachUnfinished(batch *domain.SettlementBatch) {
+	for _, transaction := range m.transactions {
+		if transaction.SettlementBatchID != nil && *transaction.SettlementBatchID == batch.ID &&
+			transaction.Status != domain.TransactionFinished && !transaction.SettlementLocked {
+			transaction.SettlementBatchID = nil
+		}
+	}
+}
+
+func (m *memorySettlementRepository) applyTotals(batch *domain.SettlementBatch) {
+	count, gross, fee := 0, 0.0, 0.0
+	for _, transaction := range m.transactions {
+		if transaction.SettlementBatchID != nil && *transaction.SettlementBatchID == batch.ID && transaction.Status == domain.TransactionFinished {
+			count++
+			gross += transaction.Amount
+			fee += transaction.FeeAmount
+		}
+	}
+	batch.ApplyTotals(count, gross, fee)
+}
+
+func (m *memorySettlementRepository) FindDueOpen(before time.Time, limit int) ([]domain.SettlementBatch, error) {
+	var due []domain.SettlementBatch
+	for _, batch := range m.batches {
+		if batch.Status == domain.SettlementBatchOpen && batch.ReferenceDate.Before(before) {
+			due = append(due, *batch)
+		}
+	}
+	return due, nil
+}
+
+func (m *memorySettlementRepository) FindByID(id string) (*domain.SettlementBatch, error) {
+	batch, ok := m.batches[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *batch
+	return &copied, nil
+}
+
+func (m *memorySettlementRepository) UpdateIfStatus(batch *domain.SettlementBatch, expectedStatus string) error {
+	if m.batches[batch.ID].Status != expectedStatus {
+		return domain.ErrorTransactionStatusChanged
+	}
+	m.batches[batch.ID] = batch
+	return nil
+}
+
+func TestSettlementService(t *testing.T) {
+	ctx := context.Background()
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	// sábado, 4 de abril de 2026, às 22h em São Paulo (já domingo em UTC)
+	saturday := time.Date(2026, 4, 4, 22, 0, 0, 0, location)
+
+	newService := func(repository *memorySettlementRepository, now time.Time) *SettlementService {
+		service := NewSettlementService(repository, SettlementConfig{
+			Calendar:   bizday.NewBrazilCalendar(),
+			Location:   location,
+			CloseDelay: time.Hour,
+		})
+		service.now = func() time.Time { return now }
+		return service
+	}
+
+	newRepository := func() *memorySettlementRepository {
+		return &memorySettlementRepository{
+			batches: make(map[string]*domain.SettlementBatch),
+			transactions: []*domain.Transaction{
+				{ID: "tx-1", Status: domain.TransactionFinished, MerchantID: "merchant-1", PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Amount: 100, FeeAmount: 1, CreatedAt: saturday},
+				{ID: "tx-2", Status: domain.TransactionFinished, MerchantID: "merchant-1", PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Amount: 50, FeeAmount: 0.5, CreatedAt: saturday.Add(-time.Hour)},
+				{ID: "tx-3", Status: domain.TransactionFinished, MerchantID: "merchant-1", PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Amount: 200, FeeAmount: 6, CreatedAt: saturday},
+				{ID: "tx-4", Status: domain.TransactionPending, MerchantID: "merchant-1", PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Amount: 10, CreatedAt: saturday},
+			},
+		}
+	}
+
+	findBatch := func(repository *memorySettlementRepository, paymentMethod string) *domain.SettlementBatch {
+		for _, batch := range repository.batches {
+			if batch.PaymentMethod == paymentMethod {
+				return batch
+			}
+		}
+		return nil
+	}
+
+	t.Run("Should group FINISHED transactions per method and local day with D+N business-day dates", func(t *testing.T) {
+		// Arrange
+		repository := newRepository()
+		service := newService(repository, saturday)
+
+		// Act
+		assigned, err := service.AssignPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, assigned)
+		require.Len(t, repository.batches, 2)
+
+		pix := findBatch(repository, domain.PaymentMethodPIX)
+		require.NotNil(t, pix)
+		assert.Equal(t, time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC), pix.ReferenceDate)
+		assert.Equal(t, time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC), pix.ExpectedSettlementDate)
+		assert.Equal(t, 2, pix.TransactionCount)
+		assert.Equal(t, 150.0, pix.GrossAmount)
+		assert.Equal(t, 148.5, pix.NetAmount)
+
+		card := findBatch(repository, domain.PaymentMethodCreditCard)
+		require.NotNil(t, card)
+		// D+30 a partir de segunda, 6/4, pulando Tiradentes (21/4) e o Dia do Trabalho (1/5)
+		assert.Equal(t, time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC), card.ExpectedSettlementDate)
+		assert.Nil(t, repository.transactions[3].SettlementBatchID)
+	})
+
+	t.Run("Should close only batches whose day ended before the close delay and lock their transactions", func(t *testing.T) {
+		// Arrange
+		repository := newRepository()
+		_, err := newService(repository, saturday).AssignPending(ctx)
+		require.NoError(t, err)
+		service := newService(repository, time.Date(2026, 4, 5, 0, 30, 0, 0, location))
+
+		// Act
+		closedEarly, earlyErr := service.CloseDue(ctx)
+		service.now = func() time.Time { return time.Date(2026, 4, 5, 1, 30, 0, 0, location) }
+		closed, err := service.CloseDue(ctx)
+
+		// Assert
+		require.NoError(t, earlyErr)
+		assert.Equal(t, 0, closedEarly)
+		require.NoError(t, err)
+		assert.Equal(t, 2, closed)
+		for _, batch := range repository.batches {
+			assert.Equal(t, domain.SettlementBatchClosed, batch.Status)
+		}
+		assert.True(t, repository.transactions[0].SettlementLocked)
+		assert.False(t, repository.transactions[3].SettlementLocked)
+	})
+
+	t.Run("Should open a new batch for transactions that finish after the day was closed", func(t *testing.T) {
+		// Arrange
+		repository := newRepository()
+		service := newService(repository, saturday)
+		_, err := service.AssignPending(ctx)
+		require.NoError(t, err)
+		_, err = service.Close(ctx, findBatch(repository, domain.PaymentMethodPIX).ID)
+		require.NoError(t, err)
+		repository.transactions[3].Status = domain.TransactionFinished
+
+		// Act
+		assigned, err := service.AssignPending(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, assigned)
+		assert.Len(t, repository.batches, 3)
+		assert.NotEqual(t, *repository.transactions[0].SettlementBatchID, *repository.transactions[3].SettlementBatchID)
+	})
+
+	t.Run("Should leave out of totals and locks the transactions that left FINISHED after being batched", func(t *testing.T) {
+		// Arrange
+		repository := newRepository()
+		service := newService(repository, saturday)
+		_, err := service.AssignPending(ctx)
+		require.NoError(t, err)
+		id := findBatch(repository, domain.PaymentMethodPIX).ID
+		repository.transactions[1].Status = domain.TransactionFailed
+
+		// Act
+		closed, err := service.Close(ctx, id)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, closed.TransactionCount)
+		assert.Equal(t, 100.0, closed.GrossAmount)
+		assert.True(t, repository.transactions[0].SettlementLocked)
+		assert.False(t, repository.transactions[1].SettlementLocked)
+		assert.Nil(t, repository.transactions[1].SettlementBatchID)
+	})
+
+	t.Run("Should only record the payout of a closed batch", func(t *testing.T) {
+		// Arrange
+		repository := newRepository()
+		service := newService(repository, saturday)
+		_, err := service.AssignPending(ctx)
+		require.NoError(t, err)
+		id := findBatch(repository, domain.PaymentMethodPIX).ID
+
+		// Act
+		_, openErr := service.MarkPaid(ctx, id, "payout-1")
+		_, err = service.Close(ctx, id)
+		require.NoError(t, err)
+		paid, err := service.MarkPaid(ctx, id, "payout-1")
+
+		// Assert
+		assert.ErrorIs(t, openErr, domain.ErrorSettlementBatchNotClosed)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SettlementBatchPaid, paid.Status)
+		assert.Equal(t, "payout-1", repository.batches[id].PayoutReference)
+	})
+}
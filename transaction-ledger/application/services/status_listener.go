@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+// StatusListener é notificado depois que uma mudança de status da transação foi persistida.
+// previousStatus vazio indica a criação da transação.
+type StatusListener interface {
+	TransactionStatusChanged(ctx context.Context, transaction *domain.Transaction, previousStatus string)
+}
+
+type statusListeners []StatusListener
+
+func (l statusListeners) notify(ctx context.Context, transaction *domain.Transaction, previousStatus string) {
+	if transaction.Status == previousStatus {
+		return
+	}
+	for _, listener := range l {
+		listener.TransactionStatusChanged(ctx, transaction, previousStatus)
+	}
+}
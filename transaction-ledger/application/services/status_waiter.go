@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"go.uber.org/zap"
+)
+
+var ErrTooManyWaiters = errors.New("too many requests waiting for a transaction status")
+
+// StatusWaiter acorda as requisições de long-poll quando o consumidor aplica o retorno do processamento.
+// Os retornos consumidos por outras réplicas chegam pelo barramento de eventos, escutado em Start.
+type StatusWaiter struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+	count   int
+	max     int
+	maxWait time.Duration
+}
+
+func NewStatusWaiter(maxWaiters int, maxWait time.Duration) *StatusWaiter {
+	return &StatusWaiter{
+		waiters: make(map[string]map[chan struct{}]struct{}),
+		max:     maxWaiters,
+		maxWait: maxWait,
+	}
+}
+
+// Register inscreve uma espera pela transação; cancel deve sempre ser chamado para liberar a vaga
+func (w *StatusWaiter) Register(transactionID string) (notified <-chan struct{}, cancel func(), err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count >= w.max {
+		return nil, nil, ErrTooManyWaiters
+	}
+
+	ch := make(chan struct{})
+	if w.waiters[transactionID] == nil {
+		w.waiters[transactionID] = make(map[chan struct{}]struct{})
+	}
+	w.waiters[transactionID][ch] = struct{}{}
+	w.count++
+
+	return ch, func() { w.remove(transactionID, ch) }, nil
+}
+
+// Start escuta os eventos de status publicados por todas as réplicas até o contexto ser cancelado e acorda
+// as esperas da transação de cada evento. Um evento perdido (por exemplo, com a conexão de LISTEN caída)
+// só faz a espera terminar pelo timeout, com a transação relida do banco.
+func (w *StatusWaiter) Start(ctx context.Context, bus EventBus) {
+	if err := bus.Listen(ctx, w.receive); err != nil {
+		logger.Log.Error("erro ao escutar eventos para o long-poll",
+			zap.Error(err),
+		)
+	}
+}
+
+func (w *StatusWaiter) receive(payload []byte) {
+	var event domain.TransactionEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.TransactionID == "" {
+		return
+	}
+	w.Notify(event.TransactionID)
+}
+
+// Notify acorda todas as esperas da transação
+func (w *StatusWaiter) Notify(transactionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.waiters[transactionID] {
+		close(ch)
+		w.count--
+	}
+	delete(w.waiters, transactionID)
+}
+
+func (w *StatusWaiter) Waiting() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+func (w *StatusWaiter) remove(transactionID string, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	waiters, ok := w.waiters[transactionID]
+	if !ok {
+		return
+	}
+	if _, ok := waiters[ch]; !ok {
+		return
+	}
+	delete(waiters, ch)
+	w.count--
+	if len(waiters) == 0 {
+		delete(w.waiters, transactionID)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusWaiter_Start(t *testing.T) {
+	t.Run("Should wake the waits of a transaction whose status event came from another replica", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		bus := pubsub.NewMemoryBus()
+		waiter := NewStatusWaiter(10, time.Minute)
+		go waiter.Start(ctx, bus)
+
+		notified, release, err := waiter.Register("tx-1")
+		require.NoError(t, err)
+		defer release()
+		other, releaseOther, err := waiter.Register("tx-2")
+		require.NoError(t, err)
+		defer releaseOther()
+		payload, err := json.Marshal(domain.TransactionEvent{TransactionID: "tx-1", Status: domain.TransactionFinished})
+		require.NoError(t, err)
+
+		// Act
+		// o Listen do barramento em memória se inscreve em outra goroutine; publica até a espera acordar
+		woke := assert.Eventually(t, func() bool {
+			require.NoError(t, bus.Publish(ctx, payload))
+			select {
+			case <-notified:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+
+		// Assert
+		assert.True(t, woke)
+		select {
+		case <-other:
+			t.Fatal("wait of another transaction was woken")
+		default:
+		}
+		assert.Equal(t, 1, waiter.Waiting())
+	})
+
+	t.Run("Should ignore payloads that are not transaction events", func(t *testing.T) {
+		// Arrange
+		waiter := NewStatusWaiter(10, time.Minute)
+		_, release, err := waiter.Register("tx-1")
+		require.NoError(t, err)
+		defer release()
+
+		// Act
+		waiter.receive([]byte("not json"))
+		waiter.receive([]byte(`{"id": 1}`))
+
+		// Assert
+		assert.Equal(t, 1, waiter.Waiting())
+	})
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrEmptyBatch            = errors.New("batch has no items")
+	ErrBatchTooLarge         = errors.New("batch exceeds the maximum number of items")
+	ErrInvalidBatchMode      = errors.New("batch mode must be ALL_OR_NOTHING or BEST_EFFORT")
+	ErrInvalidIdempotencyKey = errors.New("idempotency key must have at most 255 characters")
+)
+
+const maxIdempotencyKeyLength = 255
+
+// WithBatchLimit define quantos itens POST /transactions/batch aceita por requisição
+func WithBatchLimit(maxItems int) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.batchMaxItems = maxItems
+	}
+}
+
+// CreateBatch valida cada item como em CreateTransaction, grava os válidos numa única transação do banco e
+// publica tudo numa única escrita no Kafka. A análise de risco roda antes da gravação: recusas são gravadas
+// como FAILED no BEST_EFFORT e derrubam o lote inteiro no ALL_OR_NOTHING, e as revisões são gravadas junto.
+// A quota é reservada e os cartões são gravados no cofre na mesma transação do banco que os inserts.
+func (s *TransactionService) CreateBatch(ctx context.Context, request *dto.TransactionBatchRequestDto) (*dto.TransactionBatchResponseDto, error) {
+	mode := strings.ToUpper(request.Mode)
+	if mode == "" {
+		mode = dto.BatchModeAllOrNothing
+	}
+	if mode != dto.BatchModeAllOrNothing && mode != dto.BatchModeBestEffort {
+		return nil, ErrInvalidBatchMode
+	}
+	if len(request.Items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(request.Items) > s.batchMaxItems {
+		return nil, ErrBatchTooLarge
+	}
+	if len(request.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	merchantID := ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID = principal.MerchantID
+	}
+
+	var requestHash string
+	if request.IdempotencyKey != "" {
+		hash, err := batchRequestHash(mode, request.Items)
+		if err != nil {
+			return nil, err
+		}
+		requestHash = hash
+		if stored, err := s.replayBatch(merchantID, request.IdempotencyKey, requestHash); stored != nil || err != nil {
+			return stored, err
+		}
+	}
+
+	response := &dto.TransactionBatchResponseDto{
+		Mode:    mode,
+		Results: make([]dto.TransactionBatchItemResultDto, len(request.Items)),
+	}
+
+	// a quota considera o que os itens anteriores do lote já vão consumir
+	pendingByCurrency := make(map[string]float64)
+	prepared := make([]*preparedTransaction, len(request.Items))
+	for i := range request.Items {
+		response.Results[i].Index = i
+
+		currency := strings.ToUpper(request.Items[i].CurrencyCode)
+		item, errs := s.prepareTransaction(ctx, &request.Items[i], pendingByCurrency[merchantID+"|"+currency])
+		if len(errs) > 0 {
+			rejectBatchItem(&response.Results[i], errs)
+			continue
+		}
+		if mode == dto.BatchModeAllOrNothing && item.transaction.RiskDecision == domain.RiskDecisionDecline {
+			// uma recusa gravaria o item como FAILED: no ALL_OR_NOTHING ela derruba o lote como um item inválido
+			rejectBatchItem(&response.Results[i], []error{ErrTransactionDeclined})
+			continue
+		}
+		pendingByCurrency[merchantID+"|"+currency] += item.transaction.Amount
+		prepared[i] = item
+	}
+
+	if mode == dto.BatchModeAllOrNothing && countRejected(response) > 0 {
+		return finishBatch(response), nil
+	}
+
+	for i, item := range prepared {
+		if item == nil {
+			continue
+		}
+		if errs := s.sealCard(ctx, item); len(errs) > 0 {
+			rejectBatchItem(&response.Results[i], errs)
+			prepared[i] = nil
+			if mode == dto.BatchModeAllOrNothing {
+				return finishBatch(response), nil
+			}
+		}
+	}
+
+	var creation *dRepo.TransactionCreation
+	var messages []akafka.Message
+	for {
+		var err error
+		creation, messages, err = s.buildBatchCreation(prepared, response)
+		if err != nil {
+			return nil, err
+		}
+		if len(creation.Transactions) == 0 {
+			return finishBatch(response), nil
+		}
+
+		finishBatch(response)
+		if request.IdempotencyKey != "" {
+			stored, err := json.Marshal(response)
+			if err != nil {
+				return nil, err
+			}
+			creation.BatchRequest = &domain.BatchRequest{MerchantID: merchantID, IdempotencyKey: request.IdempotencyKey, RequestHash: requestHash, Response: string(stored)}
+		}
+		s.reserveQuotas(creation)
+
+		err = s.repository.CreateBatch(creation)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, domain.ErrorBatchRequestExists) {
+			// outra requisição com a mesma chave gravou primeiro; a resposta dela vale para as duas
+			return s.replayBatch(merchantID, request.IdempotencyKey, requestHash)
+		}
+		var reservationErr *dRepo.QuotaReservationError
+		if !errors.As(err, &reservationErr) {
+			return nil, err
+		}
+
+		// uma criação concorrente consumiu a quota depois da checagem de prepareTransaction: os itens dessa
+		// quota são recusados e, no BEST_EFFORT, o restante é gravado de novo sem eles
+		rejectQuotaItems(prepared, response, s.quota.Exceeded(reservationErr))
+		if mode == dto.BatchModeAllOrNothing {
+			for i := range response.Results {
+				if len(response.Results[i].Errors) == 0 {
+					response.Results[i] = dto.TransactionBatchItemResultDto{Index: i}
+				}
+			}
+			return finishBatch(response), nil
+		}
+	}
+
+	for _, transaction := range creation.Transactions {
+		if transaction.RiskDecision == domain.RiskDecisionDecline {
+			s.logger.Warn("transação recusada pela análise de risco",
+				zap.String("id", transaction.ID),
+				zap.Int("riskScore", transaction.RiskScore),
+				zap.String("reasons", transaction.RiskReasons),
+			)
+		}
+		s.listeners.notify(ctx, transaction, "")
+	}
+	for _, review := range creation.Reviews {
+		s.reviews.Opened(review)
+	}
+
+	if err := s.kafkaBroker.PublishBatch(messages); err != nil {
+		s.logger.Error("erro ao publicar lote no Kafka",
+			zap.Error(err),
+			zap.Int("count", len(messages)),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("lote de transações publicado com sucesso",
+		zap.Int("items", len(request.Items)),
+		zap.Int("published", len(messages)),
+	)
+	return response, nil
+}
+
+// buildBatchCreation monta o que o lote grava a partir dos itens preparados e preenche o resultado de cada um
+func (s *TransactionService) buildBatchCreation(prepared []*preparedTransaction, response *dto.TransactionBatchResponseDto) (*dRepo.TransactionCreation, []akafka.Message, error) {
+	creation := &dRepo.TransactionCreation{}
+	messages := make([]akafka.Message, 0, len(prepared))
+	for i, item := range prepared {
+		if item == nil {
+			continue
+		}
+		transaction := item.transaction
+		creation.Transactions = append(creation.Transactions, transaction)
+		creation.Installments = append(creation.Installments, item.schedule...)
+		if item.vaulted != nil {
+			creation.Cards = append(creation.Cards, item.vaulted)
+		}
+
+		result := &response.Results[i]
+		result.ID = transaction.ID
+		result.Status = transaction.Status
+
+		switch transaction.RiskDecision {
+		case domain.RiskDecisionDecline:
+			result.Code = ErrorCodeRiskDeclined
+			result.Errors = []string{ErrTransactionDeclined.Error()}
+			continue
+		case domain.RiskDecisionReview:
+			// fica aguardando revisão manual, não segue para o processamento
+			creation.Reviews = append(creation.Reviews, s.reviews.Prepare(transaction))
+			continue
+		}
+
+		jsonData, err := transaction.ToJson()
+		if err != nil {
+			return nil, nil, err
+		}
+		messages = append(messages, akafka.Message{Topic: transaction.ProcessingTopic(), Value: jsonData})
+	}
+	return creation, messages, nil
+}
+
+// rejectQuotaItems recusa os itens que consomem a quota esgotada e os tira do que será gravado
+func rejectQuotaItems(prepared []*preparedTransaction, response *dto.TransactionBatchResponseDto, quotaErr *QuotaExceededError) {
+	for i, item := range prepared {
+		if item == nil || item.transaction.MerchantID != quotaErr.MerchantID || item.transaction.CurrencyCode != quotaErr.CurrencyCode {
+			continue
+		}
+		if item.transaction.Status == domain.TransactionFailed {
+			// recusada pelo risco, não consome quota e continua gravada como FAILED
+			continue
+		}
+		response.Results[i] = dto.TransactionBatchItemResultDto{Index: i}
+		rejectBatchItem(&response.Results[i], []error{quotaErr})
+		prepared[i] = nil
+	}
+}
+
+// replayBatch devolve a resposta do lote já gravado com a chave, ou nil se a chave ainda não foi usada
+func (s *TransactionService) replayBatch(merchantID, idempotencyKey, requestHash string) (*dto.TransactionBatchResponseDto, error) {
+	stored, err := s.repository.FindBatchRequest(merchantID, idempotencyKey)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	if err := stored.Matches(requestHash); err != nil {
+		return nil, err
+	}
+
+	var response dto.TransactionBatchResponseDto
+	if err := json.Unmarshal([]byte(stored.Response), &response); err != nil {
+		return nil, err
+	}
+	response.Replayed = true
+	return &response, nil
+}
+
+// unmaskedBatchItem tem os campos de dto.TransactionRequestDto sem o MarshalJSON que mascara o cartão e a
+// chave Pix; com a máscara, lotes que diferem só no PAN, no titular ou na chave teriam o mesmo hash
+type unmaskedBatchItem dto.TransactionRequestDto
+
+// batchRequestHash identifica o conteúdo do lote, para recusar a mesma chave com outro lote
+func batchRequestHash(mode string, items []dto.TransactionRequestDto) (string, error) {
+	unmasked := make([]unmaskedBatchItem, len(items))
+	for i, item := range items {
+		unmasked[i] = unmaskedBatchItem(item)
+	}
+	data, err := json.Marshal(struct {
+		Mode  string              `json:"mode"`
+		Items []unmaskedBatchItem `json:"items"`
+	}{Mode: mode, Items: unmasked})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func rejectBatchItem(result *dto.TransactionBatchItemResultDto, errs []error) {
+	result.Errors = make([]string, 0, len(errs))
+	for _, err := range errs {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	var quotaErr *QuotaExceededError
+	switch {
+	case errors.As(errs[0], &quotaErr):
+		result.Code = ErrorCodeDailyQuotaExceeded
+	case errors.Is(errs[0], ErrTransactionDeclined):
+		result.Code = ErrorCodeRiskDeclined
+	}
+}
+
+func countRejected(response *dto.TransactionBatchResponseDto) int {
+	rejected := 0
+	for _, result := range response.Results {
+		if len(result.Errors) > 0 {
+			rejected++
+		}
+	}
+	return rejected
+}
+
+// finishBatch conta como aceitos só os itens gravados sem erro; num ALL_OR_NOTHING abortado nenhum é aceito
+func finishBatch(response *dto.TransactionBatchResponseDto) *dto.TransactionBatchResponseDto {
+	response.Accepted = 0
+	for _, result := range response.Results {
+		if result.ID != "" && len(result.Errors) == 0 {
+			response.Accepted++
+		}
+	}
+	response.Rejected = len(response.Results) - response.Accepted
+	return response
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/risk"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const batchRiskRules = `
+rules:
+  - name: high-amount-brl
+    type: amount
+    currency: BRL
+    above: 1000
+    score: 60
+  - name: blocked
+    type: blocked_description
+    patterns: ["gift ?card"]
+    action: DECLINE
+`
+
+func TestTransactionService_CreateBatch(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(t *testing.T, repository *memoryTransactionRepository, broker *recordingKafkaBroker) *TransactionService {
+		ruleSet, err := risk.ParseRuleSet([]byte(batchRiskRules))
+		require.NoError(t, err)
+		return NewTransactionService(broker, repository,
+			WithRiskEngine(risk.NewEngine(ruleSet, nil)),
+			WithReviewService(NewReviewService(broker, nil, repository, time.Hour)),
+			WithInstallmentService(NewInstallmentService(nil, InstallmentPolicy{MaxInstallments: 12, InterestFreeInstallments: 12}, nil)),
+		)
+	}
+	item := func(amount float64, description string) dto.TransactionRequestDto {
+		return dto.TransactionRequestDto{Amount: amount, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: description}
+	}
+
+	t.Run("Should store nothing in ALL_OR_NOTHING mode when the risk engine declines an item", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		service := newService(t, repository, &recordingKafkaBroker{})
+
+		// Act
+		response, err := service.CreateBatch(ctx, &dto.TransactionBatchRequestDto{Items: []dto.TransactionRequestDto{
+			item(100, "Fornecedor A"),
+			item(50, "gift card"),
+		}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, response.Accepted)
+		assert.Equal(t, ErrorCodeRiskDeclined, response.Results[1].Code)
+		assert.Empty(t, response.Results[0].ID)
+		assert.Zero(t, repository.batches)
+	})
+
+	t.Run("Should store transactions, installments and reviews in one write before publishing", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		broker := &recordingKafkaBroker{}
+		service := newService(t, repository, broker)
+		installments := dto.TransactionRequestDto{Amount: 300, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Description: "Parcelado", Installments: 3}
+
+		// Act
+		response, err := service.CreateBatch(ctx, &dto.TransactionBatchRequestDto{Mode: dto.BatchModeBestEffort, Items: []dto.TransactionRequestDto{
+			item(100, "Fornecedor A"),
+			item(5000, "Fornecedor B"),
+			item(50, "gift card"),
+			installments,
+		}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, repository.batches)
+		assert.Len(t, repository.transactions, 4)
+		assert.Len(t, repository.installments, 3)
+		require.Len(t, repository.reviews, 1)
+		assert.Equal(t, response.Results[1].ID, repository.reviews[0].TransactionID)
+		assert.Equal(t, domain.TransactionReview, response.Results[1].Status)
+		assert.Equal(t, ErrorCodeRiskDeclined, response.Results[2].Code)
+		assert.Equal(t, domain.TransactionFailed, response.Results[2].Status)
+		assert.Len(t, broker.published, 2, "only the items neither declined nor in review are published")
+		assert.Equal(t, 3, response.Accepted)
+	})
+
+	t.Run("Should return the stored response when the same batch is sent again with its idempotency key", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		service := newService(t, repository, &recordingKafkaBroker{})
+		request := func(description string) *dto.TransactionBatchRequestDto {
+			return &dto.TransactionBatchRequestDto{IdempotencyKey: "batch-1", Items: []dto.TransactionRequestDto{item(100, description)}}
+		}
+
+		// Act
+		first, err := service.CreateBatch(ctx, request("Fornecedor A"))
+		require.NoError(t, err)
+		replayed, replayErr := service.CreateBatch(ctx, request("Fornecedor A"))
+		_, reusedErr := service.CreateBatch(ctx, request("Fornecedor B"))
+
+		// Assert
+		require.NoError(t, replayErr)
+		assert.Equal(t, 1, repository.batches)
+		assert.Len(t, repository.transactions, 1)
+		assert.True(t, replayed.Replayed)
+		assert.Equal(t, first.Results[0].ID, replayed.Results[0].ID)
+		assert.ErrorIs(t, reusedErr, domain.ErrorIdempotencyKeyUsed)
+	})
+	t.Run("Should reject the items of a quota used up by a concurrent creation and store the rest", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), concurrentUsed: map[string]float64{"BRL": 950}}
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository, WithQuotaService(NewQuotaService(repository, 1000, nil)))
+		merchantCtx := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1"})
+		usd := dto.TransactionRequestDto{Amount: 100, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "USD", Description: "Fornecedor B"}
+
+		// Act
+		response, err := service.CreateBatch(merchantCtx, &dto.TransactionBatchRequestDto{Mode: dto.BatchModeBestEffort, Items: []dto.TransactionRequestDto{
+			item(100, "Fornecedor A"),
+			usd,
+		}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, repository.batches, "the batch is written again without the rejected items")
+		assert.Equal(t, ErrorCodeDailyQuotaExceeded, response.Results[0].Code)
+		assert.Empty(t, response.Results[0].ID)
+		assert.Empty(t, response.Results[1].Errors)
+		require.Len(t, repository.transactions, 1)
+		assert.Contains(t, repository.transactions, response.Results[1].ID)
+		assert.Len(t, broker.published, 1)
+		assert.Equal(t, 1, response.Accepted)
+	})
+
+	t.Run("Should store only the cards of the items written when the quota is used up by a concurrent creation", func(t *testing.T) {
+		// Arrange
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		sealing, err := cardvault.NewSealingKeyring("k1", map[string]*rsa.PublicKey{"k1": &privateKey.PublicKey})
+		require.NoError(t, err)
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), concurrentUsed: map[string]float64{"BRL": 950}}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository,
+			WithQuotaService(NewQuotaService(repository, 1000, nil)),
+			WithCardVault(cardvault.NewVault(sealing, nil)),
+		)
+		merchantCtx := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1"})
+		cardItem := func(currency, number string) dto.TransactionRequestDto {
+			return dto.TransactionRequestDto{Amount: 100, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: currency, Description: "Fornecedor",
+				Card: &dto.CardRequestDto{Number: number, ExpiryMonth: 12, ExpiryYear: time.Now().Year() + 1, HolderName: "FULANO DE TAL"}}
+		}
+
+		// Act
+		response, err := service.CreateBatch(merchantCtx, &dto.TransactionBatchRequestDto{Mode: dto.BatchModeBestEffort, Items: []dto.TransactionRequestDto{
+			cardItem("BRL", "4111111111111111"),
+			cardItem("USD", "5555555555554444"),
+		}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, ErrorCodeDailyQuotaExceeded, response.Results[0].Code)
+		require.Len(t, repository.cards, 1, "the card of the rejected item is not written to the vault")
+		assert.Equal(t, "4444", repository.cards[0].Last4)
+		assert.Equal(t, repository.cards[0].Token, repository.transactions[response.Results[1].ID].CardToken)
+	})
+
+	t.Run("Should store nothing in ALL_OR_NOTHING mode when the quota is used up by a concurrent creation", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), concurrentUsed: map[string]float64{"BRL": 950}}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithQuotaService(NewQuotaService(repository, 1000, nil)))
+		merchantCtx := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1"})
+
+		// Act
+		response, err := service.CreateBatch(merchantCtx, &dto.TransactionBatchRequestDto{Items: []dto.TransactionRequestDto{
+			item(10, "Fornecedor A"),
+			item(50, "Fornecedor B"),
+		}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, repository.transactions)
+		assert.Zero(t, response.Accepted)
+		assert.Equal(t, ErrorCodeDailyQuotaExceeded, response.Results[0].Code)
+		assert.Equal(t, ErrorCodeDailyQuotaExceeded, response.Results[1].Code)
+	})
+
+	t.Run("Should not count cancelled and expired transactions against the quota", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: map[string]*domain.Transaction{
+			"cancelled-1": {ID: "cancelled-1", MerchantID: "merchant-1", CurrencyCode: "BRL", Amount: 600, Status: domain.TransactionCancelled},
+			"expired-1":   {ID: "expired-1", MerchantID: "merchant-1", CurrencyCode: "BRL", Amount: 300, Status: domain.TransactionExpired},
+			"finished-1":  {ID: "finished-1", MerchantID: "merchant-1", CurrencyCode: "BRL", Amount: 100, Status: domain.TransactionFinished},
+		}}
+		quota := NewQuotaService(repository, 1000, nil)
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithQuotaService(quota))
+		merchantCtx := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1"})
+		request := item(800, "Fornecedor A")
+
+		// Act
+		reservations := quota.Reservations([]*domain.Transaction{repository.transactions["cancelled-1"], repository.transactions["expired-1"]})
+		transaction, errs := service.CreateTransaction(merchantCtx, &request)
+
+		// Assert
+		assert.Empty(t, reservations)
+		require.Empty(t, errs)
+		assert.Contains(t, repository.transactions, transaction.ID)
+	})
+
+	t.Run("Should return the quota error when a single transaction no longer fits the quota at insert time", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), concurrentUsed: map[string]float64{"BRL": 950}}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithQuotaService(NewQuotaService(repository, 1000, nil)))
+		merchantCtx := auth.WithPrincipal(ctx, &auth.Principal{MerchantID: "merchant-1"})
+		request := item(100, "Fornecedor A")
+
+		// Act
+		_, errs := service.CreateTransaction(merchantCtx, &request)
+
+		// Assert
+		require.Len(t, errs, 1)
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, errs[0], &quotaErr)
+		assert.Equal(t, 950.0, quotaErr.Used)
+		assert.Empty(t, repository.transactions)
+	})
+}
+
+func TestBatchRequestHash(t *testing.T) {
+	card := func(number, holderName string) dto.TransactionRequestDto {
+		return dto.TransactionRequestDto{Amount: 100, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Description: "Fornecedor A",
+			Card: &dto.CardRequestDto{Number: number, ExpiryMonth: 12, ExpiryYear: 2030, HolderName: holderName}}
+	}
+	pix := func(key string) dto.TransactionRequestDto {
+		return dto.TransactionRequestDto{Amount: 100, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: "Fornecedor A", PixKey: key}
+	}
+
+	tests := []struct {
+		name   string
+		first  dto.TransactionRequestDto
+		second dto.TransactionRequestDto
+	}{
+		{name: "card number with the same last digits", first: card("4111111111111111", "ANA SILVA"), second: card("4000000000001111", "ANA SILVA")},
+		{name: "card holder name", first: card("4111111111111111", "ANA SILVA"), second: card("4111111111111111", "BRUNO SOUZA")},
+		{name: "pix key", first: pix("12345678901"), second: pix("12345678991")},
+	}
+
+	for _, tt := range tests {
+		t.Run("Should tell apart batches that differ only in the "+tt.name, func(t *testing.T) {
+			// Arrange
+			firstMasked, err := json.Marshal(tt.first)
+			require.NoError(t, err)
+			secondMasked, err := json.Marshal(tt.second)
+			require.NoError(t, err)
+
+			// Act
+			firstHash, firstErr := batchRequestHash(dto.BatchModeAllOrNothing, []dto.TransactionRequestDto{tt.first})
+			secondHash, secondErr := batchRequestHash(dto.BatchModeAllOrNothing, []dto.TransactionRequestDto{tt.second})
+
+			// Assert
+			require.Equal(t, string(firstMasked), string(secondMasked), "the masked forms are the same")
+			require.NoError(t, firstErr)
+			require.NoError(t, secondErr)
+			assert.NotEqual(t, firstHash, secondHash)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetRowGroupSize limita quanto o writer de Parquet acumula antes de gravar um row group
+const parquetRowGroupSize = 16 * 1024 * 1024
+
+// exportRow são as colunas exportadas; chave Pix, payload e token do cartão ficam de fora
+type exportRow struct {
+	ID                 string  `json:"id" parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MerchantID         string  `json:"merchantId" parquet:"name=merchantId, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TenantID           string  `json:"tenantId" parquet:"name=tenantId, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Amount             float64 `json:"amount" parquet:"name=amount, type=DOUBLE"`
+	CurrencyCode       string  `json:"currencyCode" parquet:"name=currencyCode, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PaymentMethod      string  `json:"paymentMethod" parquet:"name=paymentMethod, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Status             string  `json:"status" parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Description        string  `json:"description" parquet:"name=description, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Error              string  `json:"error" parquet:"name=error, type=BYTE_ARRAY, convertedtype=UTF8"`
+	RiskScore          int32   `json:"riskScore" parquet:"name=riskScore, type=INT32"`
+	RiskDecision       string  `json:"riskDecision" parquet:"name=riskDecision, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PixTxID            string  `json:"pixTxId" parquet:"name=pixTxId, type=BYTE_ARRAY, convertedtype=UTF8"`
+	CardLast4          string  `json:"cardLast4" parquet:"name=cardLast4, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Installments       int32   `json:"installments" parquet:"name=installments, type=INT32"`
+	CapturedAmount     float64 `json:"capturedAmount" parquet:"name=capturedAmount, type=DOUBLE"`
+	GrossAmount        float64 `json:"grossAmount" parquet:"name=grossAmount, type=DOUBLE"`
+	FeeAmount          float64 `json:"feeAmount" parquet:"name=feeAmount, type=DOUBLE"`
+	NetAmount          float64 `json:"netAmount" parquet:"name=netAmount, type=DOUBLE"`
+	SettlementCurrency string  `json:"settlementCurrency" parquet:"name=settlementCurrency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SettlementAmount   float64 `json:"settlementAmount" parquet:"name=settlementAmount, type=DOUBLE"`
+	BaseCurrency       string  `json:"baseCurrency" parquet:"name=baseCurrency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BaseAmount         float64 `json:"baseAmount" parquet:"name=baseAmount, type=DOUBLE"`
+	CreatedAt          int64   `json:"-" parquet:"name=createdAt, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	UpdatedAt          int64   `json:"-" parquet:"name=updatedAt, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+var exportColumns = []string{
+	"id", "merchantId", "tenantId", "amount", "currencyCode", "paymentMethod", "status", "description", "error",
+	"riskScore", "riskDecision", "pixTxId", "cardLast4", "installments", "capturedAmount", "grossAmount", "feeAmount",
+	"netAmount", "settlementCurrency", "settlementAmount", "baseCurrency", "baseAmount", "createdAt", "updatedAt",
+}
+
+func newExportRow(t *domain.Transaction) *exportRow {
+	return &exportRow{
+		ID:                 t.ID,
+		MerchantID:         t.MerchantID,
+		TenantID:           t.TenantID,
+		Amount:             t.Amount,
+		CurrencyCode:       t.CurrencyCode,
+		PaymentMethod:      t.PaymentMethod,
+		Status:             t.Status,
+		Description:        t.Description,
+		Error:              t.ErrorMessage,
+		RiskScore:          int32(t.RiskScore),
+		RiskDecision:       t.RiskDecision,
+		PixTxID:            t.PixTxID,
+		CardLast4:          t.CardLast4,
+		Installments:       int32(t.Installments),
+		CapturedAmount:     t.CapturedAmount,
+		GrossAmount:        t.GrossAmount,
+		FeeAmount:          t.FeeAmount,
+		NetAmount:          t.NetAmount,
+		SettlementCurrency: t.SettlementCurrency,
+		SettlementAmount:   t.SettlementAmount,
+		BaseCurrency:       t.BaseCurrency,
+		BaseAmount:         t.BaseAmount,
+		CreatedAt:          t.CreatedAt.UnixMilli(),
+		UpdatedAt:          t.UpdatedAt.UnixMilli(),
+	}
+}
+
+// MarshalJSON devolve as datas em RFC 3339, como o restante da API
+func (r *exportRow) MarshalJSON() ([]byte, error) {
+	type row exportRow
+	return json.Marshal(struct {
+		*row
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}{(*row)(r), formatExportTime(r.CreatedAt), formatExportTime(r.UpdatedAt)})
+}
+
+func (r *exportRow) record() []string {
+	return []string{
+		r.ID, r.MerchantID, r.TenantID, formatExportFloat(r.Amount), r.CurrencyCode, r.PaymentMethod, r.Status,
+		r.Description, r.Error, strconv.Itoa(int(r.RiskScore)), r.RiskDecision, r.PixTxID, r.CardLast4,
+		strconv.Itoa(int(r.Installments)), formatExportFloat(r.CapturedAmount), formatExportFloat(r.GrossAmount),
+		formatExportFloat(r.FeeAmount), formatExportFloat(r.NetAmount), r.SettlementCurrency,
+		formatExportFloat(r.SettlementAmount), r.BaseCurrency, formatExportFloat(r.BaseAmount),
+		formatExportTime(r.CreatedAt), formatExportTime(r.UpdatedAt),
+	}
+}
+
+func formatExportFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatExportTime(millis int64) string {
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339Nano)
+}
+
+// exportWriter grava uma linha por vez; Close grava o que ficou pendente (rodapé do Parquet, gzip)
+type exportWriter interface {
+	Write(row *exportRow) error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case domain.ExportFormatCSV:
+		writer := &csvExportWriter{writer: csv.NewWriter(w)}
+		return writer, writer.writer.Write(exportColumns)
+	case domain.ExportFormatJSONL:
+		return &jsonlExportWriter{encoder: json.NewEncoder(w)}, nil
+	case domain.ExportFormatParquet:
+		parquetWriter, err := writer.NewParquetWriterFromWriter(w, new(exportRow), 1)
+		if err != nil {
+			return nil, err
+		}
+		parquetWriter.RowGroupSize = parquetRowGroupSize
+		parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetExportWriter{writer: parquetWriter}, nil
+	}
+	return nil, domain.ErrorInvalidExportFormat
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvExportWriter) Write(row *exportRow) error {
+	return w.writer.Write(row.record())
+}
+
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlExportWriter) Write(row *exportRow) error {
+	return w.encoder.Encode(row)
+}
+
+func (w *jsonlExportWriter) Close() error {
+	return nil
+}
+
+type parquetExportWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func (w *parquetExportWriter) Write(row *exportRow) error {
+	return w.writer.Write(row)
+}
+
+func (w *parquetExportWriter) Close() error {
+	return w.writer.WriteStop()
+}
+
+// gzipExportWriter comprime a saída de outro exportWriter e fecha o gzip depois dele
+type gzipExportWriter struct {
+	exportWriter
+	gzip *gzip.Writer
+}
+
+func (w *gzipExportWriter) Close() error {
+	if err := w.exportWriter.Close(); err != nil {
+		return err
+	}
+	return w.gzip.Close()
+}
+
+// ExportTransactions grava em w as transações visíveis para o chamador, com o mesmo escopo de
+// GET /transactions, lendo do banco por cursor. Devolve quantas linhas foram gravadas.
+func (s *TransactionService) ExportTransactions(ctx context.Context, format string, compress bool, w io.Writer) (int64, error) {
+	format, err := domain.NormalizeExportFormat(format)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := dRepo.TransactionFilter{}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	var out exportWriter
+	if compress {
+		gz := gzip.NewWriter(w)
+		inner, err := newExportWriter(format, gz)
+		if err != nil {
+			return 0, err
+		}
+		out = &gzipExportWriter{exportWriter: inner, gzip: gz}
+	} else {
+		out, err = newExportWriter(format, w)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var rows int64
+	err = s.repository.Stream(filter, func(transaction *domain.Transaction) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows++
+		return out.Write(newExportRow(transaction))
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, out.Close()
+}
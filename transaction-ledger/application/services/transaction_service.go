@@ -2,54 +2,358 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"math"
+	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/pix"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/risk"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
 	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrTransactionDeclined  = errors.New("transaction declined by risk analysis")
+	ErrCardVaultUnavailable = errors.New("card vault is not configured")
+	ErrReviewUnavailable    = errors.New("risk analysis requires a manual review but no review service is configured")
+)
+
+const ErrorCodeRiskDeclined = "RISK_DECLINED"
+
 type TransactionService struct {
 	kafkaBroker akafka.KafkaBroker
 	logger      *zap.Logger
 	repository  dRepo.TransactionRepository
+	quota       *QuotaService
+	risk        *risk.Engine
+	reviews     *ReviewService
+	fx          *FXService
+	pix         *pix.ChargeGenerator
+	vault       *cardvault.Vault
+	fingerprint *cardvault.Fingerprinter
+	installment *InstallmentService
+	fees        *FeeService
+	listeners   statusListeners
+	waiter      *StatusWaiter
+	audit       dRepo.AuditRepository
+
+	authorizationTTL time.Duration
+	batchMaxItems    int
+	pendingSweep     PendingSweepConfig
+}
+
+// TransactionServiceOption configura dependências opcionais do serviço
+type TransactionServiceOption func(*TransactionService)
+
+func WithQuotaService(quota *QuotaService) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.quota = quota
+	}
+}
+
+func WithRiskEngine(engine *risk.Engine) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.risk = engine
+	}
+}
+
+func WithReviewService(reviews *ReviewService) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.reviews = reviews
+	}
 }
 
-func NewTransactionService(kafkaBroker akafka.KafkaBroker, repository dRepo.TransactionRepository) *TransactionService {
-	return &TransactionService{kafkaBroker: kafkaBroker, logger: logger.Log, repository: repository}
+func WithFXService(fx *FXService) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.fx = fx
+	}
+}
+
+func WithPixChargeGenerator(generator *pix.ChargeGenerator) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.pix = generator
+	}
+}
+
+func WithCardVault(vault *cardvault.Vault) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.vault = vault
+	}
 }
 
-func (s *TransactionService) CreateTransaction(ctx context.Context, transactionDto *dto.TransactionRequestDto) (domain.Transaction, []error) {
-	transaction, errs := domain.NewTransaction(transactionDto.Amount, transactionDto.PaymentMethod, transactionDto.CurrencyCode, transactionDto.Description)
+// WithCardFingerprinter grava a impressão do cartão na transação, para as regras de velocidade por cartão
+func WithCardFingerprinter(fingerprinter *cardvault.Fingerprinter) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.fingerprint = fingerprinter
+	}
+}
+
+func WithInstallmentService(installments *InstallmentService) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.installment = installments
+	}
+}
+
+func WithFeeService(fees *FeeService) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.fees = fees
+	}
+}
+
+// WithStatusListeners registra quem deve ser avisado a cada mudança de status (webhooks, streams...)
+func WithStatusListeners(listeners ...StatusListener) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.listeners = append(s.listeners, listeners...)
+	}
+}
+
+// WithStatusWaiter habilita o long-poll em WaitForStatus
+func WithStatusWaiter(waiter *StatusWaiter) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.waiter = waiter
+	}
+}
+
+// WithAuthorizationTTL define por quanto tempo uma autorização sem captura segura o valor no cartão
+func WithAuthorizationTTL(ttl time.Duration) TransactionServiceOption {
+	return func(s *TransactionService) {
+		s.authorizationTTL = ttl
+	}
+}
+
+func NewTransactionService(kafkaBroker akafka.KafkaBroker, repository dRepo.TransactionRepository, opts ...TransactionServiceOption) *TransactionService {
+	service := &TransactionService{kafkaBroker: kafkaBroker, logger: logger.Log, repository: repository, authorizationTTL: 7 * 24 * time.Hour, batchMaxItems: 1000, pendingSweep: PendingSweepConfig{After: 5 * time.Minute, MaxAttempts: 3, BatchSize: 100}}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
+}
+
+// preparedTransaction é uma transação já validada, convertida e avaliada, pronta para ser gravada
+type preparedTransaction struct {
+	transaction *domain.Transaction
+	card        *cardvault.Card
+	// vaulted é o cartão já cifrado, gravado junto com a transação
+	vaulted  *cardvault.VaultedCard
+	schedule []domain.Installment
+}
+
+func (s *TransactionService) CreateTransaction(ctx context.Context, transactionDto *dto.TransactionRequestDto) (*domain.Transaction, []error) {
+	prepared, errs := s.prepareTransaction(ctx, transactionDto, 0)
 	if len(errs) > 0 {
-		return domain.Transaction{}, errs
+		return nil, errs
+	}
+	transaction := prepared.transaction
+
+	if errs := s.sealCard(ctx, prepared); len(errs) > 0 {
+		return nil, errs
 	}
 
 	jsonData, err := transaction.ToJson()
 	if err != nil {
-		return domain.Transaction{}, []error{err}
+		return nil, []error{err}
 	}
 
-	err = s.repository.Create(transaction)
-	if err != nil {
-		return domain.Transaction{}, []error{err}
+	// as parcelas são gravadas junto: não fica transação parcelada sem o cronograma
+	creation := &dRepo.TransactionCreation{Transactions: []*domain.Transaction{transaction}, Installments: prepared.schedule}
+	if prepared.vaulted != nil {
+		creation.Cards = []*cardvault.VaultedCard{prepared.vaulted}
+	}
+	if transaction.RiskDecision == domain.RiskDecisionReview {
+		// a revisão é gravada junto: sem ela a transação ficaria em REVIEW sem ninguém para decidir
+		creation.Reviews = []*domain.Review{s.reviews.Prepare(transaction)}
+	}
+	s.reserveQuotas(creation)
+	if err := s.repository.CreateBatch(creation); err != nil {
+		return nil, []error{s.quotaError(err)}
+	}
+	for _, review := range creation.Reviews {
+		s.reviews.Opened(review)
+	}
+
+	s.listeners.notify(ctx, transaction, "")
+
+	if publish, err := s.routeByRisk(transaction); !publish {
+		if err != nil {
+			return transaction, []error{err}
+		}
+		return transaction, nil
 	}
 
-	if err := s.kafkaBroker.Publish("process-transaction", jsonData); err != nil {
+	if err := s.kafkaBroker.Publish(transaction.ProcessingTopic(), jsonData); err != nil {
 		s.logger.Error("erro ao publicar no Kafka",
 			zap.Error(err),
 		)
-		return domain.Transaction{}, []error{err}
+		return nil, []error{err}
 	}
 
 	s.logger.Info("transação publicada com sucesso",
-		zap.Any("transaction", transactionDto),
+		zap.Any("transaction", transaction),
 	)
 
-	return *transaction, nil
+	return transaction, nil
+}
+
+// prepareTransaction executa todas as validações e enriquecimentos que não gravam nada.
+// pendingAmount é o valor de transações do mesmo lote ainda não gravadas, para a checagem de quota.
+func (s *TransactionService) prepareTransaction(ctx context.Context, transactionDto *dto.TransactionRequestDto, pendingAmount float64) (*preparedTransaction, []error) {
+	transaction, errs := domain.NewTransaction(transactionDto.Amount, transactionDto.PaymentMethod, transactionDto.CurrencyCode, transactionDto.Description, transactionDto.Options()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var card *cardvault.Card
+	if transactionDto.Card != nil {
+		if !transaction.IsCardPayment() {
+			return nil, []error{domain.ErrorCardNotAllowed}
+		}
+		if s.vault == nil {
+			return nil, []error{ErrCardVaultUnavailable}
+		}
+		parsed := transactionDto.Card.ToCard()
+		if errs := parsed.Validate(time.Now()); len(errs) > 0 {
+			return nil, errs
+		}
+		card = &parsed
+		if s.fingerprint != nil {
+			transaction.CardFingerprint = s.fingerprint.Fingerprint(parsed)
+		}
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		transaction.MerchantID = principal.MerchantID
+		transaction.TenantID = principal.TenantID
+	}
+
+	if err := s.applyConversions(transaction, transactionDto.SettlementCurrency); err != nil {
+		return nil, []error{err}
+	}
+
+	if s.quota != nil {
+		if err := s.quota.CheckWithPending(ctx, transaction, pendingAmount); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	var schedule []domain.Installment
+	if s.installment != nil && transaction.Installments > 1 {
+		planned, err := s.installment.Plan(transaction)
+		if err != nil {
+			return nil, []error{err}
+		}
+		schedule = planned
+	}
+
+	if s.pix != nil && transaction.PaymentMethod == domain.PaymentMethodPIX {
+		if err := s.pix.Attach(transaction); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if s.risk != nil {
+		assessment, err := s.risk.Evaluate(ctx, transaction)
+		if err != nil {
+			return nil, []error{err}
+		}
+		if assessment.Decision == domain.RiskDecisionReview && s.reviews == nil {
+			return nil, []error{ErrReviewUnavailable}
+		}
+		transaction.ApplyRiskAssessment(assessment.Score, assessment.Decision, assessment.Reasons)
+	}
+
+	return &preparedTransaction{transaction: transaction, card: card, schedule: schedule}, nil
+}
+
+// reserveQuotas pede ao repositório que confira a quota na mesma transação do banco que grava a criação;
+// a checagem de prepareTransaction pode ter visto a soma antes de outra criação concorrente gravar
+func (s *TransactionService) reserveQuotas(creation *dRepo.TransactionCreation) {
+	if s.quota != nil {
+		creation.Quotas = s.quota.Reservations(creation.Transactions)
+	}
+}
+
+// quotaError traduz a reserva recusada pelo repositório em *QuotaExceededError; outros erros passam iguais
+func (s *TransactionService) quotaError(err error) error {
+	var reservationErr *dRepo.QuotaReservationError
+	if s.quota != nil && errors.As(err, &reservationErr) {
+		return s.quota.Exceeded(reservationErr)
+	}
+	return err
+}
+
+// sealCard cifra o cartão e anexa o token à transação sem gravar nada no cofre: o cartão é gravado na mesma
+// transação do banco que a criação, então uma reserva de quota recusada não deixa cartão órfão
+func (s *TransactionService) sealCard(ctx context.Context, prepared *preparedTransaction) []error {
+	if prepared.card == nil {
+		return nil
+	}
+	vaulted, errs := s.vault.Seal(ctx, *prepared.card)
+	if len(errs) > 0 {
+		return errs
+	}
+	prepared.vaulted = vaulted
+	prepared.transaction.AttachCard(vaulted.Token, vaulted.Last4)
+	return nil
+}
+
+// routeByRisk decide, após a gravação, se a transação segue para o processamento.
+// Recusadas devolvem ErrTransactionDeclined; as em revisão aguardam a decisão manual.
+func (s *TransactionService) routeByRisk(transaction *domain.Transaction) (bool, error) {
+	switch transaction.RiskDecision {
+	case domain.RiskDecisionDecline:
+		s.logger.Warn("transação recusada pela análise de risco",
+			zap.String("id", transaction.ID),
+			zap.Int("riskScore", transaction.RiskScore),
+			zap.String("reasons", transaction.RiskReasons),
+		)
+		return false, ErrTransactionDeclined
+	case domain.RiskDecisionReview:
+		// fica aguardando revisão manual, não segue para o processamento
+		return false, nil
+	}
+	return true, nil
+}
+
+// applyConversions converte o valor para a moeda de liquidação (quando informada) e para a moeda base
+func (s *TransactionService) applyConversions(transaction *domain.Transaction, settlementCurrency string) error {
+	if settlementCurrency != "" && !domain.Currencies.IsEnabled(settlementCurrency) {
+		return domain.ErrorInvalidCurrencyCode
+	}
+
+	if s.fx == nil {
+		if settlementCurrency != "" && settlementCurrency != transaction.CurrencyCode {
+			return domain.ErrorFXRateUnavailable
+		}
+		return nil
+	}
+
+	if settlementCurrency != "" {
+		conversion, err := s.fx.Convert(transaction.Amount, transaction.CurrencyCode, settlementCurrency)
+		if err != nil {
+			return err
+		}
+		transaction.ApplySettlementConversion(conversion.To, conversion.Amount, conversion.Rate, conversion.RateAt)
+	}
+
+	conversion, err := s.fx.Convert(transaction.Amount, transaction.CurrencyCode, s.fx.BaseCurrency())
+	if err != nil {
+		// sem cotação para a moeda base a transação segue sem o valor consolidado
+		s.logger.Warn("cotação para moeda base indisponível",
+			zap.Error(err),
+			zap.String("currency", transaction.CurrencyCode),
+		)
+		return nil
+	}
+	transaction.ApplyBaseConversion(conversion.To, conversion.Amount, conversion.Rate, conversion.RateAt)
+	return nil
 }
 
 func (s *TransactionService) UpdateTransaction(ctx context.Context, transaction *domain.Transaction) error {
@@ -64,8 +368,364 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, transaction
 	return err
 }
 
+// Capture pede a captura total (amount zero) ou parcial de uma autorização de cartão de crédito
+func (s *TransactionService) Capture(ctx context.Context, id string, amount float64) (*domain.Transaction, error) {
+	return s.requestOperation(ctx, id, func(transaction *domain.Transaction) error {
+		return transaction.RequestCapture(amount, time.Now())
+	})
+}
+
+// Void libera a reserva de uma autorização ainda não capturada
+func (s *TransactionService) Void(ctx context.Context, id string) (*domain.Transaction, error) {
+	return s.requestOperation(ctx, id, func(transaction *domain.Transaction) error {
+		return transaction.RequestVoid()
+	})
+}
+
+// Cancel pede o cancelamento de uma transação PENDING. Ela continua PENDING até o processamento
+// responder: CANCELLED se o pedido chegou antes do fim do processamento, ou o resultado normal se não.
+func (s *TransactionService) Cancel(ctx context.Context, id string) (*domain.Transaction, error) {
+	transaction, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transaction.RequestCancel(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateIfStatus(transaction, domain.TransactionPending); err != nil {
+		return nil, err
+	}
+
+	actor := domain.AuditActorAPI
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+		actor = principal.Subject
+	}
+	s.recordAudit(domain.NewTransactionAuditEntry(transaction.ID, domain.AuditActionCancellationRequested, actor, nil))
+
+	jsonData, err := json.Marshal(dto.CancelTransactionDto{TransactionID: transaction.ID})
+	if err == nil {
+		err = s.kafkaBroker.Publish(domain.CancelTransactionTopic, jsonData)
+	}
+	if err != nil {
+		// o pedido já está gravado e segue nas republicações do varredor de PENDING
+		s.logger.Error("erro ao publicar pedido de cancelamento",
+			zap.Error(err),
+			zap.String("id", transaction.ID),
+		)
+	}
+
+	s.logger.Info("cancelamento solicitado",
+		zap.String("id", transaction.ID),
+	)
+	return transaction, nil
+}
+
+// requestOperation aplica a transição, grava condicionada ao status anterior e publica a etapa para o processamento
+func (s *TransactionService) requestOperation(ctx context.Context, id string, transition func(*domain.Transaction) error) (*domain.Transaction, error) {
+	transaction, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := transaction.Status
+	if err := transition(transaction); err != nil {
+		return nil, err
+	}
+	if err := s.repository.UpdateIfStatus(transaction, previousStatus); err != nil {
+		return nil, err
+	}
+	s.listeners.notify(ctx, transaction, previousStatus)
+
+	jsonData, err := transaction.ToJson()
+	if err == nil {
+		err = s.kafkaBroker.Publish(transaction.ProcessingTopic(), jsonData)
+	}
+	if err != nil {
+		s.logger.Error("erro ao publicar no Kafka",
+			zap.Error(err),
+			zap.String("id", transaction.ID),
+			zap.String("operation", transaction.ProcessingOperation()),
+		)
+		s.revertOperation(ctx, transaction)
+		return nil, err
+	}
+
+	s.logger.Info("etapa da autorização publicada",
+		zap.String("id", transaction.ID),
+		zap.String("operation", transaction.ProcessingOperation()),
+	)
+	return transaction, nil
+}
+
+// ApplyProcessingResult aplica na transação o retorno do transaction-processment para a etapa executada
+func (s *TransactionService) ApplyProcessingResult(ctx context.Context, result dto.ProcessTransactionDto) error {
+	transaction, err := s.FindByID(ctx, result.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	if transaction.SettlementLocked {
+		s.logger.Warn("resultado recebido para transação travada na liquidação",
+			zap.String("id", transaction.ID),
+			zap.String("status", result.Status),
+			zap.Stringp("settlementBatchId", transaction.SettlementBatchID),
+		)
+		return nil
+	}
+	if !transaction.AcceptsResult(result.Operation) {
+		// retorno repetido (republicação do varredor) ou de uma etapa que já foi resolvida
+		s.logger.Warn("resultado descartado: transação não aguarda essa etapa",
+			zap.String("id", transaction.ID),
+			zap.String("transactionStatus", transaction.Status),
+			zap.String("operation", result.Operation),
+			zap.String("status", result.Status),
+		)
+		return nil
+	}
+
+	previousStatus := transaction.Status
+	abandoned := transaction.ProcessingAbandoned()
+	switch result.Status {
+	case dto.TransactionStatusProcessed:
+		transaction.TransactionProcessed()
+	case dto.TransactionStatusAuthorized:
+		transaction.Authorized(time.Now().Add(s.authorizationTTL))
+	case dto.TransactionStatusCaptured:
+		transaction.Captured()
+	case dto.TransactionStatusVoided:
+		transaction.Voided()
+	case dto.TransactionStatusCancelled:
+		transaction.Cancelled()
+	default:
+		transaction.OperationFailed(result.Operation, result.ErrorMessage)
+	}
+	if abandoned && transaction.Status != domain.TransactionFailed {
+		transaction.ErrorMessage = ""
+	}
+
+	if transaction.Status == domain.TransactionFinished && s.fees != nil {
+		if err := s.fees.Apply(ctx, transaction); err != nil {
+			// a transação é finalizada mesmo assim; as taxas podem ser recalculadas depois
+			s.logger.Error("erro ao calcular taxas da transação",
+				zap.Error(err),
+				zap.String("id", transaction.ID),
+			)
+		}
+	}
+	var schedule []domain.Installment
+	if transaction.Status == domain.TransactionFinished && result.Operation == domain.OperationCapture && s.installment != nil {
+		// uma captura parcial parcela só o valor capturado
+		schedule = s.installment.Replan(transaction)
+	}
+	transaction.Mu.Lock()
+	if schedule != nil {
+		err = s.repository.UpdateIfStatusWithSchedule(transaction, previousStatus, schedule)
+	} else {
+		err = s.repository.UpdateIfStatus(transaction, previousStatus)
+	}
+	transaction.Mu.Unlock()
+	if errors.Is(err, domain.ErrorTransactionStatusChanged) {
+		// outro retorno (ou o varredor) mudou a transação entre a leitura e a gravação e prevalece
+		s.logger.Warn("resultado descartado: transação alterada concorrentemente",
+			zap.String("id", transaction.ID),
+			zap.String("operation", result.Operation),
+			zap.String("status", result.Status),
+		)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if abandoned {
+		// o processamento respondeu depois do varredor desistir; o retorno do processador prevalece
+		s.recordAudit(domain.NewTransactionAuditEntry(transaction.ID, domain.AuditActionLateProcessingResult, domain.AuditActorProcessor, map[string]string{
+			"operation": result.Operation,
+			"status":    result.Status,
+			"newStatus": transaction.Status,
+		}))
+	}
+	if transaction.CancelRequestedAt != nil && previousStatus == domain.TransactionPending && transaction.Status != domain.TransactionCancelled {
+		// o processamento terminou antes de ver o pedido; o resultado dele prevalece
+		s.recordAudit(domain.NewTransactionAuditEntry(transaction.ID, domain.AuditActionCancellationRejected, domain.AuditActorProcessor, map[string]string{
+			"status":    result.Status,
+			"newStatus": transaction.Status,
+		}))
+	}
+	s.listeners.notify(ctx, transaction, previousStatus)
+	return nil
+}
+
+// FindInstallments devolve o cronograma de parcelas de uma transação visível para o principal
+func (s *TransactionService) FindInstallments(ctx context.Context, id string) (*domain.Transaction, []domain.Installment, error) {
+	transaction, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.installment == nil {
+		return transaction, nil, nil
+	}
+
+	schedule, err := s.installment.FindByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transaction, schedule, nil
+}
+
+// ExpirePendingCharges move para EXPIRED as cobranças pendentes e as autorizações que passaram do prazo
+func (s *TransactionService) ExpirePendingCharges(ctx context.Context) (int, error) {
+	now := time.Now()
+	transactions, err := s.repository.FindExpired(now, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range transactions {
+		transaction := &transactions[i]
+		previousStatus := transaction.Status
+		if !transaction.Expire(now) {
+			continue
+		}
+
+		transaction.Mu.Lock()
+		err := s.repository.UpdateIfStatus(transaction, previousStatus)
+		transaction.Mu.Unlock()
+		if errors.Is(err, domain.ErrorTransactionStatusChanged) {
+			// um retorno do processamento ou uma captura chegou antes e prevalece
+			continue
+		}
+		if err != nil {
+			s.logger.Error("erro ao expirar transação",
+				zap.Error(err),
+				zap.String("id", transaction.ID),
+			)
+			continue
+		}
+		s.listeners.notify(ctx, transaction, previousStatus)
+		expired++
+
+		if transaction.Status == domain.TransactionVoidPending {
+			s.publishExpiredVoid(ctx, transaction)
+		}
+	}
+
+	return expired, nil
+}
+
+// publishExpiredVoid pede ao processamento a liberação da reserva de uma autorização expirada; se a
+// publicação falhar, a autorização volta a AUTHORIZED e a próxima passada tenta de novo
+func (s *TransactionService) publishExpiredVoid(ctx context.Context, transaction *domain.Transaction) {
+	jsonData, err := transaction.ToJson()
+	if err == nil {
+		err = s.kafkaBroker.Publish(transaction.ProcessingTopic(), jsonData)
+	}
+	if err != nil {
+		s.logger.Error("erro ao publicar liberação da autorização expirada",
+			zap.Error(err),
+			zap.String("id", transaction.ID),
+		)
+		s.revertOperation(ctx, transaction)
+		return
+	}
+
+	s.logger.Info("liberação da autorização expirada publicada",
+		zap.String("id", transaction.ID),
+	)
+}
+
+// revertOperation devolve a AUTHORIZED a captura ou o cancelamento que não chegou ao Kafka, para que não
+// fique em CAPTURE_PENDING/VOID_PENDING sem nada a caminho do processamento. Se nem a volta for gravada, o
+// varredor de transações presas republica a etapa.
+func (s *TransactionService) revertOperation(ctx context.Context, transaction *domain.Transaction) {
+	pendingStatus := transaction.Status
+	transaction.OperationFailed(transaction.ProcessingOperation(), domain.ErrorMessagePublishFailed)
+	if err := s.repository.UpdateIfStatus(transaction, pendingStatus); err != nil {
+		if !errors.Is(err, domain.ErrorTransactionStatusChanged) {
+			s.logger.Error("erro ao desfazer etapa não publicada",
+				zap.Error(err),
+				zap.String("id", transaction.ID),
+			)
+		}
+		return
+	}
+	s.listeners.notify(ctx, transaction, pendingStatus)
+}
+
+func (s *TransactionService) StartExpirationWatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpirePendingCharges(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar cobranças expiradas",
+					zap.Error(err),
+				)
+				continue
+			}
+			if expired > 0 {
+				s.logger.Info("cobranças expiradas",
+					zap.Int("count", expired),
+				)
+			}
+		}
+	}
+}
+
+// FindByID busca a transação; quando há um principal no contexto, só retorna transações do mesmo merchant e tenant
 func (s *TransactionService) FindByID(ctx context.Context, id string) (*domain.Transaction, error) {
-	return s.repository.FindByID(id)
+	transaction, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(transaction.MerchantID, transaction.TenantID) {
+		return nil, ErrTransactionNotFound
+	}
+
+	return transaction, nil
+}
+
+// WaitForStatus espera até timeout (limitado pelo StatusWaiter) a transação sair do processamento.
+// A espera é acordada pelo consumidor de retornos, sem consultar o banco em intervalos.
+func (s *TransactionService) WaitForStatus(ctx context.Context, id string, timeout time.Duration) (*domain.Transaction, error) {
+	if s.waiter == nil || timeout <= 0 {
+		return s.FindByID(ctx, id)
+	}
+
+	timer := time.NewTimer(min(timeout, s.waiter.maxWait))
+	defer timer.Stop()
+
+	for {
+		// inscreve antes de ler, para não perder um retorno aplicado entre a leitura e a espera
+		notified, cancel, err := s.waiter.Register(id)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction, err := s.FindByID(ctx, id)
+		if err != nil || !transaction.AwaitingProcessing() {
+			cancel()
+			return transaction, err
+		}
+
+		select {
+		case <-notified:
+			cancel()
+		case <-timer.C:
+			cancel()
+			return transaction, nil
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
 }
 
 func (s *TransactionService) FindPaginated(ctx context.Context, page, pageSize int) (*dto.PaginatedTransactionsResponseDto, error) {
@@ -76,7 +736,13 @@ func (s *TransactionService) FindPaginated(ctx context.Context, page, pageSize i
 		pageSize = 50
 	}
 
-	transactions, total, err := s.repository.FindPaginated(page, pageSize)
+	filter := dRepo.TransactionFilter{}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	transactions, total, err := s.repository.FindPaginated(filter, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/risk"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionService_CreateTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	engine := func(t *testing.T) *risk.Engine {
+		ruleSet, err := risk.ParseRuleSet([]byte(batchRiskRules))
+		require.NoError(t, err)
+		return risk.NewEngine(ruleSet, nil)
+	}
+	flagged := dto.TransactionRequestDto{Amount: 5000, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: "Fornecedor A"}
+
+	t.Run("Should store the review in the same write as the transaction and not publish it", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository,
+			WithRiskEngine(engine(t)),
+			WithReviewService(NewReviewService(broker, nil, repository, time.Hour)),
+		)
+		request := flagged
+
+		// Act
+		transaction, errs := service.CreateTransaction(ctx, &request)
+
+		// Assert
+		require.Empty(t, errs)
+		assert.Equal(t, domain.TransactionReview, transaction.Status)
+		assert.Equal(t, 1, repository.batches)
+		require.Len(t, repository.reviews, 1)
+		assert.Equal(t, transaction.ID, repository.reviews[0].TransactionID)
+		assert.Empty(t, broker.published)
+	})
+
+	t.Run("Should store neither the transaction nor the review when the write fails", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), failOnBatch: 1}
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository,
+			WithRiskEngine(engine(t)),
+			WithReviewService(NewReviewService(broker, nil, repository, time.Hour)),
+		)
+		request := flagged
+
+		// Act
+		_, errs := service.CreateTransaction(ctx, &request)
+
+		// Assert
+		require.Len(t, errs, 1)
+		assert.Empty(t, repository.transactions)
+		assert.Empty(t, repository.reviews)
+		assert.Empty(t, broker.published)
+	})
+
+	t.Run("Should store the installment schedule in the same write as the transaction", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository,
+			WithInstallmentService(NewInstallmentService(nil, InstallmentPolicy{MaxInstallments: 12, InterestFreeInstallments: 12}, nil)),
+		)
+		request := dto.TransactionRequestDto{Amount: 300, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Description: "Parcelado", Installments: 3}
+
+		// Act
+		transaction, errs := service.CreateTransaction(ctx, &request)
+
+		// Assert
+		require.Empty(t, errs)
+		assert.Equal(t, 1, repository.batches)
+		require.Len(t, repository.installments, 3)
+		assert.Equal(t, transaction.ID, repository.installments[0].TransactionID)
+		assert.Len(t, broker.published, 1)
+	})
+
+	t.Run("Should store neither the transaction nor the schedule when the write fails", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction), failOnBatch: 1}
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository,
+			WithInstallmentService(NewInstallmentService(nil, InstallmentPolicy{MaxInstallments: 12, InterestFreeInstallments: 12}, nil)),
+		)
+		request := dto.TransactionRequestDto{Amount: 300, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Description: "Parcelado", Installments: 3}
+
+		// Act
+		_, errs := service.CreateTransaction(ctx, &request)
+
+		// Assert
+		require.Len(t, errs, 1)
+		assert.Empty(t, repository.transactions)
+		assert.Empty(t, repository.installments)
+		assert.Empty(t, broker.published)
+	})
+
+	t.Run("Should refuse a transaction sent to review when no review service is configured", func(t *testing.T) {
+		// Arrange
+		repository := &memoryTransactionRepository{transactions: make(map[string]*domain.Transaction)}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithRiskEngine(engine(t)))
+		request := flagged
+
+		// Act
+		_, errs := service.CreateTransaction(ctx, &request)
+
+		// Assert
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrReviewUnavailable)
+		assert.Zero(t, repository.batches)
+	})
+}
+
+func TestTransactionService_FindByID(t *testing.T) {
+	repository := &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+		"tx-1": {ID: "tx-1", MerchantID: "merchant-1", TenantID: "tenant-a", Status: domain.TransactionPending},
+	}}
+	service := NewTransactionService(&recordingKafkaBroker{}, repository)
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		found     bool
+	}{
+		{name: "Should return the transaction to its merchant and tenant", principal: &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-a"}, found: true},
+		{name: "Should hide the transaction from another tenant of the same merchant", principal: &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-b"}},
+		{name: "Should hide the transaction from another merchant", principal: &auth.Principal{MerchantID: "merchant-2", TenantID: "tenant-a"}},
+		{name: "Should check only the tenant when the principal has no merchant", principal: &auth.Principal{TenantID: "tenant-a"}, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := auth.WithPrincipal(context.Background(), tt.principal)
+
+			// Act
+			transaction, err := service.FindByID(ctx, "tx-1")
+
+			// Assert
+			if !tt.found {
+				assert.ErrorIs(t, err, ErrTransactionNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "tx-1", transaction.ID)
+		})
+	}
+}
+
+func TestTransactionService_Cancel(t *testing.T) {
+	ctx := context.Background()
+
+	newPending := func() *stuckTransactionRepository {
+		return &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"tx-1": {ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX, UpdatedAt: time.Now()},
+		}}
+	}
+
+	t.Run("Should record the request and publish it to the processor", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		broker := &recordingKafkaBroker{}
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(broker, repository, WithAuditRepository(audit))
+
+		// Act
+		transaction, err := service.Cancel(ctx, "tx-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionPending, transaction.Status)
+		assert.NotNil(t, repository.transactions["tx-1"].CancelRequestedAt)
+
+		require.Len(t, broker.published, 1)
+		assert.Equal(t, domain.CancelTransactionTopic, broker.published[0].Topic)
+		var message dto.CancelTransactionDto
+		require.NoError(t, json.Unmarshal(broker.published[0].Value, &message))
+		assert.Equal(t, "tx-1", message.TransactionID)
+
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, domain.AuditActionCancellationRequested, audit.entries[0].Action)
+		assert.Equal(t, domain.AuditActorAPI, audit.entries[0].Actor)
+	})
+
+	t.Run("Should reject transactions that already left PENDING", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		repository.transactions["tx-1"].Status = domain.TransactionFinished
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository)
+
+		// Act
+		_, err := service.Cancel(ctx, "tx-1")
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrorTransactionNotCancellable)
+		assert.Empty(t, broker.published)
+	})
+
+	t.Run("Should apply the CANCELLED result from the processor", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		service := NewTransactionService(&recordingKafkaBroker{}, repository)
+		_, err := service.Cancel(ctx, "tx-1")
+		require.NoError(t, err)
+
+		// Act
+		err = service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusCancelled})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionCancelled, repository.transactions["tx-1"].Status)
+	})
+
+	t.Run("Should keep the processing result and audit the rejected cancellation when completion wins", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		audit := &memoryAuditRepository{}
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithAuditRepository(audit))
+		_, err := service.Cancel(ctx, "tx-1")
+		require.NoError(t, err)
+
+		// Act
+		err = service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusProcessed})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionFinished, repository.transactions["tx-1"].Status)
+		require.Len(t, audit.entries, 2)
+		assert.Equal(t, domain.AuditActionCancellationRejected, audit.entries[1].Action)
+		assert.Equal(t, domain.TransactionFinished, audit.entries[1].Details["newStatus"])
+	})
+}
+
+func TestTransactionService_ApplyProcessingResult(t *testing.T) {
+	ctx := context.Background()
+
+	newPending := func() *stuckTransactionRepository {
+		return &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"tx-1": {ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX, UpdatedAt: time.Now()},
+		}}
+	}
+
+	t.Run("Should apply a result only once when the processor delivers it twice", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		service := NewTransactionService(&recordingKafkaBroker{}, repository)
+		processed := dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusProcessed}
+		failed := dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusFailed, ErrorMessage: "saldo insuficiente"}
+
+		// Act
+		firstErr := service.ApplyProcessingResult(ctx, processed)
+		repeatedErr := service.ApplyProcessingResult(ctx, processed)
+		lateErr := service.ApplyProcessingResult(ctx, failed)
+
+		// Assert
+		require.NoError(t, firstErr)
+		require.NoError(t, repeatedErr)
+		require.NoError(t, lateErr)
+		assert.Equal(t, domain.TransactionFinished, repository.transactions["tx-1"].Status)
+		assert.Empty(t, repository.transactions["tx-1"].ErrorMessage)
+	})
+
+	t.Run("Should drop results for an operation the transaction is not waiting for", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		service := NewTransactionService(&recordingKafkaBroker{}, repository)
+
+		// Act
+		err := service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationCapture, Status: dto.TransactionStatusFailed})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionPending, repository.transactions["tx-1"].Status)
+	})
+
+	t.Run("Should keep the concurrent change when the status moved before the write", func(t *testing.T) {
+		// Arrange
+		repository := newPending()
+		repository.statusChanged = true
+		service := NewTransactionService(&recordingKafkaBroker{}, repository)
+
+		// Act
+		err := service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "tx-1", Operation: domain.OperationSale, Status: dto.TransactionStatusFailed})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionPending, repository.transactions["tx-1"].Status)
+	})
+}
+
+func TestTransactionService_ExpirePendingCharges(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+
+	newExpired := func() *stuckTransactionRepository {
+		return &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"pix-1":  {ID: "pix-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX, ExpiresAt: &past},
+			"card-1": {ID: "card-1", Status: domain.TransactionAuthorized, PaymentMethod: domain.PaymentMethodCreditCard, ExpiresAt: &past},
+		}}
+	}
+
+	t.Run("Should expire pending charges and publish a VOID for expired authorizations", func(t *testing.T) {
+		// Arrange
+		repository := newExpired()
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository)
+
+		// Act
+		expired, err := service.ExpirePendingCharges(ctx)
+		voidErr := service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "card-1", Operation: domain.OperationVoid, Status: dto.TransactionStatusVoided})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, expired)
+		assert.Equal(t, domain.TransactionExpired, repository.transactions["pix-1"].Status)
+
+		require.Len(t, broker.published, 1)
+		var message domain.Transaction
+		require.NoError(t, json.Unmarshal(broker.published[0].Value, &message))
+		assert.Equal(t, "card-1", message.ID)
+		assert.Equal(t, domain.OperationVoid, message.ProcessingOperation())
+
+		require.NoError(t, voidErr)
+		assert.Equal(t, domain.TransactionExpired, repository.transactions["card-1"].Status)
+	})
+
+	t.Run("Should try the VOID again on the next sweep when the processor refuses it", func(t *testing.T) {
+		// Arrange
+		repository := newExpired()
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository)
+		_, err := service.ExpirePendingCharges(ctx)
+		require.NoError(t, err)
+
+		// Act
+		failErr := service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "card-1", Operation: domain.OperationVoid, Status: dto.TransactionStatusFailed, ErrorMessage: "adquirente indisponível"})
+		expired, err := service.ExpirePendingCharges(ctx)
+
+		// Assert
+		require.NoError(t, failErr)
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Equal(t, domain.TransactionVoidPending, repository.transactions["card-1"].Status)
+		assert.Len(t, broker.published, 2)
+	})
+
+	t.Run("Should return the authorization to AUTHORIZED when the VOID can't be published", func(t *testing.T) {
+		// Arrange
+		repository := newExpired()
+		service := NewTransactionService(failingKafkaBroker{}, repository)
+
+		// Act
+		expired, err := service.ExpirePendingCharges(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, expired)
+		assert.Equal(t, domain.TransactionAuthorized, repository.transactions["card-1"].Status)
+		assert.Equal(t, domain.ErrorMessagePublishFailed, repository.transactions["card-1"].ErrorMessage)
+	})
+
+	t.Run("Should leave alone a transaction changed after it was read", func(t *testing.T) {
+		// Arrange
+		repository := newExpired()
+		repository.statusChanged = true
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository)
+
+		// Act
+		expired, err := service.ExpirePendingCharges(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, expired)
+		assert.Equal(t, domain.TransactionAuthorized, repository.transactions["card-1"].Status)
+		assert.Empty(t, broker.published)
+	})
+}
+
+func TestTransactionService_Capture(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+
+	newAuthorized := func() *stuckTransactionRepository {
+		return &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"card-1": {ID: "card-1", Status: domain.TransactionAuthorized, Amount: 100, AuthorizationOnly: true, PaymentMethod: domain.PaymentMethodCreditCard, ExpiresAt: &future},
+		}}
+	}
+
+	t.Run("Should publish the capture and leave the transaction in CAPTURE_PENDING", func(t *testing.T) {
+		// Arrange
+		repository := newAuthorized()
+		broker := &recordingKafkaBroker{}
+		service := NewTransactionService(broker, repository)
+
+		// Act
+		transaction, err := service.Capture(ctx, "card-1", 40)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionCapturePending, transaction.Status)
+		assert.Equal(t, domain.TransactionCapturePending, repository.transactions["card-1"].Status)
+		assert.Equal(t, 40.0, repository.transactions["card-1"].CapturedAmount)
+		require.Len(t, broker.published, 1)
+	})
+
+	t.Run("Should return the transaction to AUTHORIZED when the capture can't be published", func(t *testing.T) {
+		// Arrange
+		repository := newAuthorized()
+		service := NewTransactionService(failingKafkaBroker{}, repository)
+
+		// Act
+		transaction, err := service.Capture(ctx, "card-1", 40)
+
+		// Assert
+		require.Error(t, err)
+		assert.Nil(t, transaction)
+		assert.Equal(t, domain.TransactionAuthorized, repository.transactions["card-1"].Status)
+		assert.Zero(t, repository.transactions["card-1"].CapturedAmount)
+		assert.Equal(t, domain.ErrorMessagePublishFailed, repository.transactions["card-1"].ErrorMessage)
+	})
+
+	t.Run("Should recompute the installment schedule from the captured amount of a partial capture", func(t *testing.T) {
+		// Arrange
+		createdAt := time.Date(2026, time.January, 31, 10, 0, 0, 0, time.UTC)
+		repository := &stuckTransactionRepository{transactions: map[string]*domain.Transaction{
+			"card-1": {ID: "card-1", Status: domain.TransactionCapturePending, Amount: 300, CapturedAmount: 100, CurrencyCode: "BRL", AuthorizationOnly: true,
+				PaymentMethod: domain.PaymentMethodCreditCard, Installments: 3, InstallmentPlan: domain.InstallmentPlanInterestFree, InstallmentsTotal: 300, CreatedAt: createdAt},
+		}}
+		installments := NewInstallmentService(nil, InstallmentPolicy{MaxInstallments: 12, InterestFreeInstallments: 12}, nil)
+		service := NewTransactionService(&recordingKafkaBroker{}, repository, WithInstallmentService(installments))
+
+		// Act
+		err := service.ApplyProcessingResult(ctx, dto.ProcessTransactionDto{TransactionID: "card-1", Operation: domain.OperationCapture, Status: dto.TransactionStatusCaptured})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionFinished, repository.transactions["card-1"].Status)
+		assert.Equal(t, 100.0, repository.transactions["card-1"].InstallmentsTotal)
+		schedule := repository.installments["card-1"]
+		require.Len(t, schedule, 3)
+		assert.Equal(t, []float64{33.34, 33.33, 33.33}, []float64{schedule[0].Amount, schedule[1].Amount, schedule[2].Amount})
+		assert.Equal(t, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+	})
+
+	t.Run("Should return the transaction to AUTHORIZED when the void can't be published", func(t *testing.T) {
+		// Arrange
+		repository := newAuthorized()
+		service := NewTransactionService(failingKafkaBroker{}, repository)
+
+		// Act
+		transaction, err := service.Void(ctx, "card-1")
+
+		// Assert
+		require.Error(t, err)
+		assert.Nil(t, transaction)
+		assert.Equal(t, domain.TransactionAuthorized, repository.transactions["card-1"].Status)
+	})
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Cabeçalhos enviados em cada entrega; a assinatura é o HMAC-SHA256 de "<timestamp>.<corpo>" com o segredo do webhook
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+type WebhookConfig struct {
+	MaxAttempts int
+	RetryBase   time.Duration
+	RetryMax    time.Duration
+	Timeout     time.Duration
+	BatchSize   int
+	// AllowPrivateAddresses libera endpoints em loopback e redes privadas; só para desenvolvimento local
+	AllowPrivateAddresses bool
+}
+
+// WebhookService mantém os endpoints dos merchants e entrega as mudanças de status por uma fila persistente.
+// As entregas são enfileiradas pelo repositório de transações, na mesma transação do banco que grava a mudança.
+type WebhookService struct {
+	webhooks   dRepo.WebhookRepository
+	deliveries dRepo.WebhookDeliveryRepository
+	client     *http.Client
+	resolver   *net.Resolver
+	config     WebhookConfig
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewWebhookService(webhooks dRepo.WebhookRepository, deliveries dRepo.WebhookDeliveryRepository, config WebhookConfig) *WebhookService {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 50
+	}
+	return &WebhookService{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     newWebhookClient(config),
+		resolver:   net.DefaultResolver,
+		config:     config,
+		logger:     logger.Log,
+		now:        time.Now,
+	}
+}
+
+// SignWebhookPayload é a assinatura esperada no cabeçalho X-Webhook-Signature, exposta para os merchants validarem
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) Register(ctx context.Context, request dto.WebhookRequestDto) (*domain.Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, tenantID := "", ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID, tenantID = principal.MerchantID, principal.TenantID
+	}
+
+	webhook, err := domain.NewWebhook(merchantID, tenantID, request.URL, secret, request.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAddress(ctx, webhook.URL); err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("webhook cadastrado",
+		zap.String("id", webhook.ID),
+		zap.String("merchantId", webhook.MerchantID),
+		zap.String("url", webhook.URL),
+	)
+	return webhook, nil
+}
+
+// checkAddress recusa no cadastro URLs cujo host resolve para um endereço interno. O nome pode passar a
+// resolver para outro endereço depois do cadastro, então a conexão de cada entrega é conferida de novo.
+func (s *WebhookService) checkAddress(ctx context.Context, rawURL string) error {
+	if s.config.AllowPrivateAddresses {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return domain.ErrorInvalidWebhookURL
+	}
+
+	addresses, err := s.resolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrorInvalidWebhookURL, err)
+	}
+	for _, address := range addresses {
+		if !domain.WebhookAddressAllowed(address.IP) {
+			return domain.ErrorWebhookAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]domain.Webhook, error) {
+	merchantID, tenantID := "", ""
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		merchantID, tenantID = principal.MerchantID, principal.TenantID
+	}
+	return s.webhooks.FindByMerchant(merchantID, tenantID)
+}
+
+// FindByID só retorna webhooks do merchant e do tenant do principal
+func (s *WebhookService) FindByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	webhook, err := s.webhooks.FindByID(id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.Owns(webhook.MerchantID, webhook.TenantID) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// Delete remove o endpoint; entregas pendentes dele são encerradas como FAILED pelo dispatcher
+func (s *WebhookService) Delete(ctx context.Context, id string) error {
+	webhook, err := s.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.webhooks.Delete(webhook.ID)
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, webhookID string, page, pageSize int) (*dto.PaginatedWebhookDeliveriesResponseDto, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	webhook, err := s.FindByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.deliveries.FindByWebhook(webhook.ID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedWebhookDeliveriesResponseDto{
+		Data:       deliveries,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// Redeliver recoloca uma entrega (inclusive já entregue ou FAILED) na fila para reenvio imediato
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error) {
+	webhook, err := s.FindByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.deliveries.FindByID(deliveryID)
+	if err != nil || delivery.WebhookID != webhook.ID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	delivery.Redeliver(s.now())
+	if err := s.deliveries.Update(delivery); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("reentrega de webhook solicitada",
+		zap.String("webhookId", webhook.ID),
+		zap.String("deliveryId", delivery.ID),
+	)
+	return delivery, nil
+}
+
+// DispatchDue envia as entregas vencidas e devolve quantas foram entregues com sucesso
+func (s *WebhookService) DispatchDue(ctx context.Context) (int, error) {
+	// o lease cobre o envio de um lote inteiro, para que outra réplica não repita a entrega em andamento
+	lease := s.config.Timeout*time.Duration(s.config.BatchSize) + time.Minute
+	deliveries, err := s.deliveries.ClaimDue(s.now(), lease, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		if s.dispatch(ctx, &deliveries[i]) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (s *WebhookService) dispatch(ctx context.Context, delivery *domain.WebhookDelivery) bool {
+	webhook, err := s.webhooks.FindByID(delivery.WebhookID)
+	if err != nil || !webhook.Active {
+		// sem endpoint não há o que tentar de novo
+		delivery.Abandon("webhook removido ou inativo", s.now())
+		if updateErr := s.deliveries.Update(delivery); updateErr != nil {
+			s.logger.Error("erro ao atualizar entrega de webhook",
+				zap.Error(updateErr),
+				zap.String("deliveryId", delivery.ID),
+			)
+		}
+		return false
+	}
+
+	statusCode, err := s.send(ctx, webhook, delivery)
+	if err == nil {
+		delivery.Delivered(statusCode, s.now())
+	} else {
+		delivery.AttemptFailed(statusCode, err.Error(), s.now(), s.config.MaxAttempts, s.config.RetryBase, s.config.RetryMax)
+		s.logger.Warn("falha na entrega do webhook",
+			zap.Error(err),
+			zap.String("deliveryId", delivery.ID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("status", delivery.Status),
+		)
+	}
+
+	if updateErr := s.deliveries.Update(delivery); updateErr != nil {
+		s.logger.Error("erro ao atualizar entrega de webhook",
+			zap.Error(updateErr),
+			zap.String("deliveryId", delivery.ID),
+		)
+	}
+	return err == nil
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	timestamp := s.now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, delivery.Event)
+	request.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	request.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("endpoint respondeu com status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+func (s *WebhookService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			delivered, err := s.DispatchDue(ctx)
+			if err != nil {
+				s.logger.Error("erro ao buscar entregas de webhook",
+					zap.Error(err),
+				)
+				continue
+			}
+			if delivered > 0 {
+				s.logger.Info("webhooks entregues",
+					zap.Int("count", delivered),
+				)
+			}
+		}
+	}
+}
+
+// newWebhookClient confere o endereço de cada conexão já resolvida, inclusive as dos redirecionamentos.
+// Sem proxy: a conexão seria com o proxy e o endereço do endpoint não seria conferido.
+func newWebhookClient(config WebhookConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !config.AllowPrivateAddresses {
+		dialer.Control = guardWebhookDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: config.Timeout, Transport: transport}
+}
+
+func guardWebhookDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !domain.WebhookAddressAllowed(ip) {
+		return fmt.Errorf("%w: %s", domain.ErrorWebhookAddressNotAllowed, host)
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryWebhookRepository struct {
+	webhooks []domain.Webhook
+}
+
+func (m *memoryWebhookRepository) Create(webhook *domain.Webhook) error {
+	m.webhooks = append(m.webhooks, *webhook)
+	return nil
+}
+
+func (m *memoryWebhookRepository) FindByID(id string) (*domain.Webhook, error) {
+	for i := range m.webhooks {
+		if m.webhooks[i].ID == id {
+			webhook := m.webhooks[i]
+			return &webhook, nil
+		}
+	}
+	return nil, ErrWebhookNotFound
+}
+
+func (m *memoryWebhookRepository) FindByMerchant(merchantID, tenantID string) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.MerchantID == merchantID && (tenantID == "" || webhook.TenantID == tenantID) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+// enqueueStatusChange enfileira as entregas como o repositório de transações faz ao gravar a mudança de status
+func enqueueStatusChange(t *testing.T, webhooks *memoryWebhookRepository, deliveries *memoryWebhookDeliveryRepository, transaction *domain.Transaction, previousStatus string, now time.Time) {
+	merchantWebhooks, err := webhooks.FindByMerchant(transaction.MerchantID, "")
+	require.NoError(t, err)
+	created, err := domain.NewStatusChangedDeliveries(merchantWebhooks, transaction, previousStatus, now)
+	require.NoError(t, err)
+	require.NoError(t, deliveries.CreateBatch(created))
+}
+
+func (m *memoryWebhookRepository) Delete(id string) error {
+	for i := range m.webhooks {
+		if m.webhooks[i].ID == id {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type memoryWebhookDeliveryRepository struct {
+	deliveries []domain.WebhookDelivery
+}
+
+func (m *memoryWebhookDeliveryRepository) CreateBatch(deliveries []domain.WebhookDelivery) error {
+	m.deliveries = append(m.deliveries, deliveries...)
+	return nil
+}
+
+func (m *memoryWebhookDeliveryRepository) FindByID(id string) (*domain.WebhookDelivery, error) {
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == id {
+			delivery := m.deliveries[i]
+			return &delivery, nil
+		}
+	}
+	return nil, ErrWebhookDeliveryNotFound
+}
+
+func (m *memoryWebhookDeliveryRepository) FindByWebhook(webhookID string, page, pageSize int) ([]domain.WebhookDelivery, int64, error) {
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, int64(len(deliveries)), nil
+}
+
+func (m *memoryWebhookDeliveryRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var due []domain.WebhookDelivery
+	for i := range m.deliveries {
+		if m.deliveries[i].Status == domain.WebhookDeliveryPending && !m.deliveries[i].NextAttemptAt.After(now) && len(due) < limit {
+			m.deliveries[i].NextAttemptAt = now.Add(lease)
+			due = append(due, m.deliveries[i])
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryWebhookDeliveryRepository) Update(delivery *domain.WebhookDelivery) error {
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == delivery.ID {
+			m.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookService_Dispatch(t *testing.T) {
+	// Arrange
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	webhooks := &memoryWebhookRepository{}
+	deliveries := &memoryWebhookDeliveryRepository{}
+	service := NewWebhookService(webhooks, deliveries, WebhookConfig{
+		MaxAttempts: 3,
+		RetryBase:   time.Minute,
+		RetryMax:    time.Hour,
+		Timeout:     time.Second,
+		// o receptor do teste escuta em 127.0.0.1
+		AllowPrivateAddresses: true,
+	})
+	service.now = func() time.Time { return now }
+
+	webhook, err := service.Register(context.Background(), dto.WebhookRequestDto{URL: server.URL, Statuses: []string{"finished"}})
+	require.NoError(t, err)
+
+	transaction := &domain.Transaction{ID: "9b2f6c1e-0000-4000-8000-000000000001", Amount: 100, PaymentMethod: "PIX", CurrencyCode: "BRL", Status: domain.TransactionFinished}
+	enqueueStatusChange(t, webhooks, deliveries, transaction, domain.TransactionPending, now)
+	enqueueStatusChange(t, webhooks, deliveries, &domain.Transaction{ID: "other", Status: domain.TransactionFailed}, domain.TransactionPending, now)
+	require.Len(t, deliveries.deliveries, 1)
+
+	t.Run("Should sign the payload and retry with exponential backoff", func(t *testing.T) {
+		// Act
+		delivered, err := service.DispatchDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		require.Len(t, receiver.requests, 1)
+
+		request := receiver.requests[0]
+		timestamp, err := strconv.ParseInt(request.Header.Get(WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, SignWebhookPayload(webhook.Secret, timestamp, receiver.bodies[0]), request.Header.Get(WebhookSignatureHeader))
+		assert.Equal(t, deliveries.deliveries[0].ID, request.Header.Get(WebhookDeliveryHeader))
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(receiver.bodies[0], &payload))
+		assert.Equal(t, domain.WebhookEventStatusChanged, payload["event"])
+		assert.Equal(t, domain.TransactionPending, payload["data"].(map[string]any)["previousStatus"])
+
+		failed := deliveries.deliveries[0]
+		assert.Equal(t, domain.WebhookDeliveryPending, failed.Status)
+		assert.Equal(t, 1, failed.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, failed.LastStatusCode)
+		assert.Equal(t, now.Add(time.Minute), failed.NextAttemptAt)
+
+		now = now.Add(time.Minute)
+		_, err = service.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(2*time.Minute), deliveries.deliveries[0].NextAttemptAt)
+	})
+
+	t.Run("Should mark the delivery as delivered once the receiver accepts it", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+
+		// Act
+		delivered, err := service.DispatchDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, domain.WebhookDeliveryDelivered, deliveries.deliveries[0].Status)
+		assert.Equal(t, 3, deliveries.deliveries[0].Attempts)
+		assert.NotNil(t, deliveries.deliveries[0].DeliveredAt)
+	})
+
+	t.Run("Should send the same payload again on manual redelivery", func(t *testing.T) {
+		// Act
+		_, err := service.Redeliver(context.Background(), webhook.ID, deliveries.deliveries[0].ID)
+		require.NoError(t, err)
+		delivered, err := service.DispatchDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		require.Len(t, receiver.requests, 4)
+		assert.Equal(t, receiver.bodies[0], receiver.bodies[3])
+	})
+}
+
+func TestWebhookService_InternalAddresses(t *testing.T) {
+	config := WebhookConfig{MaxAttempts: 3, RetryBase: time.Minute, RetryMax: time.Hour, Timeout: time.Second}
+
+	t.Run("Should refuse to register endpoints on internal addresses", func(t *testing.T) {
+		// Arrange
+		webhooks := &memoryWebhookRepository{}
+		service := NewWebhookService(webhooks, &memoryWebhookDeliveryRepository{}, config)
+
+		for _, url := range []string{
+			"http://127.0.0.1:8080/hooks",
+			"http://[::1]/hooks",
+			"http://169.254.169.254/latest/meta-data",
+			"http://10.0.0.5/hooks",
+			"http://192.168.1.10/hooks",
+			"http://0.0.0.0/hooks",
+		} {
+			// Act
+			_, err := service.Register(context.Background(), dto.WebhookRequestDto{URL: url})
+
+			// Assert
+			assert.ErrorIs(t, err, domain.ErrorWebhookAddressNotAllowed, url)
+		}
+		assert.Empty(t, webhooks.webhooks)
+	})
+
+	t.Run("Should not connect to an endpoint that resolves to an internal address after registration", func(t *testing.T) {
+		// Arrange
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		deliveries := &memoryWebhookDeliveryRepository{}
+		// cadastrado direto no repositório, como um nome que passou a resolver para a rede interna
+		webhooks := &memoryWebhookRepository{webhooks: []domain.Webhook{{ID: "webhook-1", URL: server.URL, Secret: "whsec_test", Active: true}}}
+		service := NewWebhookService(webhooks, deliveries, config)
+		enqueueStatusChange(t, webhooks, deliveries, &domain.Transaction{ID: "9b2f6c1e-0000-4000-8000-000000000002", Status: domain.TransactionFinished}, domain.TransactionPending, time.Now())
+		require.Len(t, deliveries.deliveries, 1)
+
+		// Act
+		delivered, err := service.DispatchDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Empty(t, receiver.requests)
+		assert.Contains(t, deliveries.deliveries[0].LastError, domain.ErrorWebhookAddressNotAllowed.Error())
+	})
+}
+
+func TestWebhookService_Tenants(t *testing.T) {
+	// Arrange
+	webhooks := &memoryWebhookRepository{}
+	deliveries := &memoryWebhookDeliveryRepository{}
+	service := NewWebhookService(webhooks, deliveries, WebhookConfig{AllowPrivateAddresses: true})
+	tenantA := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-a"})
+	tenantB := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-b"})
+	merchant := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: "merchant-1"})
+
+	scoped, err := service.Register(tenantA, dto.WebhookRequestDto{URL: "http://127.0.0.1/a"})
+	require.NoError(t, err)
+	wide, err := service.Register(merchant, dto.WebhookRequestDto{URL: "http://127.0.0.1/all"})
+	require.NoError(t, err)
+
+	// Act
+	enqueueStatusChange(t, webhooks, deliveries, &domain.Transaction{ID: "tx-b", MerchantID: "merchant-1", TenantID: "tenant-b", Status: domain.TransactionFinished}, domain.TransactionPending, time.Now())
+	listedA, listErr := service.List(tenantA)
+	_, findErr := service.FindByID(tenantB, scoped.ID)
+
+	// Assert
+	require.Len(t, deliveries.deliveries, 1)
+	assert.Equal(t, wide.ID, deliveries.deliveries[0].WebhookID)
+	require.NoError(t, listErr)
+	require.Len(t, listedA, 1)
+	assert.Equal(t, scoped.ID, listedA[0].ID)
+	assert.ErrorIs(t, findErr, ErrWebhookNotFound)
+}
+
+func TestWebhookDelivery_AttemptFailed(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	delivery := domain.NewWebhookDelivery("webhook", "transaction", "delivery", []byte("{}"), now)
+
+	// Act
+	for range 4 {
+		delivery.AttemptFailed(0, "connection refused", now, 5, time.Minute, 5*time.Minute)
+	}
+
+	// Assert
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, now.Add(5*time.Minute), delivery.NextAttemptAt)
+
+	delivery.AttemptFailed(0, "connection refused", now, 5, time.Minute, 5*time.Minute)
+	assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+}
@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/bizday"
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/pkg/config"
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/consumers"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/pix"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/risk"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/database"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/loadshed"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/pubsub"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/ratelimit"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/storage"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -21,29 +41,197 @@ func main() {
 	kafkaBroker := akafka.NewKafkaBroker("host.docker.internal:9094")
 	defer kafkaBroker.Close()
 
-	kafkaBroker.CreateTopicsIfNotExists([]string{"process-transaction", "transaction-process-return"})
-
 	db, err := database.NewPostgresConnection()
 	if err != nil {
 		logger.Log.Fatal("erro ao conectar ao banco de dados",
 			zap.Error(err),
 		)
 	}
-	database.RunMigrations(db)
+	if err := database.RunMigrations(db); err != nil {
+		logger.Log.Fatal("erro ao executar as migrações do banco de dados",
+			zap.Error(err),
+		)
+	}
 
 	txRepository := repository.NewTransactionRepositoryGorm(db)
 
-	processTransactionConsumer := consumers.NewProcessTransactionConsumer(&kafkaBroker, txRepository)
-	go processTransactionConsumer.Start()
-
 	// Configs Gin
 	router := gin.Default()
 
-	transactionService := services.NewTransactionService(kafkaBroker, txRepository)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
-	router.POST("/transaction", transactionHandler.CreateTransaction)
-	router.GET("/transactions", transactionHandler.GetTransactionsPaginated)
-	router.GET("/transaction/:id", transactionHandler.GetTransactionByID)
+	authMiddleware := newAuthMiddleware()
+	if !authMiddleware.Enabled() {
+		logger.Log.Warn("autenticação desabilitada: configure API_KEYS_FILE ou JWT_JWKS")
+	}
+	api := router.Group("/", authMiddleware.Authenticate())
+
+	if currencies := config.GetEnv("ENABLED_CURRENCIES", ""); currencies != "" {
+		domain.Currencies.SetEnabled(strings.Split(currencies, ","))
+	}
+	fxService := newFXService(db)
+
+	webhookService := newWebhookService(db)
+
+	eventBus := newEventBus(db)
+	eventStream := services.NewEventStreamService(repository.NewTransactionEventRepositoryGorm(db), eventBus, config.GetEnvInt("EVENT_STREAM_BUFFER", 64))
+
+	reviewRepository := repository.NewReviewRepositoryGorm(db)
+	reviewService := services.NewReviewService(kafkaBroker, reviewRepository, txRepository, config.GetEnvDuration("REVIEW_SLA", 24*time.Hour), eventStream)
+
+	feeService := services.NewFeeService(repository.NewFeePlanRepositoryGorm(db))
+
+	statusWaiter := services.NewStatusWaiter(config.GetEnvInt("LONG_POLL_MAX_WAITERS", 1000), config.GetEnvDuration("LONG_POLL_MAX_WAIT", 30*time.Second))
+	riskEngine := newRiskEngine(txRepository)
+	transactionService := services.NewTransactionService(kafkaBroker, txRepository,
+		services.WithQuotaService(newQuotaService(txRepository)),
+		services.WithRiskEngine(riskEngine),
+		services.WithReviewService(reviewService),
+		services.WithFXService(fxService),
+		services.WithPixChargeGenerator(newPixChargeGenerator()),
+		services.WithCardVault(newCardVault(db)),
+		services.WithCardFingerprinter(newCardFingerprinter()),
+		services.WithInstallmentService(newInstallmentService(db)),
+		services.WithFeeService(feeService),
+		services.WithStatusListeners(eventStream),
+		services.WithStatusWaiter(statusWaiter),
+		services.WithBatchLimit(config.GetEnvInt("BATCH_MAX_ITEMS", 1000)),
+		services.WithAuthorizationTTL(config.GetEnvDuration("AUTHORIZATION_TTL", 7*24*time.Hour)),
+		services.WithAuditRepository(repository.NewAuditRepositoryGorm(db)),
+		services.WithPendingSweep(services.PendingSweepConfig{
+			After:       config.GetEnvDuration("PENDING_SWEEP_AFTER", 5*time.Minute),
+			MaxAttempts: config.GetEnvInt("PENDING_SWEEP_MAX_ATTEMPTS", 3),
+			BatchSize:   config.GetEnvInt("PENDING_SWEEP_BATCH_SIZE", 100),
+		}),
+	)
+
+	importService := services.NewImportService(repository.NewImportRepositoryGorm(db), transactionService, services.ImportConfig{
+		ChunkSize:      config.GetEnvInt("IMPORT_CHUNK_SIZE", 500),
+		StaleAfter:     config.GetEnvDuration("IMPORT_STALE_AFTER", 5*time.Minute),
+		TempDir:        config.GetEnv("IMPORT_TEMP_DIR", ""),
+		MaxUploadBytes: int64(config.GetEnvInt("IMPORT_MAX_UPLOAD_BYTES", 100<<20)),
+	})
+	// "ledger import ..." roda só a importação e sai, antes de criar tópicos e de subir tarefas em segundo
+	// plano, consumidores e servidor HTTP
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		code := runImportCommand(os.Args[2:], importService)
+		// os.Exit pula os defers; o Close envia o que ainda está na fila do writer assíncrono
+		kafkaBroker.Close()
+		os.Exit(code)
+	}
+
+	kafkaBroker.CreateTopicsIfNotExists(append(domain.PaymentMethods.Topics(), "transaction-process-return", domain.CancelTransactionTopic, consumers.DisputesTopic, consumers.DisputesDeadLetterTopic))
+
+	go webhookService.StartDispatcher(context.Background(), config.GetEnvDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second))
+	go eventStream.Start(context.Background())
+	go eventStream.StartCleanup(context.Background(), config.GetEnvDuration("EVENT_CLEANUP_INTERVAL", time.Hour), config.GetEnvDuration("EVENT_RETENTION", 7*24*time.Hour))
+	go reviewService.StartSLAWatcher(context.Background(), config.GetEnvDuration("REVIEW_SLA_CHECK_INTERVAL", time.Minute))
+	go fxService.StartRefresher(context.Background(), config.GetEnvDuration("FX_RATES_REFRESH_INTERVAL", time.Minute))
+	if riskEngine != nil {
+		go riskEngine.Watch(context.Background(), config.GetEnv("RISK_RULES_FILE", ""), config.GetEnvDuration("RISK_RULES_RELOAD_INTERVAL", 10*time.Second))
+	}
+	// os retornos consumidos por outra réplica chegam como eventos de status pelo mesmo barramento dos streams
+	go statusWaiter.Start(context.Background(), eventBus)
+
+	processTransactionConsumer := consumers.NewProcessTransactionConsumer(&kafkaBroker, transactionService, statusWaiter)
+	go processTransactionConsumer.Start()
+
+	go transactionService.StartExpirationWatcher(context.Background(), config.GetEnvDuration("PIX_EXPIRATION_CHECK_INTERVAL", 30*time.Second))
+	go transactionService.StartPendingSweeper(context.Background(), config.GetEnvDuration("PENDING_SWEEP_INTERVAL", time.Minute))
+
+	intakeCtx, stopIntake := context.WithCancel(context.Background())
+	intakeService := services.NewIntakeService(transactionService, services.IntakeConfig{
+		QueueSize: config.GetEnvInt("INTAKE_QUEUE_SIZE", 10000),
+		Workers:   config.GetEnvInt("INTAKE_WORKERS", 4),
+		BatchSize: config.GetEnvInt("INTAKE_BATCH_SIZE", 200),
+		BatchWait: config.GetEnvDuration("INTAKE_BATCH_WAIT", 20*time.Millisecond),
+		ResultTTL: config.GetEnvDuration("INTAKE_RESULT_TTL", 10*time.Minute),
+	})
+	intakeService.Start(intakeCtx)
+
+	transactionHandler := handlers.NewTransactionHandler(transactionService, handlers.WithIntakeService(intakeService))
+	loadShedder := newLoadShedder(kafkaBroker, db)
+	go loadShedder.Start(context.Background(), config.GetEnvDuration("LOAD_SHED_SAMPLE_INTERVAL", time.Second))
+	transactionHandler.RegisterRoutes(api, authMiddleware, append([]gin.HandlerFunc{middlewares.LoadShed(loadShedder)}, newRateLimitMiddlewares(db)...)...)
+
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	reviewHandler.RegisterRoutes(api, authMiddleware)
+
+	fxHandler := handlers.NewFXHandler(fxService)
+	fxHandler.RegisterRoutes(api, authMiddleware)
+
+	feeHandler := handlers.NewFeeHandler(feeService)
+	feeHandler.RegisterRoutes(api, authMiddleware)
+
+	eventHandler := handlers.NewEventHandler(transactionService, eventStream, config.GetEnvDuration("EVENT_STREAM_HEARTBEAT", 15*time.Second))
+	eventHandler.RegisterRoutes(api, authMiddleware)
+
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	webhookHandler.RegisterRoutes(api, authMiddleware)
+
+	importHandler := handlers.NewImportHandler(importService)
+	importHandler.RegisterRoutes(api, authMiddleware)
+
+	reconciliationService := services.NewReconciliationService(repository.NewReconciliationRepositoryGorm(db), txRepository, services.ReconciliationConfig{
+		Format:         newReconciliationFormat(),
+		ChunkSize:      config.GetEnvInt("RECONCILIATION_CHUNK_SIZE", 500),
+		TempDir:        config.GetEnv("RECONCILIATION_TEMP_DIR", ""),
+		StaleAfter:     config.GetEnvDuration("RECONCILIATION_STALE_AFTER", 10*time.Minute),
+		MaxUploadBytes: int64(config.GetEnvInt("RECONCILIATION_MAX_UPLOAD_BYTES", 100<<20)),
+	})
+	go reconciliationService.Start(context.Background(), config.GetEnvDuration("RECONCILIATION_STALE_CHECK_INTERVAL", time.Minute))
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	reconciliationHandler.RegisterRoutes(api, authMiddleware)
+
+	settlementService := services.NewSettlementService(repository.NewSettlementRepositoryGorm(db), newSettlementConfig())
+	go settlementService.Start(context.Background(), config.GetEnvDuration("SETTLEMENT_INTERVAL", time.Minute))
+
+	settlementHandler := handlers.NewSettlementHandler(settlementService)
+	settlementHandler.RegisterRoutes(api, authMiddleware)
+
+	evidenceStorage, err := storage.NewLocalStorage(config.GetEnv("DISPUTE_EVIDENCE_DIR", filepath.Join(os.TempDir(), "ledger-dispute-evidence")))
+	if err != nil {
+		logger.Log.Fatal("erro ao criar diretório de evidências",
+			zap.Error(err),
+		)
+	}
+	disputeService := services.NewDisputeService(repository.NewDisputeRepositoryGorm(db), txRepository, evidenceStorage, services.DisputeConfig{
+		MaxEvidenceBytes: int64(config.GetEnvInt("DISPUTE_EVIDENCE_MAX_BYTES", 10<<20)),
+	})
+	go disputeService.StartDeadlineWatcher(context.Background(), config.GetEnvDuration("DISPUTE_DEADLINE_CHECK_INTERVAL", time.Minute))
+
+	disputeConsumer := consumers.NewDisputeConsumer(&kafkaBroker, disputeService, consumers.DisputeConsumerConfig{
+		MaxAttempts:  config.GetEnvInt("DISPUTE_MAX_ATTEMPTS", 5),
+		RetryBackoff: config.GetEnvDuration("DISPUTE_RETRY_BACKOFF", time.Second),
+	})
+	go disputeConsumer.Start()
+
+	disputeHandler := handlers.NewDisputeHandler(disputeService)
+	disputeHandler.RegisterRoutes(api, authMiddleware)
+
+	exportDir := config.GetEnv("EXPORT_DIR", filepath.Join(os.TempDir(), "ledger-exports"))
+	if err := os.MkdirAll(exportDir, 0o750); err != nil {
+		logger.Log.Fatal("erro ao criar diretório de exportações",
+			zap.Error(err),
+			zap.String("dir", exportDir),
+		)
+	}
+	exportService := services.NewExportService(repository.NewExportRepositoryGorm(db), transactionService, services.ExportConfig{
+		Dir:        exportDir,
+		Workers:    config.GetEnvInt("EXPORT_WORKERS", 2),
+		QueueSize:  config.GetEnvInt("EXPORT_QUEUE_SIZE", 20),
+		TTL:        config.GetEnvDuration("EXPORT_TTL", 24*time.Hour),
+		StaleAfter: config.GetEnvDuration("EXPORT_STALE_AFTER", 6*time.Hour),
+	})
+	go exportService.Start(context.Background(), config.GetEnvDuration("EXPORT_CLEANUP_INTERVAL", 10*time.Minute))
+
+	exportHandler := handlers.NewExportHandler(transactionService, exportService)
+	exportHandler.RegisterRoutes(api, authMiddleware)
+
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(domain.PaymentMethods)
+	paymentMethodHandler.RegisterRoutes(api)
+
+	// o scraper se autentica como os demais clientes, com uma credencial de escopo metrics:read
+	metricsHandler := handlers.NewMetricsHandler(loadShedder)
+	metricsHandler.RegisterRoutes(api, authMiddleware)
 
 	// Graceful shutdown config
 	quit := make(chan os.Signal, 1)
@@ -59,4 +247,327 @@ func main() {
 
 	<-quit
 	logger.Log.Info("encerrando o servidor...")
+
+	// grava o que ainda está na fila de entrada antes de sair
+	stopIntake()
+	intakeService.Wait()
+
+	// as conciliações em andamento ficam como FAILED, em vez de RUNNING para sempre
+	reconciliationService.Shutdown()
+}
+
+func newAuthMiddleware() *middlewares.Auth {
+	var apiKeys *auth.APIKeyStore
+	if path := config.GetEnv("API_KEYS_FILE", ""); path != "" {
+		store, err := auth.NewAPIKeyStoreFromFile(path)
+		if err != nil {
+			logger.Log.Fatal("erro ao carregar api keys",
+				zap.Error(err),
+			)
+		}
+		apiKeys = store
+	}
+
+	var jwtValidator *auth.JWTValidator
+	if source := config.GetEnv("JWT_JWKS", ""); source != "" {
+		keySet := auth.NewKeySet(source, config.GetEnvDuration("JWT_JWKS_TTL", 15*time.Minute))
+		jwtValidator = auth.NewJWTValidator(keySet, auth.JWTConfig{
+			Issuer:        config.GetEnv("JWT_ISSUER", ""),
+			Audience:      config.GetEnv("JWT_AUDIENCE", ""),
+			MerchantClaim: config.GetEnv("JWT_MERCHANT_CLAIM", "merchant_id"),
+			TenantClaim:   config.GetEnv("JWT_TENANT_CLAIM", "tenant_id"),
+			Leeway:        config.GetEnvDuration("JWT_LEEWAY", 30*time.Second),
+		})
+	}
+
+	return middlewares.NewAuth(apiKeys, jwtValidator)
+}
+
+func newRateLimitMiddlewares(db *gorm.DB) []gin.HandlerFunc {
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if config.GetEnv("RATE_LIMIT_BACKEND", "memory") == "postgres" {
+		postgresLimiter := ratelimit.NewPostgresLimiter(db)
+		go postgresLimiter.StartCleanup(context.Background(), config.GetEnvDuration("RATE_LIMIT_CLEANUP_INTERVAL", 10*time.Minute), config.GetEnvDuration("RATE_LIMIT_BUCKET_TTL", time.Hour))
+		limiter = postgresLimiter
+	}
+
+	ipLimit := ratelimit.Limit{
+		Rate:  config.GetEnvFloat("RATE_LIMIT_IP_RPS", 0),
+		Burst: config.GetEnvInt("RATE_LIMIT_IP_BURST", 0),
+	}
+	keyLimit := ratelimit.Limit{
+		Rate:  config.GetEnvFloat("RATE_LIMIT_KEY_RPS", 0),
+		Burst: config.GetEnvInt("RATE_LIMIT_KEY_BURST", 0),
+	}
+
+	return []gin.HandlerFunc{
+		middlewares.RateLimit(limiter, ipLimit, middlewares.ClientIPKey),
+		middlewares.RateLimit(limiter, keyLimit, middlewares.APIKeyKey),
+	}
+}
+
+func newLoadShedder(kafkaBroker akafka.KafkaBroker, db *gorm.DB) *loadshed.AdaptiveLimiter {
+	var signals []loadshed.Signal
+	if reporter, ok := kafkaBroker.(akafka.QueueDepthReporter); ok {
+		signals = append(signals, loadshed.QueueDepthSignal("kafka_queue_depth", config.GetEnvInt("LOAD_SHED_KAFKA_QUEUE_DEPTH", 5000), reporter.QueueDepth))
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		signals = append(signals, loadshed.DBPoolWaitSignal(sqlDB, config.GetEnvDuration("LOAD_SHED_DB_POOL_WAIT", 50*time.Millisecond)))
+	}
+
+	return loadshed.NewAdaptiveLimiter(loadshed.Config{
+		InitialLimit: config.GetEnvInt("LOAD_SHED_INITIAL_LIMIT", 100),
+		MinLimit:     config.GetEnvInt("LOAD_SHED_MIN_LIMIT", 10),
+		MaxLimit:     config.GetEnvInt("LOAD_SHED_MAX_LIMIT", 1000),
+		Tolerance:    config.GetEnvFloat("LOAD_SHED_TOLERANCE", 1.5),
+		Backoff:      config.GetEnvFloat("LOAD_SHED_BACKOFF", 0.9),
+		RetryAfter:   config.GetEnvDuration("LOAD_SHED_RETRY_AFTER", time.Second),
+	}, signals...)
+}
+
+func newQuotaService(txRepository *repository.TransactionRepositoryGorm) *services.QuotaService {
+	var quotas services.MerchantQuotas
+	if path := config.GetEnv("MERCHANT_QUOTAS_FILE", ""); path != "" {
+		loaded, err := services.LoadMerchantQuotas(path)
+		if err != nil {
+			logger.Log.Fatal("erro ao carregar quotas",
+				zap.Error(err),
+			)
+		}
+		quotas = loaded
+	}
+
+	return services.NewQuotaService(txRepository, config.GetEnvFloat("DAILY_AMOUNT_QUOTA", 0), quotas)
+}
+
+func newReconciliationFormat() services.ReconciliationFormat {
+	path := config.GetEnv("RECONCILIATION_FORMAT_FILE", "")
+	if path == "" {
+		return services.DefaultReconciliationFormat()
+	}
+
+	format, err := services.LoadReconciliationFormat(path)
+	if err != nil {
+		logger.Log.Fatal("erro ao carregar formato de conciliação",
+			zap.Error(err),
+		)
+	}
+	return format
+}
+
+// newSettlementConfig monta o calendário de dias úteis; SETTLEMENT_EXTRA_HOLIDAYS recebe datas AAAA-MM-DD
+// separadas por vírgula para feriados locais ou pontuais
+func newSettlementConfig() services.SettlementConfig {
+	location, err := time.LoadLocation(config.GetEnv("SETTLEMENT_TIMEZONE", "America/Sao_Paulo"))
+	if err != nil {
+		logger.Log.Fatal("fuso horário de liquidação inválido",
+			zap.Error(err),
+		)
+	}
+
+	var extra []time.Time
+	for _, value := range strings.Split(config.GetEnv("SETTLEMENT_EXTRA_HOLIDAYS", ""), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			logger.Log.Fatal("feriado adicional inválido",
+				zap.Error(err),
+				zap.String("value", value),
+			)
+		}
+		extra = append(extra, day)
+	}
+
+	return services.SettlementConfig{
+		Calendar:   bizday.NewBrazilCalendar(extra...),
+		Location:   location,
+		CloseDelay: config.GetEnvDuration("SETTLEMENT_CLOSE_DELAY", time.Hour),
+		BatchSize:  config.GetEnvInt("SETTLEMENT_BATCH_SIZE", 500),
+	}
+}
+
+func newInstallmentService(db *gorm.DB) *services.InstallmentService {
+	var policies services.MerchantInstallmentPolicies
+	if path := config.GetEnv("INSTALLMENT_POLICIES_FILE", ""); path != "" {
+		loaded, err := services.LoadMerchantInstallmentPolicies(path)
+		if err != nil {
+			logger.Log.Fatal("erro ao carregar políticas de parcelamento",
+				zap.Error(err),
+			)
+		}
+		policies = loaded
+	}
+
+	defaultPolicy := services.InstallmentPolicy{
+		MaxInstallments:          config.GetEnvInt("MAX_INSTALLMENTS", 12),
+		InterestFreeInstallments: config.GetEnvInt("INTEREST_FREE_INSTALLMENTS", 12),
+		MonthlyInterestRate:      config.GetEnvFloat("INSTALLMENT_MONTHLY_INTEREST_RATE", 0),
+		MinInstallmentAmount:     config.GetEnvFloat("MIN_INSTALLMENT_AMOUNT", 5),
+	}
+	return services.NewInstallmentService(repository.NewInstallmentRepositoryGorm(db), defaultPolicy, policies)
+}
+
+func newWebhookService(db *gorm.DB) *services.WebhookService {
+	return services.NewWebhookService(
+		repository.NewWebhookRepositoryGorm(db),
+		repository.NewWebhookDeliveryRepositoryGorm(db),
+		services.WebhookConfig{
+			MaxAttempts:           config.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBase:             config.GetEnvDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			RetryMax:              config.GetEnvDuration("WEBHOOK_RETRY_MAX", 6*time.Hour),
+			Timeout:               config.GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			BatchSize:             config.GetEnvInt("WEBHOOK_BATCH_SIZE", 50),
+			AllowPrivateAddresses: config.GetEnvBool("WEBHOOK_ALLOW_PRIVATE_ADDRESSES", false),
+		},
+	)
+}
+
+// newEventBus escolhe como os eventos de status chegam às outras réplicas; "memory" serve para uma réplica só
+func newEventBus(db *gorm.DB) services.EventBus {
+	if config.GetEnv("EVENT_BUS", "postgres") == "memory" {
+		return pubsub.NewMemoryBus()
+	}
+	return pubsub.NewPostgresBus(db, config.GetEnv("EVENT_BUS_CHANNEL", "transaction_events"))
+}
+
+func newRiskEngine(txRepository *repository.TransactionRepositoryGorm) *risk.Engine {
+	path := config.GetEnv("RISK_RULES_FILE", "")
+	if path == "" {
+		return nil
+	}
+
+	ruleSet, err := risk.LoadRuleSet(path)
+	if err != nil {
+		logger.Log.Fatal("erro ao carregar regras de risco",
+			zap.Error(err),
+		)
+	}
+
+	return risk.NewEngine(ruleSet, txRepository)
+}
+
+func newFXService(db *gorm.DB) *services.FXService {
+	fxService := services.NewFXService(repository.NewFXRateRepositoryGorm(db), config.GetEnv("FX_BASE_CURRENCY", "BRL"))
+	if err := fxService.Refresh(); err != nil {
+		logger.Log.Error("erro ao carregar cotações",
+			zap.Error(err),
+		)
+	}
+
+	if path := config.GetEnv("FX_RATES_FILE", ""); path != "" {
+		if err := fxService.LoadFile(context.Background(), path); err != nil {
+			logger.Log.Fatal("erro ao importar cotações",
+				zap.Error(err),
+			)
+		}
+	}
+
+	return fxService
+}
+
+func newPixChargeGenerator() *pix.ChargeGenerator {
+	generator, err := pix.NewChargeGenerator(pix.Config{
+		MerchantKey:     config.GetEnv("PIX_MERCHANT_KEY", ""),
+		MerchantName:    config.GetEnv("PIX_MERCHANT_NAME", "TRANSACTION HUB"),
+		MerchantCity:    config.GetEnv("PIX_MERCHANT_CITY", "SAO PAULO"),
+		LocationBaseURL: config.GetEnv("PIX_LOCATION_BASE_URL", ""),
+		Expiration:      config.GetEnvDuration("PIX_EXPIRATION", 30*time.Minute),
+	})
+	if err != nil {
+		logger.Log.Warn("geração de BR Code PIX desabilitada",
+			zap.Error(err),
+		)
+		return nil
+	}
+	return generator
+}
+
+func newCardVault(db *gorm.DB) *cardvault.Vault {
+	// o ledger recebe só as chaves públicas: consegue guardar cartões, mas não abri-los
+	path := config.GetEnv("CARD_VAULT_PUBLIC_KEYFILE", "")
+	if path == "" {
+		logger.Log.Warn("cofre de cartões desabilitado: configure CARD_VAULT_PUBLIC_KEYFILE")
+		return nil
+	}
+
+	keyring, err := cardvault.NewSealingKeyringFromFile(path)
+	if err != nil {
+		logger.Log.Fatal("erro ao carregar chaves do cofre de cartões",
+			zap.Error(err),
+		)
+	}
+	return cardvault.NewVault(keyring, cardvault.NewGormStore(db))
+}
+
+func newCardFingerprinter() *cardvault.Fingerprinter {
+	key := config.GetEnv("CARD_FINGERPRINT_KEY", "")
+	if key == "" {
+		logger.Log.Warn("regras de velocidade por cartão desabilitadas: configure CARD_FINGERPRINT_KEY")
+		return nil
+	}
+
+	fingerprinter, err := cardvault.NewFingerprinter([]byte(key))
+	if err != nil {
+		logger.Log.Fatal("erro ao configurar a impressão de cartões",
+			zap.Error(err),
+		)
+	}
+	return fingerprinter
+}
+
+// runImportCommand importa um arquivo local de forma síncrona, com as mesmas regras de POST /imports:
+//
+//	ledger import -merchant <id> [-tenant <id>] [-format csv|jsonl] arquivo.csv
+func runImportCommand(args []string, importService *services.ImportService) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	merchantID := flags.String("merchant", "", "merchant dono das transações importadas (obrigatório)")
+	tenantID := flags.String("tenant", "", "tenant dono das transações importadas")
+	format := flags.String("format", "", "csv ou jsonl; por padrão vem da extensão do arquivo")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *merchantID == "" || flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "uso: ledger import -merchant <id> [-tenant <id>] [-format csv|jsonl] <arquivo>")
+		return 2
+	}
+	path := flags.Arg(0)
+
+	contentHash, err := services.HashFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erro ao ler arquivo:", err)
+		return 1
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "cli", MerchantID: *merchantID, TenantID: *tenantID, Method: "cli"})
+	job, start, err := importService.Submit(ctx, filepath.Base(path), *format, contentHash)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erro ao criar importação:", err)
+		return 1
+	}
+	if !start {
+		fmt.Printf("importação %s já está %s (checkpoint %d)\n", job.ID, job.Status, job.Checkpoint)
+		return 0
+	}
+	if job.Checkpoint > 0 {
+		fmt.Printf("retomando importação %s a partir da linha %d\n", job.ID, job.Checkpoint+1)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erro ao abrir arquivo:", err)
+		return 1
+	}
+	defer file.Close()
+
+	importErr := importService.Import(ctx, job, file)
+	fmt.Printf("importação %s: %s, %d importadas, %d duplicadas, %d com erro (checkpoint %d)\n",
+		job.ID, job.Status, job.ImportedRows, job.DuplicateRows, job.FailedRows, job.Checkpoint)
+	if importErr != nil {
+		fmt.Fprintln(os.Stderr, "erro na importação:", importErr)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const AuditEntityTransaction = "TRANSACTION"
+
+// Ações registradas pelo varredor de transações presas em PENDING
+const (
+	AuditActionProcessingRedriven   = "PROCESSING_REDRIVEN"
+	AuditActionProcessingAbandoned  = "PROCESSING_ABANDONED"
+	AuditActionLateProcessingResult = "LATE_PROCESSING_RESULT"
+)
+
+// Ações do cancelamento de transações PENDING; o pedido perde quando o processamento termina antes dele
+const (
+	AuditActionCancellationRequested = "CANCELLATION_REQUESTED"
+	AuditActionCancellationRejected  = "CANCELLATION_REJECTED"
+)
+
+const (
+	AuditActorPendingSweeper = "pending-sweeper"
+	AuditActorProcessor      = "transaction-processment"
+	AuditActorAPI            = "api"
+)
+
+// AuditEntry registra uma ação automática ou manual sobre uma entidade; nunca é alterada depois de gravada
+type AuditEntry struct {
+	ID         string            `json:"id" gorm:"primaryKey;type:uuid"`
+	EntityType string            `json:"entityType" gorm:"type:varchar(30);not null;index:idx_audit_entries_entity,priority:1"`
+	EntityID   string            `json:"entityId" gorm:"type:varchar(64);not null;index:idx_audit_entries_entity,priority:2"`
+	Action     string            `json:"action" gorm:"type:varchar(40);not null"`
+	Actor      string            `json:"actor" gorm:"type:varchar(64);not null"`
+	Details    map[string]string `json:"details,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedAt  time.Time         `json:"createdAt" gorm:"type:timestamp;not null;index"`
+}
+
+func NewTransactionAuditEntry(transactionID, action, actor string, details map[string]string) *AuditEntry {
+	return &AuditEntry{
+		ID:         uuid.New().String(),
+		EntityType: AuditEntityTransaction,
+		EntityID:   transactionID,
+		Action:     action,
+		Actor:      actor,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrorBatchRequestExists = errors.New("a batch with this idempotency key was already stored")
+	ErrorIdempotencyKeyUsed = errors.New("idempotency key was already used with a different batch")
+)
+
+// BatchRequest guarda a resposta de um lote enviado com Idempotency-Key. É gravado na mesma transação do
+// banco que as transações do lote, então a chave existe se e somente se o lote foi gravado.
+type BatchRequest struct {
+	MerchantID     string    `json:"merchantId" gorm:"primaryKey;type:varchar(64)"`
+	IdempotencyKey string    `json:"idempotencyKey" gorm:"primaryKey;type:varchar(255)"`
+	RequestHash    string    `json:"requestHash" gorm:"type:varchar(64);not null"`
+	Response       string    `json:"response" gorm:"type:text;not null"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"type:timestamp;not null;index"`
+}
+
+// Matches indica se o lote reenviado com a mesma chave é o mesmo que foi gravado
+func (r *BatchRequest) Matches(requestHash string) error {
+	if r.RequestHash != requestHash {
+		return ErrorIdempotencyKeyUsed
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Currency representa uma moeda da tabela ISO 4217
+type Currency struct {
+	Code       string `json:"code"`
+	Numeric    string `json:"numeric"`
+	MinorUnits int    `json:"minorUnits"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// Round arredonda o valor para a quantidade de casas decimais da moeda
+func (c Currency) Round(amount float64) float64 {
+	factor := math.Pow10(c.MinorUnits)
+	return math.Round(amount*factor) / factor
+}
+
+type CurrencyTable struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyTable(currencies []Currency) *CurrencyTable {
+	table := &CurrencyTable{currencies: make(map[string]Currency, len(currencies))}
+	for _, currency := range currencies {
+		table.currencies[currency.Code] = currency
+	}
+	return table
+}
+
+func (t *CurrencyTable) Lookup(code string) (Currency, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	currency, ok := t.currencies[strings.ToUpper(code)]
+	return currency, ok
+}
+
+func (t *CurrencyTable) IsEnabled(code string) bool {
+	currency, ok := t.Lookup(code)
+	return ok && currency.Enabled
+}
+
+// SetEnabled habilita apenas as moedas informadas, desabilitando as demais
+func (t *CurrencyTable) SetEnabled(codes []string) {
+	enabled := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		enabled[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for code, currency := range t.currencies {
+		currency.Enabled = enabled[code]
+		t.currencies[code] = currency
+	}
+}
+
+func (t *CurrencyTable) All() []Currency {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	currencies := make([]Currency, 0, len(t.currencies))
+	for _, currency := range t.currencies {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+	return currencies
+}
+
+// Currencies é a tabela usada na validação das transações; por padrão apenas BRL e USD ficam habilitadas
+var Currencies = NewCurrencyTable([]Currency{
+	{Code: "AED", Numeric: "784", MinorUnits: 2},
+	{Code: "ARS", Numeric: "032", MinorUnits: 2},
+	{Code: "AUD", Numeric: "036", MinorUnits: 2},
+	{Code: "BHD", Numeric: "048", MinorUnits: 3},
+	{Code: "BOB", Numeric: "068", MinorUnits: 2},
+	{Code: "BRL", Numeric: "986", MinorUnits: 2, Enabled: true},
+	{Code: "CAD", Numeric: "124", MinorUnits: 2},
+	{Code: "CHF", Numeric: "756", MinorUnits: 2},
+	{Code: "CLP", Numeric: "152", MinorUnits: 0},
+	{Code: "CNY", Numeric: "156", MinorUnits: 2},
+	{Code: "COP", Numeric: "170", MinorUnits: 2},
+	{Code: "CZK", Numeric: "203", MinorUnits: 2},
+	{Code: "DKK", Numeric: "208", MinorUnits: 2},
+	{Code: "EUR", Numeric: "978", MinorUnits: 2},
+	{Code: "GBP", Numeric: "826", MinorUnits: 2},
+	{Code: "HKD", Numeric: "344", MinorUnits: 2},
+	{Code: "INR", Numeric: "356", MinorUnits: 2},
+	{Code: "JOD", Numeric: "400", MinorUnits: 3},
+	{Code: "JPY", Numeric: "392", MinorUnits: 0},
+	{Code: "KRW", Numeric: "410", MinorUnits: 0},
+	{Code: "KWD", Numeric: "414", MinorUnits: 3},
+	{Code: "MXN", Numeric: "484", MinorUnits: 2},
+	{Code: "NOK", Numeric: "578", MinorUnits: 2},
+	{Code: "NZD", Numeric: "554", MinorUnits: 2},
+	{Code: "OMR", Numeric: "512", MinorUnits: 3},
+	{Code: "PEN", Numeric: "604", MinorUnits: 2},
+	{Code: "PLN", Numeric: "985", MinorUnits: 2},
+	{Code: "PYG", Numeric: "600", MinorUnits: 0},
+	{Code: "SEK", Numeric: "752", MinorUnits: 2},
+	{Code: "SGD", Numeric: "702", MinorUnits: 2},
+	{Code: "TND", Numeric: "788", MinorUnits: 3},
+	{Code: "USD", Numeric: "840", MinorUnits: 2, Enabled: true},
+	{Code: "UYU", Numeric: "858", MinorUnits: 2},
+	{Code: "ZAR", Numeric: "710", MinorUnits: 2},
+})
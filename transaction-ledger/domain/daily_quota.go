@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// DailyQuota é a linha de trava da quota de um merchant, numa moeda, num dia (UTC). Quem grava transações
+// que consomem a quota trava essa linha antes de somar o que já foi usado, então duas gravações do mesmo
+// merchant e moeda não conferem a quota ao mesmo tempo.
+type DailyQuota struct {
+	MerchantID   string    `json:"merchantId" gorm:"primaryKey;type:varchar(64)"`
+	CurrencyCode string    `json:"currencyCode" gorm:"primaryKey;type:varchar(3)"`
+	Day          time.Time `json:"day" gorm:"primaryKey;type:date"`
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorTransactionNotDisputable = errors.New("only FINISHED credit card transactions can be disputed")
+	ErrorInvalidDisputeReason     = errors.New("dispute reason code is not supported")
+	ErrorInvalidDisputeAmount     = errors.New("dispute amount must be greater than 0 and not exceed the transaction amount")
+	ErrorDisputedAmountExceeded   = errors.New("open and lost disputes already cover the settled amount")
+	ErrorDisputeNotOpen           = errors.New("dispute is not open for evidence")
+	ErrorDisputeResolved          = errors.New("dispute is already resolved")
+	ErrorEvidenceDeadlinePassed   = errors.New("evidence deadline has passed")
+	ErrorEvidenceDeadlineOpen     = errors.New("evidence deadline has not passed yet")
+	ErrorEvidenceRequired         = errors.New("at least one evidence file is required")
+	ErrorDisputeStatusChanged     = errors.New("dispute status changed concurrently")
+)
+
+// Ciclo de vida da disputa: OPENED aceita arquivos de evidência até EvidenceDueAt, EVIDENCE_SUBMITTED
+// e EVIDENCE_OVERDUE (prazo vencido sem envio) aguardam a decisão do processador, WON e LOST são finais
+const (
+	DisputeOpened            = "OPENED"
+	DisputeEvidenceSubmitted = "EVIDENCE_SUBMITTED"
+	DisputeEvidenceOverdue   = "EVIDENCE_OVERDUE"
+	DisputeWon               = "WON"
+	DisputeLost              = "LOST"
+)
+
+// DisputeReason descreve um motivo de chargeback; ResponseDays é o prazo padrão, em dias corridos,
+// para enviar a evidência quando o processador não informa o prazo
+type DisputeReason struct {
+	Code         string `json:"code"`
+	Description  string `json:"description"`
+	ResponseDays int    `json:"responseDays"`
+}
+
+var DisputeReasons = map[string]DisputeReason{
+	"FRAUD":                {Code: "FRAUD", Description: "Transação não reconhecida pelo portador", ResponseDays: 7},
+	"NOT_RECEIVED":         {Code: "NOT_RECEIVED", Description: "Produto ou serviço não recebido", ResponseDays: 10},
+	"NOT_AS_DESCRIBED":     {Code: "NOT_AS_DESCRIBED", Description: "Produto ou serviço diferente do anunciado", ResponseDays: 10},
+	"DUPLICATE":            {Code: "DUPLICATE", Description: "Cobrança em duplicidade", ResponseDays: 7},
+	"CREDIT_NOT_PROCESSED": {Code: "CREDIT_NOT_PROCESSED", Description: "Cancelamento ou estorno não processado", ResponseDays: 10},
+	"RECURRING_CANCELLED":  {Code: "RECURRING_CANCELLED", Description: "Cobrança recorrente já cancelada", ResponseDays: 10},
+	"GENERAL":              {Code: "GENERAL", Description: "Outros motivos", ResponseDays: 7},
+}
+
+// Dispute é o chargeback aberto pelo portador do cartão contra uma transação FINISHED. ProcessorDisputeID
+// identifica a disputa nas notificações do processador e torna a ingestão idempotente.
+type Dispute struct {
+	ID                  string     `json:"id" gorm:"primaryKey;type:uuid"`
+	ProcessorDisputeID  string     `json:"processorDisputeId" gorm:"type:varchar(100);not null;uniqueIndex"`
+	TransactionID       string     `json:"transactionId" gorm:"type:uuid;not null;index"`
+	MerchantID          string     `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID            string     `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	ReasonCode          string     `json:"reasonCode" gorm:"type:varchar(30);not null"`
+	Amount              float64    `json:"amount" gorm:"type:decimal(18,4);not null"`
+	CurrencyCode        string     `json:"currencyCode" gorm:"type:varchar(3);not null"`
+	Status              string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	EvidenceDueAt       time.Time  `json:"evidenceDueAt" gorm:"type:timestamp;not null;index"`
+	EvidenceSubmittedAt *time.Time `json:"evidenceSubmittedAt,omitempty" gorm:"type:timestamp"`
+	ResolvedAt          *time.Time `json:"resolvedAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt           time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt           time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+// NewDispute abre a disputa; amount zero contesta o que resta do valor liquidado e dueAt zero usa o prazo do
+// motivo. disputed é a soma das disputas da transação ainda abertas ou perdidas: juntas, elas não passam do
+// valor liquidado.
+func NewDispute(transaction *Transaction, disputed float64, processorDisputeID, reasonCode string, amount float64, dueAt, now time.Time) (*Dispute, error) {
+	if transaction.Status != TransactionFinished || transaction.PaymentMethod != PaymentMethodCreditCard {
+		return nil, ErrorTransactionNotDisputable
+	}
+
+	reason, ok := DisputeReasons[reasonCode]
+	if !ok {
+		return nil, ErrorInvalidDisputeReason
+	}
+
+	settled := transaction.Amount
+	if transaction.CapturedAmount > 0 {
+		settled = transaction.CapturedAmount
+	}
+	remaining := settled - disputed
+	if currency, ok := Currencies.Lookup(transaction.CurrencyCode); ok {
+		remaining = currency.Round(remaining)
+	}
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount < 0 || amount > settled {
+		return nil, ErrorInvalidDisputeAmount
+	}
+	if amount == 0 || amount > remaining {
+		return nil, ErrorDisputedAmountExceeded
+	}
+
+	if dueAt.IsZero() {
+		dueAt = now.AddDate(0, 0, reason.ResponseDays)
+	}
+
+	return &Dispute{
+		ID:                 uuid.New().String(),
+		ProcessorDisputeID: processorDisputeID,
+		TransactionID:      transaction.ID,
+		MerchantID:         transaction.MerchantID,
+		TenantID:           transaction.TenantID,
+		ReasonCode:         reasonCode,
+		Amount:             amount,
+		CurrencyCode:       transaction.CurrencyCode,
+		Status:             DisputeOpened,
+		EvidenceDueAt:      dueAt,
+	}, nil
+}
+
+// AcceptsEvidence indica se ainda é possível anexar arquivos à disputa
+func (d *Dispute) AcceptsEvidence(now time.Time) error {
+	if d.Status != DisputeOpened {
+		return ErrorDisputeNotOpen
+	}
+	if now.After(d.EvidenceDueAt) {
+		return ErrorEvidenceDeadlinePassed
+	}
+	return nil
+}
+
+// SubmitEvidence encerra o envio de evidências; evidenceCount é a quantidade de arquivos já anexados
+func (d *Dispute) SubmitEvidence(evidenceCount int, now time.Time) error {
+	if err := d.AcceptsEvidence(now); err != nil {
+		return err
+	}
+	if evidenceCount == 0 {
+		return ErrorEvidenceRequired
+	}
+	d.Status = DisputeEvidenceSubmitted
+	d.EvidenceSubmittedAt = &now
+	return nil
+}
+
+// CloseEvidence encerra o envio de evidências de uma disputa OPENED cujo prazo venceu. A disputa não é
+// decidida aqui: só o processador diz se ela foi ganha ou perdida.
+func (d *Dispute) CloseEvidence(now time.Time) error {
+	if d.Status != DisputeOpened {
+		return ErrorDisputeNotOpen
+	}
+	if !now.After(d.EvidenceDueAt) {
+		return ErrorEvidenceDeadlineOpen
+	}
+	d.Status = DisputeEvidenceOverdue
+	return nil
+}
+
+// Resolve registra a decisão; disputas perdidas geram o ajuste de débito devolvido
+func (d *Dispute) Resolve(won bool, now time.Time) (*LedgerAdjustment, error) {
+	if d.Status == DisputeWon || d.Status == DisputeLost {
+		return nil, ErrorDisputeResolved
+	}
+
+	d.ResolvedAt = &now
+	if won {
+		d.Status = DisputeWon
+		return nil, nil
+	}
+
+	d.Status = DisputeLost
+	return NewDisputeDebit(d), nil
+}
+
+// DisputeEvidence é um arquivo enviado pelo merchant; o conteúdo fica no armazenamento em StorageKey
+type DisputeEvidence struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:uuid"`
+	DisputeID   string    `json:"disputeId" gorm:"type:uuid;not null;index"`
+	FileName    string    `json:"fileName" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"contentType" gorm:"type:varchar(100);not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+	SHA256      string    `json:"sha256" gorm:"type:varchar(64);not null"`
+	StorageKey  string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
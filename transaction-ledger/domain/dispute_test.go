@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispute_Lifecycle(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	finished := &domain.Transaction{ID: "tx-1", MerchantID: "merchant-1", Status: domain.TransactionFinished, PaymentMethod: domain.PaymentMethodCreditCard, CurrencyCode: "BRL", Amount: 100, CapturedAmount: 80}
+
+	t.Run("Should only open disputes for FINISHED credit card transactions", func(t *testing.T) {
+		// Arrange
+		pix := &domain.Transaction{ID: "tx-2", Status: domain.TransactionFinished, PaymentMethod: domain.PaymentMethodPIX, Amount: 100}
+
+		// Act
+		_, pixErr := domain.NewDispute(pix, 0, "dp-1", "FRAUD", 0, time.Time{}, now)
+		_, reasonErr := domain.NewDispute(finished, 0, "dp-1", "UNKNOWN", 0, time.Time{}, now)
+		_, amountErr := domain.NewDispute(finished, 0, "dp-1", "FRAUD", 90, time.Time{}, now)
+		dispute, err := domain.NewDispute(finished, 0, "dp-1", "FRAUD", 0, time.Time{}, now)
+
+		// Assert
+		assert.ErrorIs(t, pixErr, domain.ErrorTransactionNotDisputable)
+		assert.ErrorIs(t, reasonErr, domain.ErrorInvalidDisputeReason)
+		assert.ErrorIs(t, amountErr, domain.ErrorInvalidDisputeAmount)
+		require.NoError(t, err)
+		assert.Equal(t, 80.0, dispute.Amount)
+		assert.Equal(t, domain.DisputeOpened, dispute.Status)
+		assert.Equal(t, now.AddDate(0, 0, 7), dispute.EvidenceDueAt)
+	})
+
+	t.Run("Should not let open and lost disputes add up to more than the settled amount", func(t *testing.T) {
+		// Act
+		remaining, err := domain.NewDispute(finished, 50, "dp-2", "FRAUD", 0, time.Time{}, now)
+		_, exceededErr := domain.NewDispute(finished, 50, "dp-2", "FRAUD", 40, time.Time{}, now)
+		_, coveredErr := domain.NewDispute(finished, 80, "dp-2", "FRAUD", 0, time.Time{}, now)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 30.0, remaining.Amount, "amount zero should dispute what is left")
+		assert.ErrorIs(t, exceededErr, domain.ErrorDisputedAmountExceeded)
+		assert.ErrorIs(t, coveredErr, domain.ErrorDisputedAmountExceeded)
+	})
+
+	t.Run("Should require evidence before the deadline and debit the merchant when lost", func(t *testing.T) {
+		// Arrange
+		dispute, err := domain.NewDispute(finished, 0, "dp-1", "NOT_RECEIVED", 50, now.Add(time.Hour), now)
+		require.NoError(t, err)
+
+		// Act
+		emptyErr := dispute.SubmitEvidence(0, now)
+		lateErr := dispute.SubmitEvidence(1, now.Add(2*time.Hour))
+		submitErr := dispute.SubmitEvidence(1, now)
+		adjustment, resolveErr := dispute.Resolve(false, now)
+		_, secondErr := dispute.Resolve(true, now)
+
+		// Assert
+		assert.ErrorIs(t, emptyErr, domain.ErrorEvidenceRequired)
+		assert.ErrorIs(t, lateErr, domain.ErrorEvidenceDeadlinePassed)
+		require.NoError(t, submitErr)
+		require.NoError(t, resolveErr)
+		assert.Equal(t, domain.DisputeLost, dispute.Status)
+		require.NotNil(t, adjustment)
+		assert.Equal(t, domain.LedgerAdjustmentDebit, adjustment.Type)
+		assert.Equal(t, 50.0, adjustment.Amount)
+		assert.Equal(t, "merchant-1", adjustment.MerchantID)
+		assert.Equal(t, dispute.ID, *adjustment.DisputeID)
+		assert.ErrorIs(t, secondErr, domain.ErrorDisputeResolved)
+	})
+}
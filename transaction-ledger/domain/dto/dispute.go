@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+// Eventos de disputa enviados pelo processador no tópico transaction-disputes
+const (
+	DisputeEventOpened = "OPENED"
+	DisputeEventWon    = "WON"
+	DisputeEventLost   = "LOST"
+)
+
+// DisputeNotificationDto é a notificação do processador; amount zero contesta o valor inteiro e
+// evidence_due_at ausente usa o prazo padrão do motivo
+type DisputeNotificationDto struct {
+	ProcessorDisputeID string     `json:"processor_dispute_id"`
+	TransactionID      string     `json:"transaction_id"`
+	Event              string     `json:"event"`
+	ReasonCode         string     `json:"reason_code,omitempty"`
+	Amount             float64    `json:"amount,omitempty"`
+	EvidenceDueAt      *time.Time `json:"evidence_due_at,omitempty"`
+}
+
+// DisputeResponseDto é a disputa com os arquivos de evidência e, se perdida, o débito gerado
+type DisputeResponseDto struct {
+	*tx.Dispute
+	Evidence   []tx.DisputeEvidence `json:"evidence"`
+	Adjustment *tx.LedgerAdjustment `json:"adjustment,omitempty"`
+}
+
+type PaginatedDisputesResponseDto struct {
+	Data       []tx.Dispute `json:"data"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"pageSize"`
+	TotalItems int64        `json:"totalItems"`
+	TotalPages int          `json:"totalPages"`
+}
+
+type PaginatedLedgerAdjustmentsResponseDto struct {
+	Data       []tx.LedgerAdjustment `json:"data"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"pageSize"`
+	TotalItems int64                 `json:"totalItems"`
+	TotalPages int                   `json:"totalPages"`
+}
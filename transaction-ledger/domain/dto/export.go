@@ -0,0 +1,9 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+// ExportResponseDto traz o endereço de download quando a exportação está concluída
+type ExportResponseDto struct {
+	*tx.ExportJob
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
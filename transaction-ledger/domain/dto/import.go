@@ -0,0 +1,11 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type PaginatedImportRowErrorsResponseDto struct {
+	Data       []tx.ImportRowError `json:"data"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"pageSize"`
+	TotalItems int64               `json:"totalItems"`
+	TotalPages int                 `json:"totalPages"`
+}
@@ -1,12 +1,22 @@
 package dto
 
 const (
-	TransactionStatusProcessed = "PROCESSED"
-	TransactionStatusFailed    = "FAILED"
+	TransactionStatusProcessed  = "PROCESSED"
+	TransactionStatusFailed     = "FAILED"
+	TransactionStatusAuthorized = "AUTHORIZED"
+	TransactionStatusCaptured   = "CAPTURED"
+	TransactionStatusVoided     = "VOIDED"
+	TransactionStatusCancelled  = "CANCELLED"
 )
 
 type ProcessTransactionDto struct {
 	TransactionID string `json:"transaction_id"`
+	Operation     string `json:"operation,omitempty"`
 	Status        string `json:"status"`
 	ErrorMessage  string `json:"error_message"`
 }
+
+// CancelTransactionDto é o pedido de cancelamento publicado em domain.CancelTransactionTopic
+type CancelTransactionDto struct {
+	TransactionID string `json:"transaction_id"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+// ReconciliationReportDto é o relatório com uma página das divergências
+type ReconciliationReportDto struct {
+	*tx.Reconciliation
+	Items PaginatedReconciliationItemsResponseDto `json:"items"`
+}
+
+type PaginatedReconciliationItemsResponseDto struct {
+	Data       []tx.ReconciliationItem `json:"data"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"pageSize"`
+	TotalItems int64                   `json:"totalItems"`
+	TotalPages int                     `json:"totalPages"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type ReviewDecisionRequestDto struct {
+	// Reviewer só é usado quando a autenticação está desabilitada; caso contrário vem do principal
+	Reviewer string `json:"reviewer"`
+	Notes    string `json:"notes"`
+}
+
+type PaginatedReviewsResponseDto struct {
+	Data       []tx.Review `json:"data"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"pageSize"`
+	TotalItems int64       `json:"totalItems"`
+	TotalPages int         `json:"totalPages"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type PayoutRequestDto struct {
+	PayoutReference string `json:"payoutReference" binding:"required"`
+}
+
+type PaginatedSettlementBatchesResponseDto struct {
+	Data       []tx.SettlementBatch `json:"data"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"pageSize"`
+	TotalItems int64                `json:"totalItems"`
+	TotalPages int                  `json:"totalPages"`
+}
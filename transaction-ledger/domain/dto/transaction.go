@@ -1,16 +1,120 @@
 package dto
 
-import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/pkg/pixkey"
+	tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
 
 type TransactionRequestDto struct {
 	Amount        float64 `json:"amount" validate:"required,min=0"`
-	PaymentMethod string  `json:"paymentMethod" validate:"required,oneof=PIX CREDIT_CARD"`
-	CurrencyCode  string  `json:"currencyCode" validate:"required,oneof=BRL USD"`
+	PaymentMethod string  `json:"paymentMethod" validate:"required"`
+	CurrencyCode  string  `json:"currencyCode" validate:"required,len=3"`
 	Description   string  `json:"description" validate:"required"`
+
+	SettlementCurrency string            `json:"settlementCurrency,omitempty" validate:"omitempty,len=3"`
+	PaymentDetails     map[string]string `json:"paymentDetails,omitempty"`
+	PixKey             string            `json:"pixKey,omitempty" validate:"omitempty,max=77"`
+	Card               *CardRequestDto   `json:"card,omitempty"`
+	// Capture false cria só a autorização do cartão de crédito; o padrão é autorizar e capturar
+	Capture *bool `json:"capture,omitempty"`
+	// Installments é o número de parcelas do cartão de crédito; omitido ou 1 é à vista
+	Installments int `json:"installments,omitempty" validate:"omitempty,min=1"`
+
+	// ID é reservado pelo ledger na criação assíncrona; nunca vem do cliente
+	ID string `json:"-"`
+}
+
+type CaptureRequestDto struct {
+	// Amount zero captura o valor total autorizado
+	Amount float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+type CardRequestDto struct {
+	Number      string `json:"number" validate:"required"`
+	ExpiryMonth int    `json:"expiryMonth" validate:"required,min=1,max=12"`
+	ExpiryYear  int    `json:"expiryYear" validate:"required"`
+	HolderName  string `json:"holderName" validate:"required"`
+}
+
+func (dto *CardRequestDto) ToCard() cardvault.Card {
+	card := cardvault.Card{
+		Number:      dto.Number,
+		ExpiryMonth: dto.ExpiryMonth,
+		ExpiryYear:  dto.ExpiryYear,
+		HolderName:  dto.HolderName,
+	}
+	card.Normalize()
+	return card
+}
+
+// MarshalJSON evita que a chave Pix e os dados do cartão apareçam completos nos logs da requisição
+func (dto TransactionRequestDto) MarshalJSON() ([]byte, error) {
+	type request TransactionRequestDto
+	masked := request(dto)
+	masked.PixKey = pixkey.Mask(dto.PixKey)
+	if dto.Card != nil {
+		card := dto.Card.ToCard()
+		masked.Card = &CardRequestDto{
+			Number:      strings.Repeat("*", max(len(card.Number)-4, 0)) + card.Last4(),
+			ExpiryMonth: card.ExpiryMonth,
+			ExpiryYear:  card.ExpiryYear,
+			HolderName:  "***",
+		}
+	}
+	return json.Marshal(masked)
 }
 
 type TransactionResponseDto struct {
-	ID string `json:"id"`
+	ID     string                `json:"id"`
+	Status string                `json:"status,omitempty"`
+	Pix    *PixChargeResponseDto `json:"pix,omitempty"`
+	Card   *CardResponseDto      `json:"card,omitempty"`
+}
+
+type CardResponseDto struct {
+	Token string `json:"token"`
+	Last4 string `json:"last4"`
+}
+
+type PixChargeResponseDto struct {
+	TxID      string    `json:"txId"`
+	Payload   string    `json:"payload"`
+	QRCodeURL string    `json:"qrCodeUrl"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type InstallmentScheduleResponseDto struct {
+	TransactionID       string           `json:"transactionId"`
+	Installments        int              `json:"installments"`
+	Plan                string           `json:"plan,omitempty"`
+	MonthlyInterestRate float64          `json:"monthlyInterestRate"`
+	Amount              float64          `json:"amount"`
+	TotalAmount         float64          `json:"totalAmount"`
+	Schedule            []tx.Installment `json:"schedule"`
+}
+
+func FromInstallmentSchedule(model *tx.Transaction, schedule []tx.Installment) InstallmentScheduleResponseDto {
+	response := InstallmentScheduleResponseDto{
+		TransactionID:       model.ID,
+		Installments:        max(model.Installments, 1),
+		Plan:                model.InstallmentPlan,
+		MonthlyInterestRate: model.InstallmentInterestRate,
+		Amount:              model.Amount,
+		TotalAmount:         model.InstallmentsTotal,
+		Schedule:            schedule,
+	}
+	if response.TotalAmount == 0 {
+		response.TotalAmount = model.Amount
+	}
+	if response.Schedule == nil {
+		response.Schedule = []tx.Installment{}
+	}
+	return response
 }
 
 type PaginatedTransactionsResponseDto struct {
@@ -22,7 +126,7 @@ type PaginatedTransactionsResponseDto struct {
 }
 
 func ToTransaction(dto *TransactionRequestDto) (*tx.Transaction, []error) {
-	transaction, err := tx.NewTransaction(dto.Amount, dto.PaymentMethod, dto.CurrencyCode, dto.Description)
+	transaction, err := tx.NewTransaction(dto.Amount, dto.PaymentMethod, dto.CurrencyCode, dto.Description, dto.Options()...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,8 +134,103 @@ func ToTransaction(dto *TransactionRequestDto) (*tx.Transaction, []error) {
 	return transaction, nil
 }
 
+// Options converte os campos opcionais da requisição em opções do domínio
+func (dto *TransactionRequestDto) Options() []tx.TransactionOption {
+	var opts []tx.TransactionOption
+	if len(dto.PaymentDetails) > 0 {
+		opts = append(opts, tx.WithPaymentDetails(dto.PaymentDetails))
+	}
+	if dto.PixKey != "" {
+		opts = append(opts, tx.WithPixKey(dto.PixKey))
+	}
+	if dto.Capture != nil && !*dto.Capture {
+		opts = append(opts, tx.WithoutCapture())
+	}
+	if dto.Installments > 0 {
+		opts = append(opts, tx.WithInstallments(dto.Installments))
+	}
+	if dto.ID != "" {
+		opts = append(opts, tx.WithID(dto.ID))
+	}
+	return opts
+}
+
 func FromTransaction(model *tx.Transaction) TransactionResponseDto {
-	return TransactionResponseDto{
-		ID: model.ID,
+	response := TransactionResponseDto{
+		ID:     model.ID,
+		Status: model.Status,
+	}
+
+	if model.PixPayload != "" && model.ExpiresAt != nil {
+		response.Pix = &PixChargeResponseDto{
+			TxID:      model.PixTxID,
+			Payload:   model.PixPayload,
+			QRCodeURL: "/transaction/" + model.ID + "/pix/qrcode.png",
+			ExpiresAt: *model.ExpiresAt,
+		}
 	}
+
+	if model.CardToken != "" {
+		response.Card = &CardResponseDto{
+			Token: model.CardToken,
+			Last4: model.CardLast4,
+		}
+	}
+
+	return response
+}
+
+const (
+	BatchModeAllOrNothing = "ALL_OR_NOTHING"
+	BatchModeBestEffort   = "BEST_EFFORT"
+)
+
+type TransactionBatchRequestDto struct {
+	// Mode ALL_OR_NOTHING (padrão) não grava nada se algum item for inválido; BEST_EFFORT grava os válidos
+	Mode  string                  `json:"mode"`
+	Items []TransactionRequestDto `json:"items"`
+	// IdempotencyKey vem do cabeçalho Idempotency-Key; a mesma chave devolve a resposta já gravada
+	IdempotencyKey string `json:"-"`
+}
+
+// TransactionBatchItemResultDto é o resultado de um item, identificado pela sua posição em items
+type TransactionBatchItemResultDto struct {
+	Index  int      `json:"index"`
+	ID     string   `json:"id,omitempty"`
+	Status string   `json:"status,omitempty"`
+	Code   string   `json:"code,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type TransactionBatchResponseDto struct {
+	Mode     string                          `json:"mode"`
+	Accepted int                             `json:"accepted"`
+	Rejected int                             `json:"rejected"`
+	Results  []TransactionBatchItemResultDto `json:"results"`
+	// Replayed indica que a resposta é a de um lote já gravado com a mesma Idempotency-Key
+	Replayed bool `json:"-"`
+}
+
+const (
+	IntakeQueued     = "QUEUED"
+	IntakeProcessing = "PROCESSING"
+	IntakeCompleted  = "COMPLETED"
+	IntakeRejected   = "REJECTED"
+	IntakeFailed     = "FAILED"
+)
+
+// IntakeStatusDto é o recurso de status de uma criação assíncrona (Prefer: respond-async).
+// O ID é também o ID da transação criada.
+type IntakeStatusDto struct {
+	ID                string     `json:"id"`
+	Status            string     `json:"status"`
+	TransactionID     string     `json:"transactionId,omitempty"`
+	TransactionStatus string     `json:"transactionStatus,omitempty"`
+	Code              string     `json:"code,omitempty"`
+	Errors            []string   `json:"errors,omitempty"`
+	QueuedAt          *time.Time `json:"queuedAt,omitempty"`
+	CompletedAt       *time.Time `json:"completedAt,omitempty"`
+
+	MerchantID string `json:"-"`
+	TenantID   string `json:"-"`
 }
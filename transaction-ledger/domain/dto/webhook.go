@@ -0,0 +1,23 @@
+package dto
+
+import tx "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type WebhookRequestDto struct {
+	URL string `json:"url" binding:"required"`
+	// Statuses vazio assina todas as mudanças de status
+	Statuses []string `json:"statuses"`
+}
+
+// WebhookCreatedResponseDto é a única resposta que expõe o segredo usado na assinatura
+type WebhookCreatedResponseDto struct {
+	*tx.Webhook
+	Secret string `json:"secret"`
+}
+
+type PaginatedWebhookDeliveriesResponseDto struct {
+	Data       []tx.WebhookDelivery `json:"data"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"pageSize"`
+	TotalItems int64                `json:"totalItems"`
+	TotalPages int                  `json:"totalPages"`
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrorInvalidExportFormat = errors.New("export format must be csv, jsonl or parquet")
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatJSONL   = "jsonl"
+	ExportFormatParquet = "parquet"
+)
+
+const (
+	ExportPending   = "PENDING"
+	ExportRunning   = "RUNNING"
+	ExportCompleted = "COMPLETED"
+	ExportFailed    = "FAILED"
+	ExportExpired   = "EXPIRED"
+)
+
+// ExportJob é uma exportação assíncrona; o arquivo fica no diretório local da réplica até ExpiresAt
+type ExportJob struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID  string     `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID    string     `json:"tenantId,omitempty" gorm:"type:varchar(64)"`
+	Format      string     `json:"format" gorm:"type:varchar(10);not null"`
+	Gzip        bool       `json:"gzip" gorm:"not null;default:false"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	FileName    string     `json:"fileName,omitempty" gorm:"type:text"`
+	Rows        int64      `json:"rows" gorm:"not null;default:0"`
+	SizeBytes   int64      `json:"sizeBytes" gorm:"not null;default:0"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" gorm:"type:timestamp"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty" gorm:"type:timestamp;index"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt   time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewExportJob(merchantID, tenantID, format string, gzip bool) (*ExportJob, error) {
+	format, err := NormalizeExportFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportJob{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID,
+		TenantID:   tenantID,
+		Format:     format,
+		Gzip:       gzip,
+		Status:     ExportPending,
+	}, nil
+}
+
+func NormalizeExportFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if format == "ndjson" {
+		format = ExportFormatJSONL
+	}
+	if format != ExportFormatCSV && format != ExportFormatJSONL && format != ExportFormatParquet {
+		return "", ErrorInvalidExportFormat
+	}
+	return format, nil
+}
+
+// ExportFileName é o nome sugerido para download, com a extensão do formato e .gz quando comprimido
+func ExportFileName(format string, gzip bool) string {
+	name := "transactions." + format
+	if gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+func (j *ExportJob) Complete(fileName string, rows, size int64, now time.Time, ttl time.Duration) {
+	expiresAt := now.Add(ttl)
+	j.Status = ExportCompleted
+	j.FileName = fileName
+	j.Rows = rows
+	j.SizeBytes = size
+	j.CompletedAt = &now
+	j.ExpiresAt = &expiresAt
+}
+
+func (j *ExportJob) Fail(errorMessage string, now time.Time) {
+	j.Status = ExportFailed
+	j.Error = errorMessage
+	j.CompletedAt = &now
+}
+
+func (j *ExportJob) Expire() {
+	j.Status = ExportExpired
+	j.FileName = ""
+}
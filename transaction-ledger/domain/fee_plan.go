@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorInvalidFeePercentage  = errors.New("fee percentage must be between 0 and 100")
+	ErrorInvalidFixedFee       = errors.New("fixed fee must not be negative")
+	ErrorInvalidFeeInstallment = errors.New("fee plan installment range is invalid")
+	ErrorFeePlanRetroactive    = errors.New("fee plan effective date must not be in the past")
+)
+
+// FeePlan é a taxa (MDR) cobrada do merchant: Percentage (em %, ex.: 2.99) sobre o valor bruto mais FixedFee.
+// MerchantID vazio vale para todos os merchants e CurrencyCode vazio para qualquer moeda. Os planos não são
+// alterados: uma mudança de preço é um plano novo com EffectiveFrom futuro, preservando o histórico.
+type FeePlan struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID      string    `json:"merchantId,omitempty" gorm:"type:varchar(64);index:idx_fee_plan_lookup"`
+	PaymentMethod   string    `json:"paymentMethod" gorm:"type:varchar(20);not null;index:idx_fee_plan_lookup"`
+	CurrencyCode    string    `json:"currencyCode,omitempty" gorm:"type:varchar(3)"`
+	MinInstallments int       `json:"minInstallments" gorm:"not null;default:1"`
+	MaxInstallments int       `json:"maxInstallments,omitempty" gorm:"not null;default:0"`
+	Percentage      float64   `json:"percentage" gorm:"type:decimal(7,4);not null"`
+	FixedFee        float64   `json:"fixedFee" gorm:"type:decimal(18,4);not null"`
+	EffectiveFrom   time.Time `json:"effectiveFrom" gorm:"type:timestamp;not null;index:idx_fee_plan_lookup"`
+	CreatedBy       string    `json:"createdBy,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt       time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
+
+// Prepare normaliza e valida um plano novo; now impede datas de vigência retroativas
+func (p *FeePlan) Prepare(now time.Time) []error {
+	var errs []error
+
+	p.ID = uuid.New().String()
+	p.CreatedAt = now
+	p.PaymentMethod = strings.ToUpper(p.PaymentMethod)
+	p.CurrencyCode = strings.ToUpper(p.CurrencyCode)
+	if p.MinInstallments < 1 {
+		p.MinInstallments = 1
+	}
+	if p.EffectiveFrom.IsZero() {
+		p.EffectiveFrom = now
+	}
+
+	if _, ok := PaymentMethods.Lookup(p.PaymentMethod); !ok {
+		errs = append(errs, ErrorInvalidPaymentMethod)
+	}
+	if _, ok := Currencies.Lookup(p.CurrencyCode); p.CurrencyCode != "" && !ok {
+		errs = append(errs, ErrorInvalidCurrencyCode)
+	}
+	if p.Percentage < 0 || p.Percentage > 100 {
+		errs = append(errs, ErrorInvalidFeePercentage)
+	}
+	if p.FixedFee < 0 {
+		errs = append(errs, ErrorInvalidFixedFee)
+	}
+	if p.MaxInstallments != 0 && p.MaxInstallments < p.MinInstallments {
+		errs = append(errs, ErrorInvalidFeeInstallment)
+	}
+	// tolerância de um minuto para relógios levemente dessincronizados
+	if p.EffectiveFrom.Before(now.Add(-time.Minute)) {
+		errs = append(errs, ErrorFeePlanRetroactive)
+	}
+
+	return errs
+}
+
+// Matches indica se o plano vale para a transação no instante at
+func (p *FeePlan) Matches(t *Transaction, at time.Time) bool {
+	installments := max(t.Installments, 1)
+	return p.PaymentMethod == t.PaymentMethod &&
+		(p.MerchantID == "" || p.MerchantID == t.MerchantID) &&
+		(p.CurrencyCode == "" || p.CurrencyCode == t.CurrencyCode) &&
+		installments >= p.MinInstallments &&
+		(p.MaxInstallments == 0 || installments <= p.MaxInstallments) &&
+		!p.EffectiveFrom.After(at)
+}
+
+// Specificity desempata planos concorrentes: plano do merchant vence o padrão, moeda específica vence
+// qualquer moeda e uma faixa de parcelas vence o plano sem restrição de parcelas
+func (p *FeePlan) Specificity() int {
+	score := 0
+	if p.MerchantID != "" {
+		score += 4
+	}
+	if p.CurrencyCode != "" {
+		score += 2
+	}
+	if p.MinInstallments > 1 || p.MaxInstallments != 0 {
+		score++
+	}
+	return score
+}
+
+// Fee calcula a taxa sobre o valor bruto, arredondada para a moeda e limitada ao próprio valor bruto
+func (p *FeePlan) Fee(gross float64, currencyCode string) float64 {
+	fee := gross*p.Percentage/100 + p.FixedFee
+	if currency, ok := Currencies.Lookup(currencyCode); ok {
+		fee = currency.Round(fee)
+	} else {
+		fee = math.Round(fee*100) / 100
+	}
+	return min(fee, gross)
+}
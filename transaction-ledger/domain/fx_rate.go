@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorInvalidFXRate     = errors.New("fx rate must be greater than 0")
+	ErrorFXRateUnavailable = errors.New("fx rate not available for currency pair")
+)
+
+// FXRate é a cotação de 1 unidade de Base em Quote
+type FXRate struct {
+	Base      string    `json:"base" gorm:"primaryKey;type:varchar(3)"`
+	Quote     string    `json:"quote" gorm:"primaryKey;type:varchar(3)"`
+	Rate      float64   `json:"rate" gorm:"type:decimal(24,10);not null"`
+	Source    string    `json:"source" gorm:"type:varchar(50)"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func (r *FXRate) Validate() error {
+	r.Base = strings.ToUpper(r.Base)
+	r.Quote = strings.ToUpper(r.Quote)
+
+	if _, ok := Currencies.Lookup(r.Base); !ok {
+		return ErrorInvalidCurrencyCode
+	}
+	if _, ok := Currencies.Lookup(r.Quote); !ok {
+		return ErrorInvalidCurrencyCode
+	}
+	if r.Rate <= 0 {
+		return ErrorInvalidFXRate
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrorInvalidImportFormat = errors.New("import format must be CSV or JSONL")
+
+const (
+	ImportFormatCSV   = "CSV"
+	ImportFormatJSONL = "JSONL"
+)
+
+const (
+	ImportPending   = "PENDING"
+	ImportRunning   = "RUNNING"
+	ImportCompleted = "COMPLETED"
+	ImportFailed    = "FAILED"
+)
+
+// ImportJob acompanha a carga de um arquivo de transações. O mesmo arquivo (pelo hash do conteúdo)
+// gera sempre o mesmo job para o mesmo merchant e tenant; um novo envio retoma a partir de Checkpoint em vez de começar do zero.
+type ImportJob struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID    string     `json:"merchantId,omitempty" gorm:"type:varchar(64);uniqueIndex:idx_import_job_tenant_content"`
+	TenantID      string     `json:"tenantId,omitempty" gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_import_job_tenant_content"`
+	FileName      string     `json:"fileName" gorm:"type:text"`
+	Format        string     `json:"format" gorm:"type:varchar(10);not null"`
+	ContentHash   string     `json:"contentHash" gorm:"type:varchar(64);not null;uniqueIndex:idx_import_job_tenant_content"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null"`
+	Checkpoint    int        `json:"checkpoint" gorm:"not null;default:0"`
+	ImportedRows  int        `json:"importedRows" gorm:"not null;default:0"`
+	DuplicateRows int        `json:"duplicateRows" gorm:"not null;default:0"`
+	FailedRows    int        `json:"failedRows" gorm:"not null;default:0"`
+	Error         string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt     *time.Time `json:"startedAt,omitempty" gorm:"type:timestamp"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewImportJob(merchantID, tenantID, fileName, format, contentHash string) (*ImportJob, error) {
+	format, err := NormalizeImportFormat(format, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportJob{
+		ID:          uuid.New().String(),
+		MerchantID:  merchantID,
+		TenantID:    tenantID,
+		FileName:    fileName,
+		Format:      format,
+		ContentHash: contentHash,
+		Status:      ImportPending,
+	}, nil
+}
+
+// NormalizeImportFormat usa o formato informado ou, sem ele, a extensão do arquivo
+func NormalizeImportFormat(format, fileName string) (string, error) {
+	format = strings.ToUpper(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".csv":
+			format = ImportFormatCSV
+		case ".jsonl", ".ndjson":
+			format = ImportFormatJSONL
+		}
+	}
+	if format == "NDJSON" {
+		format = ImportFormatJSONL
+	}
+	if format != ImportFormatCSV && format != ImportFormatJSONL {
+		return "", ErrorInvalidImportFormat
+	}
+	return format, nil
+}
+
+func (j *ImportJob) Start(now time.Time) {
+	j.Status = ImportRunning
+	j.Error = ""
+	j.CompletedAt = nil
+	if j.StartedAt == nil {
+		j.StartedAt = &now
+	}
+}
+
+// Advance registra um bloco de linhas já gravado; row é a última linha do bloco
+func (j *ImportJob) Advance(row, imported, duplicates, failed int) {
+	j.Checkpoint = row
+	j.ImportedRows += imported
+	j.DuplicateRows += duplicates
+	j.FailedRows += failed
+}
+
+func (j *ImportJob) Complete(now time.Time) {
+	j.Status = ImportCompleted
+	j.CompletedAt = &now
+}
+
+// Fail interrompe o job mantendo o checkpoint, para que o próximo envio do arquivo retome dali
+func (j *ImportJob) Fail(errorMessage string, now time.Time) {
+	j.Status = ImportFailed
+	j.Error = errorMessage
+	j.CompletedAt = &now
+}
+
+// Resumable indica se um novo envio do arquivo pode continuar o job
+func (j *ImportJob) Resumable(staleBefore time.Time) bool {
+	switch j.Status {
+	case ImportPending, ImportFailed:
+		return true
+	case ImportRunning:
+		// um job RUNNING sem progresso recente ficou órfão, por exemplo num restart da réplica
+		return j.UpdatedAt.Before(staleBefore)
+	}
+	return false
+}
+
+// ImportRowError é o erro de validação de uma linha; Row conta a partir de 1, sem o cabeçalho do CSV
+type ImportRowError struct {
+	ID          int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	ImportJobID string    `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_import_row_error"`
+	Row         int       `json:"row" gorm:"not null;uniqueIndex:idx_import_row_error"`
+	Errors      []string  `json:"errors" gorm:"type:jsonb;serializer:json"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorInvalidInstallments     = errors.New("installments must be between 1 and the merchant maximum")
+	ErrorInstallmentsNotAllowed  = errors.New("installments are only allowed for credit card transactions")
+	ErrorInstallmentBelowMinimum = errors.New("installment amount below merchant minimum")
+)
+
+const (
+	InstallmentPlanInterestFree = "INTEREST_FREE"
+	InstallmentPlanWithInterest = "WITH_INTEREST"
+)
+
+// Installment é uma parcela do cronograma de uma transação parcelada no cartão de crédito
+type Installment struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:uuid"`
+	TransactionID string    `json:"transactionId" gorm:"type:uuid;not null;uniqueIndex:idx_installment_number"`
+	Number        int       `json:"number" gorm:"not null;uniqueIndex:idx_installment_number"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(18,4);not null"`
+	DueDate       time.Time `json:"dueDate" gorm:"type:date;not null"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
+
+// NewInstallmentSchedule monta as parcelas em unidades mínimas da moeda para não perder centavos.
+// Sem juros o valor é dividido e a sobra do arredondamento vai para a primeira parcela; com juros
+// as parcelas são fixas pela Tabela Price. O vencimento é mensal a partir de start. O principal é o valor
+// efetivamente cobrado: o capturado, depois de uma captura parcial.
+func NewInstallmentSchedule(transaction *Transaction, monthlyRate float64, start time.Time) []Installment {
+	count := max(transaction.Installments, 1)
+
+	minorUnits := 2
+	if currency, ok := Currencies.Lookup(transaction.CurrencyCode); ok {
+		minorUnits = currency.MinorUnits
+	}
+	factor := math.Pow10(minorUnits)
+	principal := int64(math.Round(transaction.SettledGross() * factor))
+
+	amounts := make([]int64, count)
+	if monthlyRate <= 0 {
+		base := principal / int64(count)
+		for i := range amounts {
+			amounts[i] = base
+		}
+		amounts[0] += principal - base*int64(count)
+	} else {
+		payment := float64(principal) * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(count)))
+		for i := range amounts {
+			amounts[i] = int64(math.Round(payment))
+		}
+	}
+
+	schedule := make([]Installment, count)
+	for i := range schedule {
+		schedule[i] = Installment{
+			ID:            uuid.New().String(),
+			TransactionID: transaction.ID,
+			Number:        i + 1,
+			Amount:        float64(amounts[i]) / factor,
+			DueDate:       addMonths(start, i+1),
+		}
+	}
+	return schedule
+}
+
+// addMonths soma meses mantendo o dia, limitado ao último dia do mês (31/01 + 1 mês = 28 ou 29/02)
+func addMonths(start time.Time, months int) time.Time {
+	year, month, day := start.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, start.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), min(day, lastDay), 0, 0, 0, 0, start.Location())
+}
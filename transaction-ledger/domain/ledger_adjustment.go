@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LedgerAdjustmentDebit             = "DEBIT"
+	LedgerAdjustmentReasonDisputeLost = "DISPUTE_LOST"
+)
+
+// LedgerAdjustment é um lançamento avulso contra o saldo do merchant, fora do fluxo das transações.
+// DisputeID é único, então uma disputa perdida gera no máximo um débito.
+type LedgerAdjustment struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID    string    `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID      string    `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	TransactionID string    `json:"transactionId" gorm:"type:uuid;not null;index"`
+	DisputeID     *string   `json:"disputeId,omitempty" gorm:"type:uuid;uniqueIndex"`
+	Type          string    `json:"type" gorm:"type:varchar(10);not null"`
+	Reason        string    `json:"reason" gorm:"type:varchar(30);not null"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(18,4);not null"`
+	CurrencyCode  string    `json:"currencyCode" gorm:"type:varchar(3);not null"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"type:timestamp;not null;index"`
+}
+
+// NewDisputeDebit debita do merchant o valor contestado de uma disputa perdida
+func NewDisputeDebit(dispute *Dispute) *LedgerAdjustment {
+	return &LedgerAdjustment{
+		ID:            uuid.New().String(),
+		MerchantID:    dispute.MerchantID,
+		TenantID:      dispute.TenantID,
+		TransactionID: dispute.TransactionID,
+		DisputeID:     &dispute.ID,
+		Type:          LedgerAdjustmentDebit,
+		Reason:        LedgerAdjustmentReasonDisputeLost,
+		Amount:        dispute.Amount,
+		CurrencyCode:  dispute.CurrencyCode,
+	}
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+const DefaultProcessingTopic = "process-transaction"
+
+var (
+	ErrorPaymentMethodAlreadyRegistered = errors.New("payment method already registered")
+	ErrorCurrencyNotAllowedForMethod    = errors.New("currency not allowed for payment method")
+	ErrorAmountBelowMethodMinimum       = errors.New("amount below payment method minimum")
+	ErrorAmountAboveMethodMaximum       = errors.New("amount above payment method maximum")
+	ErrorMissingPaymentDetail           = errors.New("missing required payment detail")
+)
+
+// PaymentMethod descreve as regras de um meio de pagamento. Currencies vazio aceita qualquer
+// moeda habilitada e MaxAmount zero significa sem limite superior. SettlementDays é o N do D+N,
+// em dias úteis, do repasse ao merchant.
+type PaymentMethod struct {
+	Code           string   `json:"code"`
+	Currencies     []string `json:"currencies,omitempty"`
+	MinAmount      float64  `json:"minAmount"`
+	MaxAmount      float64  `json:"maxAmount,omitempty"`
+	RequiredFields []string `json:"requiredFields,omitempty"`
+	Topic          string   `json:"topic"`
+	SettlementDays int      `json:"settlementDays"`
+}
+
+func (m PaymentMethod) Validate(t *Transaction) []error {
+	var errs []error
+
+	if len(m.Currencies) > 0 && Currencies.IsEnabled(t.CurrencyCode) && !slices.Contains(m.Currencies, t.CurrencyCode) {
+		errs = append(errs, ErrorCurrencyNotAllowedForMethod)
+	}
+
+	if t.Amount > 0 && t.Amount < m.MinAmount {
+		errs = append(errs, ErrorAmountBelowMethodMinimum)
+	}
+	if m.MaxAmount > 0 && t.Amount > m.MaxAmount {
+		errs = append(errs, ErrorAmountAboveMethodMaximum)
+	}
+
+	for _, field := range m.RequiredFields {
+		if t.PaymentDetails[field] == "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrorMissingPaymentDetail, field))
+		}
+	}
+
+	return errs
+}
+
+type PaymentMethodRegistry struct {
+	mu      sync.RWMutex
+	methods map[string]PaymentMethod
+}
+
+func NewPaymentMethodRegistry(methods ...PaymentMethod) *PaymentMethodRegistry {
+	registry := &PaymentMethodRegistry{methods: make(map[string]PaymentMethod)}
+	for _, method := range methods {
+		registry.methods[method.Code] = method
+	}
+	return registry
+}
+
+func (r *PaymentMethodRegistry) Register(method PaymentMethod) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.methods[method.Code]; exists {
+		return ErrorPaymentMethodAlreadyRegistered
+	}
+	if method.Topic == "" {
+		method.Topic = DefaultProcessingTopic
+	}
+	r.methods[method.Code] = method
+	return nil
+}
+
+func (r *PaymentMethodRegistry) Lookup(code string) (PaymentMethod, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	method, ok := r.methods[code]
+	return method, ok
+}
+
+func (r *PaymentMethodRegistry) All() []PaymentMethod {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]PaymentMethod, 0, len(r.methods))
+	for _, method := range r.methods {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Code < methods[j].Code })
+	return methods
+}
+
+// Topics devolve os tópicos de processamento distintos, usados para criar e consumir os tópicos no Kafka
+func (r *PaymentMethodRegistry) Topics() []string {
+	var topics []string
+	for _, method := range r.All() {
+		if !slices.Contains(topics, method.Topic) {
+			topics = append(topics, method.Topic)
+		}
+	}
+	return topics
+}
+
+// PaymentMethods é o registro global consultado pela validação, pela API e pelo roteamento do processamento
+var PaymentMethods = NewPaymentMethodRegistry(
+	PaymentMethod{
+		Code:           PaymentMethodPIX,
+		Currencies:     []string{"BRL"},
+		MinAmount:      0.01,
+		Topic:          DefaultProcessingTopic,
+		SettlementDays: 0,
+	},
+	PaymentMethod{
+		Code:           PaymentMethodCreditCard,
+		MinAmount:      1,
+		MaxAmount:      100000,
+		Topic:          DefaultProcessingTopic,
+		SettlementDays: 30,
+	},
+	PaymentMethod{
+		Code:           PaymentMethodDebitCard,
+		MinAmount:      1,
+		MaxAmount:      50000,
+		Topic:          DefaultProcessingTopic,
+		SettlementDays: 1,
+	},
+	PaymentMethod{
+		Code:           PaymentMethodBoleto,
+		Currencies:     []string{"BRL"},
+		MinAmount:      5,
+		MaxAmount:      250000,
+		RequiredFields: []string{"payerName", "payerDocument"},
+		Topic:          "process-transaction-boleto",
+		SettlementDays: 1,
+	},
+)
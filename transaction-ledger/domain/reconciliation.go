@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorInvalidReconciliationPeriod = errors.New("reconciliation period requires from and to dates with from not after to")
+	ErrorReconciliationNotRunning    = errors.New("reconciliation is no longer running")
+)
+
+const (
+	ReconciliationRunning   = "RUNNING"
+	ReconciliationCompleted = "COMPLETED"
+	ReconciliationFailed    = "FAILED"
+)
+
+// Classificação de cada registro conciliado
+const (
+	ReconciliationMatched            = "MATCHED"
+	ReconciliationMissingInLedger    = "MISSING_IN_LEDGER"
+	ReconciliationMissingInProcessor = "MISSING_IN_PROCESSOR"
+	ReconciliationAmountMismatch     = "AMOUNT_MISMATCH"
+	ReconciliationStatusMismatch     = "STATUS_MISMATCH"
+	ReconciliationInvalidRecord      = "INVALID_RECORD"
+)
+
+// Reconciliation é o relatório da comparação de um arquivo de liquidação do processador com o ledger.
+// From e To delimitam (inclusive, em dias UTC) as transações do ledger que deveriam constar no arquivo.
+type Reconciliation struct {
+	ID                 string     `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID         string     `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID           string     `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	FileName           string     `json:"fileName" gorm:"type:text"`
+	ContentHash        string     `json:"contentHash" gorm:"type:varchar(64);not null"`
+	From               time.Time  `json:"from" gorm:"type:date;not null"`
+	To                 time.Time  `json:"to" gorm:"type:date;not null"`
+	Status             string     `json:"status" gorm:"type:varchar(20);not null"`
+	ProcessorRecords   int        `json:"processorRecords" gorm:"not null;default:0"`
+	Matched            int        `json:"matched" gorm:"not null;default:0"`
+	MissingInLedger    int        `json:"missingInLedger" gorm:"not null;default:0"`
+	MissingInProcessor int        `json:"missingInProcessor" gorm:"not null;default:0"`
+	AmountMismatches   int        `json:"amountMismatches" gorm:"not null;default:0"`
+	StatusMismatches   int        `json:"statusMismatches" gorm:"not null;default:0"`
+	InvalidRecords     int        `json:"invalidRecords" gorm:"not null;default:0"`
+	Error              string     `json:"error,omitempty" gorm:"type:text"`
+	CompletedAt        *time.Time `json:"completedAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt          time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt          time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+// ReconciliationItem é uma divergência do relatório; os registros conciliados só entram nos contadores.
+// Row é a linha do arquivo (sem o cabeçalho) e fica zerada para o que falta no processador.
+type ReconciliationItem struct {
+	ID               int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	ReconciliationID string    `json:"-" gorm:"type:uuid;not null;index"`
+	Row              int       `json:"row,omitempty" gorm:"not null;default:0"`
+	TransactionID    string    `json:"transactionId,omitempty" gorm:"type:varchar(64);index"`
+	Result           string    `json:"result" gorm:"type:varchar(30);not null"`
+	LedgerAmount     *float64  `json:"ledgerAmount,omitempty" gorm:"type:decimal(18,4)"`
+	ProcessorAmount  *float64  `json:"processorAmount,omitempty" gorm:"type:decimal(18,4)"`
+	LedgerStatus     string    `json:"ledgerStatus,omitempty" gorm:"type:varchar(20)"`
+	ProcessorStatus  string    `json:"processorStatus,omitempty" gorm:"type:varchar(40)"`
+	Message          string    `json:"message,omitempty" gorm:"type:text"`
+	CreatedAt        time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+}
+
+func NewReconciliation(merchantID, tenantID, fileName, contentHash string, from, to time.Time) (*Reconciliation, error) {
+	if from.IsZero() || to.IsZero() || from.After(to) {
+		return nil, ErrorInvalidReconciliationPeriod
+	}
+
+	return &Reconciliation{
+		ID:          uuid.New().String(),
+		MerchantID:  merchantID,
+		TenantID:    tenantID,
+		FileName:    fileName,
+		ContentHash: contentHash,
+		From:        from,
+		To:          to,
+		Status:      ReconciliationRunning,
+	}, nil
+}
+
+// Count soma o resultado de um registro ao contador correspondente
+func (r *Reconciliation) Count(result string) {
+	switch result {
+	case ReconciliationMatched:
+		r.Matched++
+	case ReconciliationMissingInLedger:
+		r.MissingInLedger++
+	case ReconciliationMissingInProcessor:
+		r.MissingInProcessor++
+	case ReconciliationAmountMismatch:
+		r.AmountMismatches++
+	case ReconciliationStatusMismatch:
+		r.StatusMismatches++
+	case ReconciliationInvalidRecord:
+		r.InvalidRecords++
+	}
+}
+
+func (r *Reconciliation) Complete(now time.Time) {
+	r.Status = ReconciliationCompleted
+	r.Error = ""
+	r.CompletedAt = &now
+}
+
+func (r *Reconciliation) Fail(message string, now time.Time) {
+	r.Status = ReconciliationFailed
+	r.Error = message
+	r.CompletedAt = &now
+}
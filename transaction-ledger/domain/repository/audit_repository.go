@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type AuditRepository interface {
+	Create(entry *domain.AuditEntry) error
+	// FindByEntity devolve as entradas da entidade em ordem de gravação
+	FindByEntity(entityType, entityID string) ([]domain.AuditEntry, error)
+}
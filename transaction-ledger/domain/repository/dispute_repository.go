@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type DisputeRepository interface {
+	// Create grava a disputa; devolve false quando já existe uma com o mesmo ProcessorDisputeID
+	Create(dispute *domain.Dispute) (bool, error)
+	FindByID(id string) (*domain.Dispute, error)
+	FindByProcessorID(processorDisputeID string) (*domain.Dispute, error)
+	// SumDisputedAmount soma as disputas da transação ainda abertas ou perdidas
+	SumDisputedAmount(transactionID string) (float64, error)
+	FindPaginated(filter DisputeFilter, page, pageSize int) ([]domain.Dispute, int64, error)
+	// UpdateIfStatus persiste a disputa somente se o status no banco ainda for expectedStatus
+	UpdateIfStatus(dispute *domain.Dispute, expectedStatus string) error
+	// Resolve grava a decisão e, para disputas perdidas, o ajuste de débito na mesma transação do banco
+	Resolve(dispute *domain.Dispute, expectedStatus string, adjustment *domain.LedgerAdjustment) error
+	// FindOverdue busca disputas OPENED cujo prazo de evidência venceu
+	FindOverdue(now time.Time, limit int) ([]domain.Dispute, error)
+	// AddEvidence anexa o arquivo somente enquanto a disputa estiver OPENED
+	AddEvidence(evidence *domain.DisputeEvidence) error
+	FindEvidence(disputeID string) ([]domain.DisputeEvidence, error)
+	FindAdjustment(disputeID string) (*domain.LedgerAdjustment, error)
+	FindAdjustments(merchantID, tenantID string, page, pageSize int) ([]domain.LedgerAdjustment, int64, error)
+}
+
+type DisputeFilter struct {
+	Status        string
+	MerchantID    string
+	TenantID      string
+	TransactionID string
+}
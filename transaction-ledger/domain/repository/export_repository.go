@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type ExportRepository interface {
+	Create(job *domain.ExportJob) error
+	FindByID(id string) (*domain.ExportJob, error)
+	Update(job *domain.ExportJob) error
+	// FindExpired busca exportações concluídas cujo arquivo já passou de ExpiresAt
+	FindExpired(now time.Time, limit int) ([]domain.ExportJob, error)
+	// FindStale busca exportações PENDING ou RUNNING sem progresso desde before, perdidas num restart
+	FindStale(before time.Time, limit int) ([]domain.ExportJob, error)
+}
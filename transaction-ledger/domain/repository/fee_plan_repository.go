@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type FeePlanRepository interface {
+	Create(plan *domain.FeePlan) error
+	FindAll(filter FeePlanFilter) ([]domain.FeePlan, error)
+	// FindEffective busca os planos do merchant e os padrões do meio de pagamento vigentes em at
+	FindEffective(merchantID, paymentMethod string, at time.Time) ([]domain.FeePlan, error)
+}
+
+type FeePlanFilter struct {
+	MerchantID    string
+	PaymentMethod string
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+type FXRateRepository interface {
+	Upsert(rates []domain.FXRate) error
+	FindAll() ([]domain.FXRate, error)
+}
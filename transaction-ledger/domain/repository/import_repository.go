@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type ImportRepository interface {
+	Create(job *domain.ImportJob) error
+	FindByID(id string) (*domain.ImportJob, error)
+	FindByContentHash(merchantID, tenantID, contentHash string) (*domain.ImportJob, error)
+	// Claim passa o job para RUNNING se ninguém o estiver processando; false quando outro processo já o pegou
+	Claim(job *domain.ImportJob, staleBefore time.Time) (bool, error)
+	// SaveProgress grava os erros do bloco e o avanço do checkpoint numa mesma transação do banco
+	SaveProgress(job *domain.ImportJob, rowErrors []domain.ImportRowError) error
+	Update(job *domain.ImportJob) error
+	FindRowErrors(jobID string, page, pageSize int) ([]domain.ImportRowError, int64, error)
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+
+// InstallmentRepository só lê: as parcelas são gravadas junto com a transação, em TransactionRepository.CreateBatch
+// e, depois de uma captura parcial, em TransactionRepository.UpdateIfStatusWithSchedule
+type InstallmentRepository interface {
+	FindByTransactionID(transactionID string) ([]domain.Installment, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type ReconciliationRepository interface {
+	Create(reconciliation *domain.Reconciliation) error
+	FindByID(id string) (*domain.Reconciliation, error)
+	// UpdateIfRunning persiste o relatório somente se ele ainda estiver RUNNING no banco; senão devolve
+	// domain.ErrorReconciliationNotRunning. Também renova updated_at, que serve de heartbeat.
+	UpdateIfRunning(reconciliation *domain.Reconciliation) error
+	// FailStale encerra como FAILED os relatórios RUNNING sem atualização desde before e devolve quantos
+	FailStale(before time.Time, message string, now time.Time) (int64, error)
+	SaveItems(items []domain.ReconciliationItem) error
+	// FindItems pagina as divergências do relatório; result vazio traz todas
+	FindItems(reconciliationID, result string, page, pageSize int) ([]domain.ReconciliationItem, int64, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type ReviewRepository interface {
+	Create(review *domain.Review) error
+	FindByID(id string) (*domain.Review, error)
+	// Decide grava a decisão e o novo status da transação na mesma transação do banco; nada é gravado se a
+	// revisão já foi decidida ou se a transação saiu de REVIEW
+	Decide(review *domain.Review, transaction *domain.Transaction) error
+	FindPaginated(filter ReviewFilter, page, pageSize int) ([]domain.Review, int64, error)
+	FindOverdue(now time.Time, limit int) ([]domain.Review, error)
+}
+
+type ReviewFilter struct {
+	Status     string
+	MerchantID string
+	TenantID   string
+}
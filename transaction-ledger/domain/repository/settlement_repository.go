@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+// SettlementBatchFilter restringe a listagem de lotes; campos vazios não filtram
+type SettlementBatchFilter struct {
+	MerchantID    string
+	TenantID      string
+	Status        string
+	PaymentMethod string
+	CurrencyCode  string
+}
+
+type SettlementRepository interface {
+	// FindUnbatched busca transações FINISHED ainda sem lote, as mais antigas primeiro
+	FindUnbatched(limit int) ([]domain.Transaction, error)
+	// FindOrCreateOpen devolve o lote OPEN com a mesma chave (merchant, moeda, meio e dia) de batch,
+	// ou grava batch como o novo lote OPEN
+	FindOrCreateOpen(batch *domain.SettlementBatch) (*domain.SettlementBatch, error)
+	// Assign coloca no lote as transações de ids que ainda estão FINISHED e sem lote, desde que o lote
+	// continue OPEN, e recalcula os totais. Devolve quantas entraram.
+	Assign(batch *domain.SettlementBatch, ids []string) (int64, error)
+	// Close fecha o lote OPEN, trava as transações e grava os totais finais numa única transação do banco
+	Close(batch *domain.SettlementBatch) error
+	// UpdateIfStatus persiste o lote somente se o status no banco ainda for expectedStatus
+	UpdateIfStatus(batch *domain.SettlementBatch, expectedStatus string) error
+	FindByID(id string) (*domain.SettlementBatch, error)
+	FindPaginated(filter SettlementBatchFilter, page, pageSize int) ([]domain.SettlementBatch, int64, error)
+	// FindDueOpen busca os lotes OPEN com dia de referência anterior a before
+	FindDueOpen(before time.Time, limit int) ([]domain.SettlementBatch, error)
+	FindTransactions(batchID string, page, pageSize int) ([]domain.Transaction, int64, error)
+}
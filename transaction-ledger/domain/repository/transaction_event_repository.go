@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+// TransactionEventFilter restringe os eventos por transação, merchant e tenant; campos vazios não filtram
+type TransactionEventFilter struct {
+	TransactionID string
+	MerchantID    string
+	TenantID      string
+}
+
+type TransactionEventRepository interface {
+	Create(event *domain.TransactionEvent) error
+	// FindAfter devolve, em ordem, os eventos com ID maior que afterID
+	FindAfter(filter TransactionEventFilter, afterID int64, limit int) ([]domain.TransactionEvent, error)
+	// DeleteBefore apaga até limit eventos criados antes de before e devolve quantos apagou
+	DeleteBefore(before time.Time, limit int) (int64, error)
+}
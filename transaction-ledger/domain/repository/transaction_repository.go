@@ -1,12 +1,88 @@
 package repository
 
-import "github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+import (
+	"fmt"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
 
 type TransactionRepository interface {
 	Create(transaction *domain.Transaction) error
+	// CreateBatch grava a criação inteira numa única transação do banco, com inserts de várias linhas.
+	// Se a chave de idempotência já existir, devolve domain.ErrorBatchRequestExists sem gravar nada;
+	// se uma reserva de quota não couber, devolve *QuotaReservationError sem gravar nada.
+	CreateBatch(creation *TransactionCreation) error
+	// FindBatchRequest devolve o lote gravado com a chave de idempotência, ou nil se não houver
+	FindBatchRequest(merchantID, idempotencyKey string) (*domain.BatchRequest, error)
 	FindByID(id string) (*domain.Transaction, error)
+	// FindExistingIDs devolve, dentre ids, os que já estão gravados
+	FindExistingIDs(ids []string) ([]string, error)
+	// FindByIDs busca as transações de ids; as que não existem ficam de fora do resultado
+	FindByIDs(ids []string) ([]domain.Transaction, error)
 	Update(transaction *domain.Transaction) error
+	// UpdateIfStatus persiste a transação somente se o status no banco ainda for expectedStatus. Quando o status
+	// muda, as entregas de webhook da mudança são gravadas na mesma transação do banco.
+	UpdateIfStatus(transaction *domain.Transaction, expectedStatus string) error
+	// UpdateIfStatusWithSchedule é UpdateIfStatus trocando o cronograma de parcelas na mesma transação do banco
+	UpdateIfStatusWithSchedule(transaction *domain.Transaction, expectedStatus string, schedule []domain.Installment) error
 	Delete(id string) error
 	FindAll() ([]*domain.Transaction, error)
-	FindPaginated(page, pageSize int) ([]domain.Transaction, int64, error)
+	FindPaginated(filter TransactionFilter, page, pageSize int) ([]domain.Transaction, int64, error)
+	// Stream percorre as transações do filtro em ordem de criação, entregando uma de cada vez a fn;
+	// um erro de fn interrompe a leitura e é devolvido
+	Stream(filter TransactionFilter, fn func(transaction *domain.Transaction) error) error
+	SumAmountByMerchant(merchantID, currencyCode string, since time.Time) (float64, error)
+	CountByMerchantSince(merchantID string, since time.Time) (int64, error)
+	CountByCardSince(fingerprint string, since time.Time) (int64, error)
+	// FindExpired busca cobranças pendentes e autorizações cujo prazo (expires_at) já passou
+	FindExpired(now time.Time, limit int) ([]domain.Transaction, error)
+	// FindStuckProcessing busca transações aguardando o processamento (PENDING, CAPTURE_PENDING ou VOID_PENDING)
+	// sem nenhuma atualização desde before, as mais antigas primeiro
+	FindStuckProcessing(before time.Time, limit int) ([]domain.Transaction, error)
+}
+
+// TransactionCreation é tudo o que a criação de transações grava: as transações e os registros que
+// dependem delas, inclusive as entregas de webhook da criação. Ou tudo é gravado, ou nada.
+type TransactionCreation struct {
+	Transactions []*domain.Transaction
+	Installments []domain.Installment
+	Reviews      []*domain.Review
+	// Cards são os cartões tokenizados das transações; uma criação recusada não deixa cartão no cofre
+	Cards []*cardvault.VaultedCard
+	// BatchRequest, quando informado, registra a Idempotency-Key do lote com a resposta devolvida
+	BatchRequest *domain.BatchRequest
+	// Quotas são conferidas sob a trava de domain.DailyQuota, na mesma transação do banco que os inserts
+	Quotas []QuotaReservation
+}
+
+// QuotaReservation é o valor que a criação consome da quota diária de um merchant numa moeda
+type QuotaReservation struct {
+	MerchantID   string
+	CurrencyCode string
+	// Day é o início do dia (UTC) da quota; contam as transações criadas a partir dele
+	Day    time.Time
+	Amount float64
+	Limit  float64
+}
+
+// QuotaReservationError indica a reserva que não coube no que restava da quota
+type QuotaReservationError struct {
+	Reservation QuotaReservation
+	Used        float64
+}
+
+func (e *QuotaReservationError) Error() string {
+	return fmt.Sprintf("quota reservation of %.2f %s for merchant %s exceeds the limit: used %.2f of %.2f",
+		e.Reservation.Amount, e.Reservation.CurrencyCode, e.Reservation.MerchantID, e.Used, e.Reservation.Limit)
+}
+
+// TransactionFilter restringe as consultas; campos vazios não filtram e CreatedTo é exclusivo
+type TransactionFilter struct {
+	MerchantID  string
+	TenantID    string
+	Statuses    []string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
 }
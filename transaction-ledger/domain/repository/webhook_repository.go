@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+type WebhookRepository interface {
+	Create(webhook *domain.Webhook) error
+	FindByID(id string) (*domain.Webhook, error)
+	// FindByMerchant lista os webhooks do merchant; tenantID vazio não filtra por tenant
+	FindByMerchant(merchantID, tenantID string) ([]domain.Webhook, error)
+	Delete(id string) error
+}
+
+type WebhookDeliveryRepository interface {
+	CreateBatch(deliveries []domain.WebhookDelivery) error
+	FindByID(id string) (*domain.WebhookDelivery, error)
+	FindByWebhook(webhookID string, page, pageSize int) ([]domain.WebhookDelivery, int64, error)
+	// ClaimDue reserva as entregas vencidas adiando next_attempt_at em lease, para que outras réplicas não as peguem
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	Update(delivery *domain.WebhookDelivery) error
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorReviewAlreadyDecided = errors.New("review already decided")
+	ErrorReviewerRequired     = errors.New("reviewer is required")
+)
+
+const (
+	ReviewPending  = "PENDING"
+	ReviewApproved = "APPROVED"
+	ReviewRejected = "REJECTED"
+	ReviewExpired  = "EXPIRED"
+)
+
+// Review é o item da fila de revisão manual criado quando o motor de risco sinaliza uma transação
+type Review struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:uuid"`
+	TransactionID string     `json:"transactionId" gorm:"type:uuid;not null;uniqueIndex"`
+	MerchantID    string     `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID      string     `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	RiskScore     int        `json:"riskScore"`
+	RiskReasons   string     `json:"riskReasons,omitempty" gorm:"type:text"`
+	Reviewer      string     `json:"reviewer,omitempty" gorm:"type:varchar(255)"`
+	Notes         string     `json:"notes,omitempty" gorm:"type:text"`
+	DueAt         time.Time  `json:"dueAt" gorm:"type:timestamp;not null;index"`
+	DecidedAt     *time.Time `json:"decidedAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewReview(transaction *Transaction, sla time.Duration) *Review {
+	return &Review{
+		ID:            uuid.New().String(),
+		TransactionID: transaction.ID,
+		MerchantID:    transaction.MerchantID,
+		TenantID:      transaction.TenantID,
+		Status:        ReviewPending,
+		RiskScore:     transaction.RiskScore,
+		RiskReasons:   transaction.RiskReasons,
+		DueAt:         time.Now().Add(sla),
+	}
+}
+
+func (r *Review) decide(status, reviewer, notes string) error {
+	if r.Status != ReviewPending {
+		return ErrorReviewAlreadyDecided
+	}
+	if reviewer == "" {
+		return ErrorReviewerRequired
+	}
+
+	now := time.Now()
+	r.Status = status
+	r.Reviewer = reviewer
+	r.Notes = notes
+	r.DecidedAt = &now
+	return nil
+}
+
+func (r *Review) Approve(reviewer, notes string) error {
+	return r.decide(ReviewApproved, reviewer, notes)
+}
+
+func (r *Review) Reject(reviewer, notes string) error {
+	return r.decide(ReviewRejected, reviewer, notes)
+}
+
+func (r *Review) Expire() error {
+	return r.decide(ReviewExpired, "sla", "prazo de revisão expirado")
+}
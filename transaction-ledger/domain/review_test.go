@@ -0,0 +1,41 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReview_Lifecycle(t *testing.T) {
+	// Arrange
+	transaction, _ := domain.NewTransaction(100, domain.PaymentMethodPIX, "BRL", "Teste")
+	transaction.ApplyRiskAssessment(60, domain.RiskDecisionReview, []string{"high-amount"})
+	review := domain.NewReview(transaction, time.Hour)
+
+	// Act
+	err := review.Approve("analyst@company", "cliente conhecido")
+	secondErr := review.Reject("analyst@company", "")
+
+	// Assert
+	assert.Equal(t, domain.TransactionReview, transaction.Status)
+	assert.Equal(t, "high-amount", review.RiskReasons)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ReviewApproved, review.Status)
+	assert.NotNil(t, review.DecidedAt)
+	assert.ErrorIs(t, secondErr, domain.ErrorReviewAlreadyDecided)
+}
+
+func TestReview_RequiresReviewer(t *testing.T) {
+	// Arrange
+	transaction, _ := domain.NewTransaction(100, domain.PaymentMethodPIX, "BRL", "Teste")
+	review := domain.NewReview(transaction, time.Hour)
+
+	// Act
+	err := review.Reject("", "sem revisor")
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrorReviewerRequired)
+	assert.Equal(t, domain.ReviewPending, review.Status)
+}
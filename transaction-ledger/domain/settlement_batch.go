@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorSettlementBatchNotOpen   = errors.New("settlement batch is not open")
+	ErrorSettlementBatchNotClosed = errors.New("settlement batch is not closed")
+	ErrorPayoutReferenceRequired  = errors.New("payout reference is required")
+)
+
+// Ciclo de vida do lote: OPEN recebe transações, CLOSED trava as transações e aguarda o repasse,
+// PAID registra o repasse feito ao merchant
+const (
+	SettlementBatchOpen   = "OPEN"
+	SettlementBatchClosed = "CLOSED"
+	SettlementBatchPaid   = "PAID"
+)
+
+// SettlementBatch agrupa as transações FINISHED de um merchant (e tenant), moeda e meio de pagamento criadas no
+// mesmo dia (ReferenceDate). Só existe um lote OPEN por chave; transações que chegam depois do
+// fechamento abrem um novo lote para o mesmo dia.
+type SettlementBatch struct {
+	ID                     string     `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID             string     `json:"merchantId" gorm:"type:varchar(64);not null;index;uniqueIndex:idx_settlement_batches_open_tenant,where:status = 'OPEN'"`
+	TenantID               string     `json:"tenantId,omitempty" gorm:"type:varchar(64);not null;default:'';index;uniqueIndex:idx_settlement_batches_open_tenant,where:status = 'OPEN'"`
+	CurrencyCode           string     `json:"currencyCode" gorm:"type:varchar(3);not null;uniqueIndex:idx_settlement_batches_open_tenant,where:status = 'OPEN'"`
+	PaymentMethod          string     `json:"paymentMethod" gorm:"type:varchar(20);not null;uniqueIndex:idx_settlement_batches_open_tenant,where:status = 'OPEN'"`
+	ReferenceDate          time.Time  `json:"referenceDate" gorm:"type:date;not null;uniqueIndex:idx_settlement_batches_open_tenant,where:status = 'OPEN'"`
+	ExpectedSettlementDate time.Time  `json:"expectedSettlementDate" gorm:"type:date;not null;index"`
+	Status                 string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	TransactionCount       int        `json:"transactionCount" gorm:"not null;default:0"`
+	GrossAmount            float64    `json:"grossAmount" gorm:"type:decimal(18,4);not null;default:0"`
+	FeeAmount              float64    `json:"feeAmount" gorm:"type:decimal(18,4);not null;default:0"`
+	NetAmount              float64    `json:"netAmount" gorm:"type:decimal(18,4);not null;default:0"`
+	PayoutReference        string     `json:"payoutReference,omitempty" gorm:"type:varchar(100)"`
+	ClosedAt               *time.Time `json:"closedAt,omitempty" gorm:"type:timestamp"`
+	PaidAt                 *time.Time `json:"paidAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt              time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt              time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewSettlementBatch(merchantID, tenantID, currencyCode, paymentMethod string, referenceDate, expectedSettlementDate time.Time) *SettlementBatch {
+	return &SettlementBatch{
+		ID:                     uuid.New().String(),
+		MerchantID:             merchantID,
+		TenantID:               tenantID,
+		CurrencyCode:           currencyCode,
+		PaymentMethod:          paymentMethod,
+		ReferenceDate:          referenceDate,
+		ExpectedSettlementDate: expectedSettlementDate,
+		Status:                 SettlementBatchOpen,
+	}
+}
+
+// ApplyTotals registra os totais recalculados a partir das transações do lote
+func (b *SettlementBatch) ApplyTotals(count int, gross, fee float64) {
+	b.TransactionCount = count
+	b.GrossAmount = gross
+	b.FeeAmount = fee
+	b.NetAmount = gross - fee
+	if currency, ok := Currencies.Lookup(b.CurrencyCode); ok {
+		b.GrossAmount = currency.Round(gross)
+		b.FeeAmount = currency.Round(fee)
+		b.NetAmount = currency.Round(gross - fee)
+	}
+}
+
+func (b *SettlementBatch) Close(now time.Time) error {
+	if b.Status != SettlementBatchOpen {
+		return ErrorSettlementBatchNotOpen
+	}
+	b.Status = SettlementBatchClosed
+	b.ClosedAt = &now
+	return nil
+}
+
+func (b *SettlementBatch) MarkPaid(payoutReference string, now time.Time) error {
+	if b.Status != SettlementBatchClosed {
+		return ErrorSettlementBatchNotClosed
+	}
+	if payoutReference == "" {
+		return ErrorPayoutReferenceRequired
+	}
+	b.Status = SettlementBatchPaid
+	b.PayoutReference = payoutReference
+	b.PaidAt = &now
+	return nil
+}
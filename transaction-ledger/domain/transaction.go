@@ -4,44 +4,147 @@ import (
 	"encoding/json"
 	"errors"
 	"math"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/NathanGdS/transaction-hub/pkg/pixkey"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrorInvalidPaymentMethod = errors.New("payment method must be PIX or CREDIT_CARD")
-	ErrorInvalidCurrencyCode  = errors.New("currency code must be BRL or USD")
+	ErrorInvalidPaymentMethod = errors.New("payment method is not supported")
+	ErrorInvalidCurrencyCode  = errors.New("currency code is not supported")
 	ErrorInvalidAmount        = errors.New("amount must be greater than 0")
 	ErrorInvalidDescription   = errors.New("description is required")
+	ErrorPixKeyNotAllowed     = errors.New("pix key is only allowed for PIX transactions")
+	ErrorCardNotAllowed       = errors.New("card data is only allowed for card transactions")
+
+	ErrorAuthorizationNotAllowed   = errors.New("authorization without capture is only allowed for credit card transactions")
+	ErrorTransactionNotAuthorized  = errors.New("transaction is not authorized")
+	ErrorAuthorizationExpired      = errors.New("authorization has expired")
+	ErrorInvalidCaptureAmount      = errors.New("capture amount must be greater than 0 and not exceed the authorized amount")
+	ErrorTransactionStatusChanged  = errors.New("transaction status changed concurrently")
+	ErrorTransactionLocked         = errors.New("transaction is locked by a closed settlement batch")
+	ErrorTransactionNotCancellable = errors.New("only PENDING transactions can be cancelled")
 )
 
+// ErrorMessageProcessingAbandoned é o motivo gravado quando o varredor desiste de uma transação sem retorno do processamento
+const ErrorMessageProcessingAbandoned = "sem retorno do processamento após as republicações"
+
+// ErrorMessageAuthorizationExpired marca o VOID pedido pela expiração da autorização; quando a reserva é
+// liberada, a transação termina em EXPIRED e não em VOIDED
+const ErrorMessageAuthorizationExpired = "autorização expirada sem captura"
+
+// ErrorMessagePublishFailed é o motivo gravado quando a captura ou o cancelamento não chegou ao processamento
+const ErrorMessagePublishFailed = "não foi possível enviar a etapa ao processamento"
+
 const (
 	PaymentMethodPIX        = "PIX"
 	PaymentMethodCreditCard = "CREDIT_CARD"
+	PaymentMethodDebitCard  = "DEBIT_CARD"
+	PaymentMethodBoleto     = "BOLETO"
+)
+
+const (
+	TransactionPending   = "PENDING"
+	TransactionFinished  = "FINISHED"
+	TransactionFailed    = "FAILED"
+	TransactionReview    = "REVIEW"
+	TransactionExpired   = "EXPIRED"
+	TransactionCancelled = "CANCELLED"
+
+	TransactionAuthorized     = "AUTHORIZED"
+	TransactionCapturePending = "CAPTURE_PENDING"
+	TransactionVoidPending    = "VOID_PENDING"
+	TransactionVoided         = "VOIDED"
 )
 
+// QuotaReleasedStatuses são os status que não consomem a quota diária: a cobrança não aconteceu ou a
+// reserva no cartão foi liberada
+var QuotaReleasedStatuses = []string{TransactionFailed, TransactionVoided, TransactionCancelled, TransactionExpired}
+
+// Operações executadas pelo transaction-processment, derivadas do status da transação publicada
 const (
-	TransactionPending  = "PENDING"
-	TransactionFinished = "FINISHED"
-	TransactionFailed   = "FAILED"
+	OperationSale      = "SALE"
+	OperationAuthorize = "AUTHORIZE"
+	OperationCapture   = "CAPTURE"
+	OperationVoid      = "VOID"
+)
+
+// CancelTransactionTopic leva os pedidos de cancelamento de transações PENDING ao transaction-processment
+const CancelTransactionTopic = "cancel-transaction"
+
+const (
+	RiskDecisionApprove = "APPROVE"
+	RiskDecisionReview  = "REVIEW"
+	RiskDecisionDecline = "DECLINE"
 )
 
 type Transaction struct {
 	Mu sync.Mutex `gorm:"-" json:"-"`
 
-	ID            string         `json:"id" gorm:"primaryKey;type:uuid"`
-	Amount        float64        `json:"amount" gorm:"type:decimal(10,2);not null"`
-	PaymentMethod string         `json:"paymentMethod" gorm:"type:varchar(20);not null"`
-	CurrencyCode  string         `json:"currencyCode" gorm:"type:varchar(3);not null"`
-	Description   string         `json:"description" gorm:"type:text;not null"`
-	Status        string         `json:"status" gorm:"type:varchar(20);not null"`
-	ErrorMessage  string         `json:"error,omitempty" gorm:"type:text"`
-	CreatedAt     time.Time      `json:"createdAt" gorm:"type:timestamp;not null"`
-	UpdatedAt     time.Time      `json:"updatedAt" gorm:"type:timestamp;not null"`
-	DeletedAt     gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	ID            string  `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID    string  `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID      string  `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	Amount        float64 `json:"amount" gorm:"type:decimal(18,4);not null"`
+	PaymentMethod string  `json:"paymentMethod" gorm:"type:varchar(20);not null"`
+	CurrencyCode  string  `json:"currencyCode" gorm:"type:varchar(3);not null"`
+	Description   string  `json:"description" gorm:"type:text;not null"`
+	Status        string  `json:"status" gorm:"type:varchar(20);not null;index:idx_transactions_status_updated_at,priority:1"`
+	ErrorMessage  string  `json:"error,omitempty" gorm:"type:text"`
+	// RedriveAttempts conta as republicações feitas pelo varredor; segue na mensagem para o processamento
+	RedriveAttempts int    `json:"redriveAttempts,omitempty" gorm:"not null;default:0"`
+	RiskScore       int    `json:"riskScore" gorm:"not null;default:0"`
+	RiskDecision    string `json:"riskDecision,omitempty" gorm:"type:varchar(10)"`
+	RiskReasons     string `json:"riskReasons,omitempty" gorm:"type:text"`
+
+	PaymentDetails map[string]string `json:"paymentDetails,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	PixKey     string     `json:"pixKey,omitempty" gorm:"type:varchar(77)"`
+	PixKeyType string     `json:"pixKeyType,omitempty" gorm:"type:varchar(10)"`
+	PixTxID    string     `json:"pixTxId,omitempty" gorm:"type:varchar(35);index"`
+	PixPayload string     `json:"pixPayload,omitempty" gorm:"type:text"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty" gorm:"type:timestamp;index"`
+
+	CardToken string `json:"cardToken,omitempty" gorm:"type:varchar(40);index"`
+	CardLast4 string `json:"cardLast4,omitempty" gorm:"type:varchar(4)"`
+	// CardFingerprint é o HMAC do PAN, igual para todos os tokens do mesmo cartão; usado pelas regras de velocidade
+	CardFingerprint string `json:"-" gorm:"type:varchar(64);index"`
+
+	AuthorizationOnly bool    `json:"authorizationOnly,omitempty" gorm:"not null;default:false"`
+	CapturedAmount    float64 `json:"capturedAmount,omitempty" gorm:"type:decimal(18,4)"`
+
+	Installments            int     `json:"installments,omitempty" gorm:"not null;default:1"`
+	InstallmentPlan         string  `json:"installmentPlan,omitempty" gorm:"type:varchar(20)"`
+	InstallmentInterestRate float64 `json:"installmentInterestRate,omitempty" gorm:"type:decimal(8,6)"`
+	InstallmentsTotal       float64 `json:"installmentsTotal,omitempty" gorm:"type:decimal(18,4)"`
+
+	GrossAmount float64 `json:"grossAmount,omitempty" gorm:"type:decimal(18,4)"`
+	FeeAmount   float64 `json:"feeAmount,omitempty" gorm:"type:decimal(18,4)"`
+	NetAmount   float64 `json:"netAmount,omitempty" gorm:"type:decimal(18,4)"`
+	FeePlanID   string  `json:"feePlanId,omitempty" gorm:"type:uuid"`
+
+	SettlementCurrency string     `json:"settlementCurrency,omitempty" gorm:"type:varchar(3)"`
+	SettlementAmount   float64    `json:"settlementAmount,omitempty" gorm:"type:decimal(18,4)"`
+	SettlementRate     float64    `json:"settlementRate,omitempty" gorm:"type:decimal(24,10)"`
+	BaseCurrency       string     `json:"baseCurrency,omitempty" gorm:"type:varchar(3)"`
+	BaseAmount         float64    `json:"baseAmount,omitempty" gorm:"type:decimal(18,4)"`
+	BaseRate           float64    `json:"baseRate,omitempty" gorm:"type:decimal(24,10)"`
+	RateSnapshotAt     *time.Time `json:"rateSnapshotAt,omitempty" gorm:"type:timestamp"`
+
+	// CancelRequestedAt também segue na mensagem, para uma republicação não processar uma transação cancelada
+	CancelRequestedAt *time.Time `json:"cancelRequestedAt,omitempty" gorm:"type:timestamp"`
+
+	// SettlementBatchID e SettlementLocked só são gravados pelo repositório de liquidação
+	SettlementBatchID *string `json:"settlementBatchId,omitempty" gorm:"type:uuid;index"`
+	SettlementLocked  bool    `json:"settlementLocked,omitempty" gorm:"not null;default:false"`
+
+	CreatedAt time.Time      `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt time.Time      `json:"updatedAt" gorm:"type:timestamp;not null;index:idx_transactions_status_updated_at,priority:2"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
 
 	// Mu sync.Mutex `gorm:"-" json:"-"`
 }
@@ -52,11 +155,12 @@ func (t *Transaction) Validate() []error {
 		errors = append(errors, ErrorInvalidAmount)
 	}
 
-	if t.PaymentMethod != PaymentMethodPIX && t.PaymentMethod != PaymentMethodCreditCard {
+	method, ok := PaymentMethods.Lookup(t.PaymentMethod)
+	if !ok {
 		errors = append(errors, ErrorInvalidPaymentMethod)
 	}
 
-	if t.CurrencyCode != "BRL" && t.CurrencyCode != "USD" {
+	if !Currencies.IsEnabled(t.CurrencyCode) {
 		errors = append(errors, ErrorInvalidCurrencyCode)
 	}
 
@@ -64,6 +168,24 @@ func (t *Transaction) Validate() []error {
 		errors = append(errors, ErrorInvalidDescription)
 	}
 
+	if ok {
+		errors = append(errors, method.Validate(t)...)
+	}
+
+	if t.PixKey != "" {
+		errors = append(errors, t.validatePixKey()...)
+	}
+
+	if t.AuthorizationOnly && t.PaymentMethod != PaymentMethodCreditCard {
+		errors = append(errors, ErrorAuthorizationNotAllowed)
+	}
+
+	if t.Installments < 0 {
+		errors = append(errors, ErrorInvalidInstallments)
+	} else if t.Installments > 1 && t.PaymentMethod != PaymentMethodCreditCard {
+		errors = append(errors, ErrorInstallmentsNotAllowed)
+	}
+
 	if len(errors) > 0 {
 		return errors
 	}
@@ -71,15 +193,76 @@ func (t *Transaction) Validate() []error {
 	return nil
 }
 
-func NewTransaction(amount float64, paymentMethod string, currencyCode string, description string) (*Transaction, []error) {
+// TransactionOption preenche campos opcionais antes da validação
+type TransactionOption func(*Transaction)
+
+func WithPaymentDetails(details map[string]string) TransactionOption {
+	return func(t *Transaction) {
+		t.PaymentDetails = details
+	}
+}
+
+// WithPixKey informa a chave Pix do recebedor; o tipo é detectado na validação
+func WithPixKey(key string) TransactionOption {
+	return func(t *Transaction) {
+		t.PixKey = key
+	}
+}
+
+// WithoutCapture cria apenas a autorização (pré-autorização); a captura é pedida depois
+func WithoutCapture() TransactionOption {
+	return func(t *Transaction) {
+		t.AuthorizationOnly = true
+	}
+}
+
+func WithInstallments(installments int) TransactionOption {
+	return func(t *Transaction) {
+		t.Installments = installments
+	}
+}
+
+// WithID usa um ID reservado antes da criação, como o da requisição assíncrona
+func WithID(id string) TransactionOption {
+	return func(t *Transaction) {
+		t.ID = id
+	}
+}
+
+// validatePixKey detecta o tipo da chave e a normaliza (CPF/CNPJ só com dígitos, e-mail em minúsculas)
+func (t *Transaction) validatePixKey() []error {
+	if t.PaymentMethod != PaymentMethodPIX {
+		return []error{ErrorPixKeyNotAllowed}
+	}
+
+	key, err := pixkey.Parse(t.PixKey)
+	if err != nil {
+		return []error{err}
+	}
+	t.PixKey = key.Value
+	t.PixKeyType = key.Type
+	return nil
+}
+
+func NewTransaction(amount float64, paymentMethod string, currencyCode string, description string, opts ...TransactionOption) (*Transaction, []error) {
+	rounded := math.Round(amount*100) / 100
+	if currency, ok := Currencies.Lookup(currencyCode); ok {
+		rounded = currency.Round(amount)
+	}
+
 	transaction := &Transaction{
 		ID:            uuid.New().String(),
-		Amount:        math.Round(amount*100) / 100,
+		Amount:        rounded,
 		PaymentMethod: paymentMethod,
 		CurrencyCode:  currencyCode,
 		Description:   description,
 		Status:        TransactionPending,
+		Installments:  1,
+	}
+	for _, opt := range opts {
+		opt(transaction)
 	}
+
 	err := transaction.Validate()
 	if err != nil {
 		return nil, err
@@ -97,6 +280,276 @@ func (t *Transaction) ErrorProcessingTransaction(errorMessage string) {
 	t.Status = TransactionFailed
 }
 
+// Redrive conta mais uma republicação de uma transação que ficou sem retorno do processamento
+func (t *Transaction) Redrive() {
+	t.RedriveAttempts++
+}
+
+// RequestCancel registra o pedido de cancelamento; a transação segue PENDING até o processamento
+// responder. Pedidos repetidos mantêm o horário do primeiro.
+func (t *Transaction) RequestCancel(now time.Time) error {
+	if t.Status != TransactionPending {
+		return ErrorTransactionNotCancellable
+	}
+	if t.CancelRequestedAt == nil {
+		t.CancelRequestedAt = &now
+	}
+	return nil
+}
+
+// Cancelled aplica o cancelamento confirmado pelo processamento
+func (t *Transaction) Cancelled() {
+	t.Status = TransactionCancelled
+	t.ErrorMessage = ""
+}
+
+// AbandonProcessing encerra a etapa que esgotou as republicações sem retorno: a cobrança vira FAILED e
+// a captura ou o cancelamento devolve a transação a AUTHORIZED
+func (t *Transaction) AbandonProcessing() {
+	t.OperationFailed(t.ProcessingOperation(), ErrorMessageProcessingAbandoned)
+}
+
+// ProcessingAbandoned indica que a transação foi encerrada pelo varredor, e não pelo processamento
+func (t *Transaction) ProcessingAbandoned() bool {
+	return t.Status == TransactionFailed && t.ErrorMessage == ErrorMessageProcessingAbandoned
+}
+
+// ApplyRiskAssessment registra o resultado do motor de risco; transações recusadas já nascem como FAILED
+func (t *Transaction) ApplyRiskAssessment(score int, decision string, reasons []string) {
+	t.RiskScore = score
+	t.RiskDecision = decision
+	t.RiskReasons = strings.Join(reasons, ",")
+
+	switch decision {
+	case RiskDecisionDecline:
+		t.ErrorProcessingTransaction("transação recusada pela análise de risco")
+	case RiskDecisionReview:
+		t.Status = TransactionReview
+	}
+}
+
+// ReviewApproved devolve a transação revisada ao fluxo normal de processamento
+func (t *Transaction) ReviewApproved() {
+	t.Status = TransactionPending
+}
+
+// ApplySettlementConversion guarda o snapshot da conversão para a moeda de liquidação
+func (t *Transaction) ApplySettlementConversion(currency string, amount, rate float64, at time.Time) {
+	t.SettlementCurrency = currency
+	t.SettlementAmount = amount
+	t.SettlementRate = rate
+	t.RateSnapshotAt = &at
+}
+
+// ApplyBaseConversion guarda o valor na moeda base usada para agregar relatórios
+func (t *Transaction) ApplyBaseConversion(currency string, amount, rate float64, at time.Time) {
+	t.BaseCurrency = currency
+	t.BaseAmount = amount
+	t.BaseRate = rate
+	if t.RateSnapshotAt == nil {
+		t.RateSnapshotAt = &at
+	}
+}
+
+// AttachPixCharge associa a cobrança PIX (BR Code) gerada para a transação
+func (t *Transaction) AttachPixCharge(txID, payload string, expiresAt time.Time) {
+	t.PixTxID = txID
+	t.PixPayload = payload
+	t.ExpiresAt = &expiresAt
+}
+
+// AwaitingProcessing indica que a transação ainda espera o retorno do transaction-processment
+func (t *Transaction) AwaitingProcessing() bool {
+	return t.Status == TransactionPending || t.Status == TransactionCapturePending || t.Status == TransactionVoidPending
+}
+
+// AcceptsResult indica se o retorno do processamento para operation ainda vale: a transação precisa estar
+// esperando exatamente essa etapa, ou ter sido abandonada pelo varredor. Retornos repetidos são descartados.
+func (t *Transaction) AcceptsResult(operation string) bool {
+	if t.ProcessingOperation() != operation {
+		return false
+	}
+	return t.AwaitingProcessing() || t.ProcessingAbandoned()
+}
+
+// ConsumesQuota indica se o valor da transação conta na quota diária do merchant
+func (t *Transaction) ConsumesQuota() bool {
+	return !slices.Contains(QuotaReleasedStatuses, t.Status)
+}
+
+func (t *Transaction) IsCardPayment() bool {
+	return t.PaymentMethod == PaymentMethodCreditCard || t.PaymentMethod == PaymentMethodDebitCard
+}
+
+// AttachCard referencia o cartão tokenizado no cofre; o ledger nunca guarda o PAN
+func (t *Transaction) AttachCard(token, last4 string) {
+	t.CardToken = token
+	t.CardLast4 = last4
+}
+
+// ProcessingOperation indica ao transaction-processment o que fazer com a transação publicada
+func (t *Transaction) ProcessingOperation() string {
+	switch t.Status {
+	case TransactionCapturePending:
+		return OperationCapture
+	case TransactionVoidPending:
+		return OperationVoid
+	}
+	if t.AuthorizationOnly {
+		return OperationAuthorize
+	}
+	return OperationSale
+}
+
+// Authorized registra a reserva no cartão; sem captura até expiresAt a autorização expira
+func (t *Transaction) Authorized(expiresAt time.Time) {
+	t.Status = TransactionAuthorized
+	t.ErrorMessage = ""
+	t.ExpiresAt = &expiresAt
+}
+
+// RequestCapture pede a captura total (amount zero) ou parcial da autorização
+func (t *Transaction) RequestCapture(amount float64, now time.Time) error {
+	if t.Status != TransactionAuthorized {
+		return ErrorTransactionNotAuthorized
+	}
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return ErrorAuthorizationExpired
+	}
+
+	if amount == 0 {
+		amount = t.Amount
+	}
+	if currency, ok := Currencies.Lookup(t.CurrencyCode); ok {
+		amount = currency.Round(amount)
+	}
+	if amount <= 0 || amount > t.Amount {
+		return ErrorInvalidCaptureAmount
+	}
+
+	t.CapturedAmount = amount
+	t.Status = TransactionCapturePending
+	t.RedriveAttempts = 0
+	return nil
+}
+
+func (t *Transaction) Captured() {
+	t.Status = TransactionFinished
+	t.ErrorMessage = ""
+}
+
+// RequestVoid pede a liberação da reserva de uma autorização ainda não capturada
+func (t *Transaction) RequestVoid() error {
+	if t.Status != TransactionAuthorized {
+		return ErrorTransactionNotAuthorized
+	}
+	t.Status = TransactionVoidPending
+	t.RedriveAttempts = 0
+	return nil
+}
+
+func (t *Transaction) Voided() {
+	if t.ErrorMessage == ErrorMessageAuthorizationExpired {
+		t.Status = TransactionExpired
+		return
+	}
+	t.Status = TransactionVoided
+	t.ErrorMessage = ""
+}
+
+// OperationFailed trata a falha de uma etapa; captura e cancelamento com erro mantêm a autorização válida
+func (t *Transaction) OperationFailed(operation, errorMessage string) {
+	switch operation {
+	case OperationCapture:
+		t.CapturedAmount = 0
+		fallthrough
+	case OperationVoid:
+		t.Status = TransactionAuthorized
+		t.ErrorMessage = errorMessage
+	default:
+		t.ErrorProcessingTransaction(errorMessage)
+	}
+}
+
+// ApplyInstallmentPlan registra o plano escolhido e o total efetivamente parcelado (com juros, quando houver)
+func (t *Transaction) ApplyInstallmentPlan(plan string, monthlyRate float64, schedule []Installment) {
+	total := 0.0
+	for _, installment := range schedule {
+		total += installment.Amount
+	}
+
+	t.InstallmentPlan = plan
+	t.InstallmentInterestRate = monthlyRate
+	t.InstallmentsTotal = math.Round(total*100) / 100
+	if currency, ok := Currencies.Lookup(t.CurrencyCode); ok {
+		t.InstallmentsTotal = currency.Round(total)
+	}
+}
+
+// SettledGross é o valor bruto efetivamente cobrado: o capturado nas capturas parciais, senão o valor da transação
+func (t *Transaction) SettledGross() float64 {
+	if t.CapturedAmount > 0 {
+		return t.CapturedAmount
+	}
+	return t.Amount
+}
+
+// ApplyFees registra bruto, taxa e líquido calculados pelo plano de taxas vigente (planID vazio = sem taxa)
+func (t *Transaction) ApplyFees(planID string, gross, fee float64) {
+	t.FeePlanID = planID
+	t.GrossAmount = gross
+	t.FeeAmount = fee
+	t.NetAmount = math.Round((gross-fee)*100) / 100
+	if currency, ok := Currencies.Lookup(t.CurrencyCode); ok {
+		t.NetAmount = currency.Round(gross - fee)
+	}
+}
+
+// Expire marca como expirada uma cobrança pendente cujo prazo passou. Uma autorização não capturada
+// ainda prende o limite do cartão: ela vai para VOID_PENDING e só fica EXPIRED quando a reserva é liberada.
+// Se o VOID falhar ou não chegar ao Kafka, ela volta a AUTHORIZED já vencida e a próxima varredura tenta de novo.
+func (t *Transaction) Expire(now time.Time) bool {
+	if t.ExpiresAt == nil || now.Before(*t.ExpiresAt) {
+		return false
+	}
+
+	switch t.Status {
+	case TransactionPending:
+		t.Status = TransactionExpired
+		t.ErrorMessage = "prazo de pagamento expirado"
+	case TransactionAuthorized:
+		t.Status = TransactionVoidPending
+		t.ErrorMessage = ErrorMessageAuthorizationExpired
+		t.RedriveAttempts = 0
+	default:
+		return false
+	}
+	return true
+}
+
+// ProcessingTopic é o tópico do Kafka em que o meio de pagamento da transação é processado
+func (t *Transaction) ProcessingTopic() string {
+	if method, ok := PaymentMethods.Lookup(t.PaymentMethod); ok && method.Topic != "" {
+		return method.Topic
+	}
+	return DefaultProcessingTopic
+}
+
+// transactionJSON tem os mesmos campos de Transaction sem os métodos, evitando recursão no MarshalJSON
+type transactionJSON Transaction
+
+// MarshalJSON mascara a chave Pix nas respostas da API e nos logs
+func (t *Transaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*transactionJSON
+		PixKey string `json:"pixKey,omitempty"`
+	}{
+		transactionJSON: (*transactionJSON)(t),
+		PixKey:          pixkey.Mask(t.PixKey),
+	})
+}
+
+// ToJson serializa a mensagem publicada no Kafka, com a chave Pix completa para o processador
 func (t *Transaction) ToJson() ([]byte, error) {
-	return json.Marshal(t)
+	return json.Marshal((*transactionJSON)(t))
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// TransactionEvent é uma mudança de status já persistida; o ID sequencial serve de Last-Event-ID nos streams
+type TransactionEvent struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	TransactionID  string    `json:"transactionId" gorm:"type:uuid;not null;index"`
+	MerchantID     string    `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID       string    `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	Status         string    `json:"status" gorm:"type:varchar(20);not null"`
+	PreviousStatus string    `json:"previousStatus,omitempty" gorm:"type:varchar(20)"`
+	Amount         float64   `json:"amount" gorm:"type:decimal(18,4);not null"`
+	CurrencyCode   string    `json:"currencyCode" gorm:"type:varchar(3);not null"`
+	PaymentMethod  string    `json:"paymentMethod" gorm:"type:varchar(20);not null"`
+	ErrorMessage   string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"type:timestamp;not null;index"`
+}
+
+func NewTransactionEvent(transaction *Transaction, previousStatus string) *TransactionEvent {
+	return &TransactionEvent{
+		TransactionID:  transaction.ID,
+		MerchantID:     transaction.MerchantID,
+		TenantID:       transaction.TenantID,
+		Status:         transaction.Status,
+		PreviousStatus: previousStatus,
+		Amount:         transaction.Amount,
+		CurrencyCode:   transaction.CurrencyCode,
+		PaymentMethod:  transaction.PaymentMethod,
+		ErrorMessage:   transaction.ErrorMessage,
+	}
+}
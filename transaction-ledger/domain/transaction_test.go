@@ -1,8 +1,11 @@
 package domain_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/NathanGdS/transaction-hub/pkg/pixkey"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
 	"github.com/stretchr/testify/assert"
@@ -85,3 +88,199 @@ func TestTransaction_Validate_InvalidDescription(t *testing.T) {
 
 	assert.Equal(t, err, []error{domain.ErrorInvalidDescription})
 }
+
+func TestTransaction_CurrencyTable(t *testing.T) {
+	// Arrange
+	defer domain.Currencies.SetEnabled([]string{"BRL", "USD"})
+	domain.Currencies.SetEnabled([]string{"BRL", "USD", "JPY"})
+
+	// Act
+	transaction, err := domain.NewTransaction(1000.6, domain.PaymentMethodCreditCard, "JPY", "Teste")
+	_, disabledErr := domain.NewTransaction(100, domain.PaymentMethodCreditCard, "EUR", "Teste")
+
+	// Assert
+	assert.Empty(t, err)
+	assert.Equal(t, 1001.0, transaction.Amount)
+	assert.Equal(t, []error{domain.ErrorInvalidCurrencyCode}, disabledErr)
+}
+
+func TestTransaction_PaymentMethodRegistry(t *testing.T) {
+	t.Run("Should reject a currency not allowed for the method", func(t *testing.T) {
+		// Act
+		transaction, err := domain.NewTransaction(100, domain.PaymentMethodPIX, "USD", "Teste")
+
+		// Assert
+		assert.Nil(t, transaction)
+		assert.Equal(t, []error{domain.ErrorCurrencyNotAllowedForMethod}, err)
+	})
+
+	t.Run("Should enforce the method amount limits", func(t *testing.T) {
+		// Act
+		_, belowErr := domain.NewTransaction(0.5, domain.PaymentMethodDebitCard, "BRL", "Teste")
+		_, aboveErr := domain.NewTransaction(100001, domain.PaymentMethodCreditCard, "BRL", "Teste")
+
+		// Assert
+		assert.Equal(t, []error{domain.ErrorAmountBelowMethodMinimum}, belowErr)
+		assert.Equal(t, []error{domain.ErrorAmountAboveMethodMaximum}, aboveErr)
+	})
+
+	t.Run("Should require the method extra fields", func(t *testing.T) {
+		// Act
+		_, missingErr := domain.NewTransaction(100, domain.PaymentMethodBoleto, "BRL", "Teste",
+			domain.WithPaymentDetails(map[string]string{"payerName": "Fulano"}))
+		transaction, err := domain.NewTransaction(100, domain.PaymentMethodBoleto, "BRL", "Teste",
+			domain.WithPaymentDetails(map[string]string{"payerName": "Fulano", "payerDocument": "12345678909"}))
+
+		// Assert
+		assert.Len(t, missingErr, 1)
+		assert.ErrorIs(t, missingErr[0], domain.ErrorMissingPaymentDetail)
+		assert.Empty(t, err)
+		assert.Equal(t, "process-transaction-boleto", transaction.ProcessingTopic())
+	})
+
+	t.Run("Should allow registering new methods", func(t *testing.T) {
+		// Arrange
+		registry := domain.NewPaymentMethodRegistry()
+
+		// Act
+		err := registry.Register(domain.PaymentMethod{Code: "WALLET"})
+		duplicatedErr := registry.Register(domain.PaymentMethod{Code: "WALLET"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, duplicatedErr, domain.ErrorPaymentMethodAlreadyRegistered)
+		assert.Equal(t, []string{domain.DefaultProcessingTopic}, registry.Topics())
+	})
+}
+
+func TestTransaction_PixKey(t *testing.T) {
+	// Arrange
+	transaction, errs := dto.ToTransaction(&dto.TransactionRequestDto{
+		Amount:        100,
+		PaymentMethod: "PIX",
+		CurrencyCode:  "BRL",
+		Description:   "Teste",
+		PixKey:        "529.982.247-25",
+	})
+
+	// Act
+	apiJSON, _ := json.Marshal(transaction)
+	kafkaJSON, _ := transaction.ToJson()
+
+	// Assert
+	assert.Empty(t, errs)
+	assert.Equal(t, "52998224725", transaction.PixKey)
+	assert.Equal(t, pixkey.TypeCPF, transaction.PixKeyType)
+	assert.Contains(t, string(apiJSON), `"pixKey":"***.982.247-**"`)
+	assert.Contains(t, string(kafkaJSON), `"pixKey":"52998224725"`)
+
+	_, errs = dto.ToTransaction(&dto.TransactionRequestDto{
+		Amount:        100,
+		PaymentMethod: "CREDIT_CARD",
+		CurrencyCode:  "BRL",
+		Description:   "Teste",
+		PixKey:        "529.982.247-25",
+	})
+	assert.Equal(t, []error{domain.ErrorPixKeyNotAllowed}, errs)
+}
+
+func TestTransaction_AuthorizeCaptureLifecycle(t *testing.T) {
+	// Arrange
+	capture := false
+	transaction, errs := dto.ToTransaction(&dto.TransactionRequestDto{
+		Amount:        100,
+		PaymentMethod: "CREDIT_CARD",
+		CurrencyCode:  "BRL",
+		Description:   "Teste",
+		Capture:       &capture,
+	})
+	now := time.Now()
+
+	// Act
+	assert.Empty(t, errs)
+	assert.Equal(t, domain.OperationAuthorize, transaction.ProcessingOperation())
+	transaction.Authorized(now.Add(time.Hour))
+
+	// Assert
+	assert.Equal(t, domain.ErrorInvalidCaptureAmount, transaction.RequestCapture(150, now))
+	assert.NoError(t, transaction.RequestCapture(40.005, now))
+	assert.Equal(t, 40.01, transaction.CapturedAmount)
+	assert.Equal(t, domain.OperationCapture, transaction.ProcessingOperation())
+
+	transaction.OperationFailed(domain.OperationCapture, "adquirente indisponível")
+	assert.Equal(t, domain.TransactionAuthorized, transaction.Status)
+
+	assert.NoError(t, transaction.RequestVoid())
+	transaction.Voided()
+	assert.Equal(t, domain.TransactionVoided, transaction.Status)
+	assert.Equal(t, domain.ErrorTransactionNotAuthorized, transaction.RequestCapture(0, now))
+
+	authorized := &domain.Transaction{Status: domain.TransactionAuthorized}
+	authorized.Authorized(now.Add(-time.Minute))
+	assert.Equal(t, domain.ErrorAuthorizationExpired, authorized.RequestCapture(0, now))
+	assert.True(t, authorized.Expire(now))
+	assert.Equal(t, domain.TransactionVoidPending, authorized.Status)
+	assert.Equal(t, domain.OperationVoid, authorized.ProcessingOperation())
+	authorized.OperationFailed(domain.OperationVoid, "adquirente indisponível")
+	assert.Equal(t, domain.TransactionAuthorized, authorized.Status)
+	assert.True(t, authorized.Expire(now))
+	authorized.Voided()
+	assert.Equal(t, domain.TransactionExpired, authorized.Status)
+
+	_, errs = dto.ToTransaction(&dto.TransactionRequestDto{
+		Amount:        100,
+		PaymentMethod: "PIX",
+		CurrencyCode:  "BRL",
+		Description:   "Teste",
+		Capture:       &capture,
+	})
+	assert.Equal(t, []error{domain.ErrorAuthorizationNotAllowed}, errs)
+}
+
+func TestTransaction_RequestCancel(t *testing.T) {
+	// Arrange
+	transaction := &domain.Transaction{Status: domain.TransactionPending}
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	err := transaction.RequestCancel(first)
+	repeatedErr := transaction.RequestCancel(first.Add(time.Minute))
+	transaction.Cancelled()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, repeatedErr)
+	assert.Equal(t, first, *transaction.CancelRequestedAt)
+	assert.Equal(t, domain.TransactionCancelled, transaction.Status)
+	assert.Equal(t, domain.ErrorTransactionNotCancellable, transaction.RequestCancel(first))
+	assert.Equal(t, domain.ErrorTransactionNotCancellable, (&domain.Transaction{Status: domain.TransactionFinished}).RequestCancel(first))
+}
+
+func TestTransaction_ConsumesQuota(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected bool
+	}{
+		{domain.TransactionPending, true},
+		{domain.TransactionReview, true},
+		{domain.TransactionAuthorized, true},
+		{domain.TransactionFinished, true},
+		{domain.TransactionFailed, false},
+		{domain.TransactionVoided, false},
+		{domain.TransactionCancelled, false},
+		{domain.TransactionExpired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			// Arrange
+			transaction := &domain.Transaction{Status: tt.status}
+
+			// Act
+			consumes := transaction.ConsumesQuota()
+
+			// Assert
+			assert.Equal(t, tt.expected, consumes)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrorInvalidWebhookURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrorInvalidWebhookStatus = errors.New("webhook subscribes to an unknown transaction status")
+	// ErrorWebhookAddressNotAllowed impede que o ledger seja usado para alcançar a rede interna
+	ErrorWebhookAddressNotAllowed = errors.New("webhook url must not point to a loopback, link-local, private or unspecified address")
+)
+
+var webhookStatuses = []string{
+	TransactionPending, TransactionFinished, TransactionFailed, TransactionReview, TransactionExpired, TransactionCancelled,
+	TransactionAuthorized, TransactionCapturePending, TransactionVoidPending, TransactionVoided,
+}
+
+const WebhookEventStatusChanged = "transaction.status_changed"
+
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// Webhook é um endpoint do merchant que recebe as mudanças de status das transações.
+// Statuses vazio assina todas as mudanças; o segredo só é exibido na criação. Um webhook com TenantID
+// só recebe as transações desse tenant; sem TenantID recebe as de todo o merchant.
+type Webhook struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid"`
+	MerchantID string    `json:"merchantId,omitempty" gorm:"type:varchar(64);index"`
+	TenantID   string    `json:"tenantId,omitempty" gorm:"type:varchar(64);index"`
+	URL        string    `json:"url" gorm:"type:text;not null"`
+	Secret     string    `json:"-" gorm:"type:varchar(80);not null"`
+	Statuses   []string  `json:"statuses,omitempty" gorm:"type:jsonb;serializer:json"`
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewWebhook(merchantID, tenantID, rawURL, secret string, statuses []string) (*Webhook, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrorInvalidWebhookURL
+	}
+
+	normalized := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if !slices.Contains(webhookStatuses, status) {
+			return nil, ErrorInvalidWebhookStatus
+		}
+		normalized = append(normalized, status)
+	}
+
+	return &Webhook{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID,
+		TenantID:   tenantID,
+		URL:        rawURL,
+		Secret:     secret,
+		Statuses:   normalized,
+		Active:     true,
+	}, nil
+}
+
+// WebhookAddressAllowed indica se o ledger pode entregar webhooks no endereço: loopback, link-local
+// (inclusive o endpoint de metadados da nuvem), privados e não especificados ficam de fora
+func WebhookAddressAllowed(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsPrivate() && !ip.IsUnspecified()
+}
+
+func (w *Webhook) Subscribes(status string) bool {
+	return w.Active && (len(w.Statuses) == 0 || slices.Contains(w.Statuses, status))
+}
+
+// WebhookDelivery é o item da fila persistente de entregas e também o log de tentativas
+type WebhookDelivery struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:uuid"`
+	WebhookID      string     `json:"webhookId" gorm:"type:uuid;not null;index"`
+	TransactionID  string     `json:"transactionId" gorm:"type:uuid;not null;index"`
+	Event          string     `json:"event" gorm:"type:varchar(50);not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_delivery_due"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt" gorm:"type:timestamp;not null;index:idx_webhook_delivery_due"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty" gorm:"type:timestamp"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty" gorm:"type:timestamp"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"type:timestamp;not null"`
+	UpdatedAt      time.Time  `json:"updatedAt" gorm:"type:timestamp;not null"`
+}
+
+func NewWebhookDelivery(webhookID, transactionID, deliveryID string, payload []byte, now time.Time) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:            deliveryID,
+		WebhookID:     webhookID,
+		TransactionID: transactionID,
+		Event:         WebhookEventStatusChanged,
+		Payload:       string(payload),
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: now,
+	}
+}
+
+type webhookPayload struct {
+	ID        string             `json:"id"`
+	Event     string             `json:"event"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Data      webhookPayloadData `json:"data"`
+}
+
+type webhookPayloadData struct {
+	PreviousStatus string       `json:"previousStatus,omitempty"`
+	Transaction    *Transaction `json:"transaction"`
+}
+
+// NewStatusChangedDeliveries monta uma entrega por webhook do merchant que assina o novo status. Webhooks de
+// um tenant não recebem as transações dos outros tenants do merchant.
+func NewStatusChangedDeliveries(webhooks []Webhook, transaction *Transaction, previousStatus string, now time.Time) ([]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0, len(webhooks))
+	for i := range webhooks {
+		if !webhooks[i].Subscribes(transaction.Status) {
+			continue
+		}
+		if webhooks[i].TenantID != "" && webhooks[i].TenantID != transaction.TenantID {
+			continue
+		}
+
+		deliveryID := uuid.New().String()
+		payload, err := json.Marshal(webhookPayload{
+			ID:        deliveryID,
+			Event:     WebhookEventStatusChanged,
+			CreatedAt: now,
+			Data: webhookPayloadData{
+				PreviousStatus: previousStatus,
+				Transaction:    transaction,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *NewWebhookDelivery(webhooks[i].ID, transaction.ID, deliveryID, payload, now))
+	}
+	return deliveries, nil
+}
+
+func (d *WebhookDelivery) Delivered(statusCode int, now time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.LastAttemptAt = &now
+	d.DeliveredAt = &now
+}
+
+// AttemptFailed agenda a próxima tentativa com backoff exponencial (base, 2x base, 4x base... até maxBackoff)
+// ou encerra a entrega como FAILED ao atingir maxAttempts
+func (d *WebhookDelivery) AttemptFailed(statusCode int, errorMessage string, now time.Time, maxAttempts int, base, maxBackoff time.Duration) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = errorMessage
+	d.LastAttemptAt = &now
+
+	if d.Attempts >= maxAttempts {
+		d.Status = WebhookDeliveryFailed
+		return
+	}
+
+	backoff := base << (d.Attempts - 1)
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	d.NextAttemptAt = now.Add(backoff)
+}
+
+// Abandon encerra a entrega sem novas tentativas, por exemplo quando o webhook foi removido
+func (d *WebhookDelivery) Abandon(errorMessage string, now time.Time) {
+	d.Status = WebhookDeliveryFailed
+	d.LastError = errorMessage
+	d.LastAttemptAt = &now
+}
+
+// Redeliver recoloca a entrega na fila para reenvio imediato, com um novo ciclo de tentativas
+func (d *WebhookDelivery) Redeliver(now time.Time) {
+	d.Status = WebhookDeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.DeliveredAt = nil
+}
@@ -0,0 +1,39 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatusChangedDeliveries(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	webhooks := []domain.Webhook{
+		{ID: "all", Active: true},
+		{ID: "failed-only", Active: true, Statuses: []string{domain.TransactionFailed}},
+		{ID: "tenant-a", TenantID: "tenant-a", Active: true},
+		{ID: "tenant-b", TenantID: "tenant-b", Active: true},
+		{ID: "inactive", Active: false},
+	}
+	transaction := &domain.Transaction{ID: "tx-1", TenantID: "tenant-b", Status: domain.TransactionFinished}
+
+	// Act
+	deliveries, err := domain.NewStatusChangedDeliveries(webhooks, transaction, domain.TransactionPending, now)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "all", deliveries[0].WebhookID)
+	assert.Equal(t, "tenant-b", deliveries[1].WebhookID)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, deliveries[0].ID, payload["id"])
+	assert.Equal(t, domain.TransactionPending, payload["data"].(map[string]any)["previousStatus"])
+	assert.Equal(t, now, deliveries[0].NextAttemptAt)
+}
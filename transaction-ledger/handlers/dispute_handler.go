@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DisputeHandler struct {
+	disputeService *services.DisputeService
+	logger         *zap.Logger
+}
+
+func NewDisputeHandler(disputeService *services.DisputeService) *DisputeHandler {
+	return &DisputeHandler{
+		disputeService: disputeService,
+		logger:         logger.Log,
+	}
+}
+
+func (h *DisputeHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeDisputesRead)
+	write := authMiddleware.RequireScope(auth.ScopeDisputesWrite)
+
+	router.GET("/disputes", read, h.GetDisputes)
+	router.GET("/disputes/:id", read, h.GetDispute)
+	router.POST("/disputes/:id/evidence", write, h.UploadEvidence)
+	router.GET("/disputes/:id/evidence/:evidenceId", read, h.DownloadEvidence)
+	router.POST("/disputes/:id/submit", write, h.SubmitEvidence)
+	router.GET("/ledger-adjustments", read, h.GetLedgerAdjustments)
+}
+
+func (h *DisputeHandler) pagination(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return 0, 0, false
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// GetDisputes lista as disputas; status e transactionId filtram a listagem
+func (h *DisputeHandler) GetDisputes(c *gin.Context) {
+	page, pageSize, ok := h.pagination(c)
+	if !ok {
+		return
+	}
+
+	filter := dRepo.DisputeFilter{
+		Status:        strings.ToUpper(c.Query("status")),
+		TransactionID: c.Query("transactionId"),
+	}
+	result, err := h.disputeService.FindPaginated(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("erro ao buscar disputas",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar disputas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *DisputeHandler) GetDispute(c *gin.Context) {
+	result, err := h.disputeService.Detail(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UploadEvidence recebe um arquivo de evidência no campo multipart "file"
+func (h *DisputeHandler) UploadEvidence(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"envie o arquivo como multipart/form-data no campo file"}})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		evidence, err := h.disputeService.AddEvidence(c.Request.Context(), c.Param("id"), part.FileName(), part)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, evidence)
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"campo file é obrigatório"}})
+}
+
+func (h *DisputeHandler) DownloadEvidence(c *gin.Context) {
+	evidence, content, err := h.disputeService.OpenEvidence(c.Request.Context(), c.Param("id"), c.Param("evidenceId"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, evidence.Size, evidence.ContentType, content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": evidence.FileName}),
+	})
+}
+
+// SubmitEvidence encerra o envio de evidências; depois dele a disputa aguarda a decisão do processador
+func (h *DisputeHandler) SubmitEvidence(c *gin.Context) {
+	dispute, err := h.disputeService.SubmitEvidence(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+func (h *DisputeHandler) GetLedgerAdjustments(c *gin.Context) {
+	page, pageSize, ok := h.pagination(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.disputeService.Adjustments(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.logger.Error("erro ao buscar ajustes do ledger",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar ajustes do ledger"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *DisputeHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDisputeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "disputa não encontrada"})
+	case errors.Is(err, services.ErrEvidenceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "evidência não encontrada"})
+	case errors.Is(err, services.ErrEvidenceTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEvidenceContentType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEvidenceEmpty), errors.Is(err, domain.ErrorEvidenceRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorDisputeNotOpen), errors.Is(err, domain.ErrorEvidenceDeadlinePassed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("erro na disputa",
+			zap.Error(err),
+			zap.String("id", c.Param("id")),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro na disputa"})
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const replayPageSize = 500
+
+// EventHandler expõe as mudanças de status das transações como Server-Sent Events
+type EventHandler struct {
+	transactionService *services.TransactionService
+	eventStream        *services.EventStreamService
+	heartbeat          time.Duration
+	logger             *zap.Logger
+}
+
+func NewEventHandler(transactionService *services.TransactionService, eventStream *services.EventStreamService, heartbeat time.Duration) *EventHandler {
+	return &EventHandler{
+		transactionService: transactionService,
+		eventStream:        eventStream,
+		heartbeat:          heartbeat,
+		logger:             logger.Log,
+	}
+}
+
+func (h *EventHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeTransactionsRead)
+
+	router.GET("/transaction/:id/events", read, h.StreamTransactionEvents)
+	router.GET("/transactions/stream", read, h.StreamMerchantEvents)
+}
+
+func (h *EventHandler) StreamTransactionEvents(c *gin.Context) {
+	transaction, err := h.transactionService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrTransactionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transação não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar transação"})
+		return
+	}
+
+	h.stream(c, dRepo.TransactionEventFilter{TransactionID: transaction.ID})
+}
+
+func (h *EventHandler) StreamMerchantEvents(c *gin.Context) {
+	filter := dRepo.TransactionEventFilter{}
+	if principal, ok := middlewares.PrincipalFromGin(c); ok {
+		filter.MerchantID = principal.MerchantID
+		filter.TenantID = principal.TenantID
+	}
+
+	h.stream(c, filter)
+}
+
+// stream envia o histórico posterior ao Last-Event-ID e depois os eventos ao vivo, até o cliente desconectar
+func (h *EventHandler) stream(c *gin.Context, filter dRepo.TransactionEventFilter) {
+	// o EventSource do navegador só manda o cabeçalho na reconexão; a query cobre a primeira conexão
+	rawLastEventID := c.GetHeader("Last-Event-ID")
+	if rawLastEventID == "" {
+		rawLastEventID = c.Query("lastEventId")
+	}
+
+	var lastEventID int64
+	if rawLastEventID != "" {
+		parsed, err := strconv.ParseInt(rawLastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Last-Event-ID inválido"})
+			return
+		}
+		lastEventID = parsed
+	}
+
+	subscription := h.eventStream.Subscribe(filter)
+	defer subscription.Close()
+
+	// só a primeira página é lida antes da resposta, para uma falha no banco ainda virar um 500
+	var page []domain.TransactionEvent
+	if lastEventID > 0 {
+		var err error
+		page, err = h.eventStream.Replay(filter, lastEventID, replayPageSize)
+		if err != nil {
+			h.logger.Error("erro ao recuperar eventos para retomada",
+				zap.Error(err),
+				zap.Int64("lastEventId", lastEventID),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao recuperar eventos"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	sent := newSentEvents()
+	// o histórico vai página a página, sem carregar todo o atraso em memória
+	for {
+		for i := range page {
+			if err := writeEvent(c.Writer, &page[i]); err != nil {
+				return
+			}
+			sent.add(page[i].ID)
+		}
+		c.Writer.Flush()
+		if len(page) < replayPageSize {
+			break
+		}
+
+		cursor := page[len(page)-1].ID
+		var err error
+		if page, err = h.eventStream.Replay(filter, cursor, replayPageSize); err != nil {
+			// a resposta já começou; o cliente reconecta com o último id recebido
+			h.logger.Error("erro ao recuperar eventos para retomada",
+				zap.Error(err),
+				zap.Int64("lastEventId", cursor),
+			)
+			return
+		}
+	}
+	// comentário inicial para o cliente saber que o stream está aberto mesmo sem eventos
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			// eventos já enviados pela retomada também chegam ao vivo enquanto o histórico era lido
+			if sent.has(event.ID) {
+				continue
+			}
+			if err := writeEvent(c.Writer, &event); err != nil {
+				return
+			}
+			sent.add(event.ID)
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// sentEventsWindow é a distância, em IDs, até a qual um evento atrasado ainda é reconhecido como repetido
+const sentEventsWindow = 10000
+
+// sentEvents lembra os IDs já enviados num stream. Os IDs saem de uma sequência, mas as transações do
+// banco confirmam fora de ordem: um evento pode chegar depois de outro com ID maior, e comparar com o
+// último ID enviado o descartaria.
+type sentEvents struct {
+	ids map[int64]struct{}
+	max int64
+}
+
+func newSentEvents() *sentEvents {
+	return &sentEvents{ids: make(map[int64]struct{})}
+}
+
+func (s *sentEvents) has(id int64) bool {
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *sentEvents) add(id int64) {
+	s.ids[id] = struct{}{}
+	s.max = max(s.max, id)
+	if len(s.ids) <= 2*sentEventsWindow {
+		return
+	}
+	for seen := range s.ids {
+		if seen < s.max-sentEventsWindow {
+			delete(s.ids, seen)
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event *domain.TransactionEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: status\ndata: %s\n\n", event.ID, data)
+	return err
+}
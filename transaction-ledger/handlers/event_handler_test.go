@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTransactionEventRepository struct {
+	mu     sync.Mutex
+	events []domain.TransactionEvent
+}
+
+func (m *memoryTransactionEventRepository) Create(event *domain.TransactionEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event.ID = int64(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryTransactionEventRepository) FindAfter(filter dRepo.TransactionEventFilter, afterID int64, limit int) ([]domain.TransactionEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []domain.TransactionEvent
+	for _, event := range m.events {
+		if event.ID > afterID && (filter.MerchantID == "" || event.MerchantID == filter.MerchantID) && (filter.TenantID == "" || event.TenantID == filter.TenantID) && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *memoryTransactionEventRepository) DeleteBefore(before time.Time, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var kept []domain.TransactionEvent
+	var deleted int64
+	for _, event := range m.events {
+		if event.CreatedAt.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	m.events = kept
+	return deleted, nil
+}
+
+// listeningBus avisa quando o serviço de eventos já está inscrito no barramento
+type listeningBus struct {
+	mu        sync.Mutex
+	handler   func(payload []byte)
+	listening chan struct{}
+}
+
+func (b *listeningBus) Publish(ctx context.Context, payload []byte) error {
+	b.mu.Lock()
+	handler := b.handler
+	b.mu.Unlock()
+
+	handler(payload)
+	return nil
+}
+
+func (b *listeningBus) Listen(ctx context.Context, handler func(payload []byte)) error {
+	b.mu.Lock()
+	b.handler = handler
+	b.mu.Unlock()
+
+	close(b.listening)
+	<-ctx.Done()
+	return nil
+}
+
+func TestStreamMerchantEvents(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &memoryTransactionEventRepository{}
+	bus := &listeningBus{listening: make(chan struct{})}
+	eventStream := services.NewEventStreamService(repo, bus, 8)
+	go eventStream.Start(ctx)
+	<-bus.listening
+
+	transaction := &domain.Transaction{ID: "0c7ad7b0-0000-4000-8000-000000000001", Amount: 10, CurrencyCode: "BRL", PaymentMethod: "PIX", Status: domain.TransactionPending}
+	eventStream.TransactionStatusChanged(ctx, transaction, "")
+	transaction.Status = domain.TransactionFinished
+	eventStream.TransactionStatusChanged(ctx, transaction, domain.TransactionPending)
+
+	router := gin.New()
+	handler := NewEventHandler(services.NewTransactionService(new(MockKafkaBroker), new(MockTransactionRepository)), eventStream, time.Minute)
+	handler.RegisterRoutes(router, middlewares.NewAuth(nil, nil))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/transactions/stream", nil)
+	require.NoError(t, err)
+	request.Header.Set("Last-Event-ID", "1")
+
+	// Act
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	readUntil := func(prefix string) string {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, "2", readUntil("id: "), "should resume after the Last-Event-ID")
+	assert.Contains(t, readUntil("data: "), `"status":"FINISHED"`)
+	readUntil(": connected")
+
+	transaction.Status = domain.TransactionFailed
+	eventStream.TransactionStatusChanged(ctx, transaction, domain.TransactionFinished)
+
+	assert.Equal(t, "3", readUntil("id: "), "should push live events after the backlog")
+	assert.Contains(t, readUntil("data: "), `"previousStatus":"FINISHED"`)
+}
+
+// startEventStream sobe o serviço de eventos e o servidor SSE e abre um stream do merchant
+func startEventStream(t *testing.T, ctx context.Context, repo *memoryTransactionEventRepository, lastEventID string) (*listeningBus, func(prefix string) string) {
+	t.Helper()
+
+	bus := &listeningBus{listening: make(chan struct{})}
+	eventStream := services.NewEventStreamService(repo, bus, 8)
+	go eventStream.Start(ctx)
+	<-bus.listening
+
+	router := gin.New()
+	handler := NewEventHandler(services.NewTransactionService(new(MockKafkaBroker), new(MockTransactionRepository)), eventStream, time.Minute)
+	handler.RegisterRoutes(router, middlewares.NewAuth(nil, nil))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/transactions/stream", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	reader := bufio.NewReader(response.Body)
+	return bus, func(prefix string) string {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+}
+
+func TestStreamMerchantEvents_OutOfOrderCommits(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, readUntil := startEventStream(t, ctx, &memoryTransactionEventRepository{}, "")
+	readUntil(": connected")
+	publish := func(id int) {
+		payload := fmt.Sprintf(`{"id":%d,"transactionId":"0c7ad7b0-0000-4000-8000-000000000001","status":"FINISHED","amount":10,"currencyCode":"BRL","paymentMethod":"PIX"}`, id)
+		require.NoError(t, bus.Publish(ctx, []byte(payload)))
+	}
+
+	// Act
+	publish(5)
+	publish(4)
+	publish(5)
+	publish(6)
+
+	// Assert
+	assert.Equal(t, "5", readUntil("id: "))
+	assert.Equal(t, "4", readUntil("id: "), "should deliver an event committed after one with a higher id")
+	assert.Equal(t, "6", readUntil("id: "), "should skip an event already sent")
+}
+
+func TestStreamMerchantEvents_PagedReplay(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &memoryTransactionEventRepository{}
+	for range replayPageSize + 2 {
+		require.NoError(t, repo.Create(&domain.TransactionEvent{TransactionID: "0c7ad7b0-0000-4000-8000-000000000001", Status: domain.TransactionFinished}))
+	}
+
+	// Act
+	_, readUntil := startEventStream(t, ctx, repo, "1")
+
+	// Assert
+	for id := 2; id <= replayPageSize+2; id++ {
+		require.Equal(t, strconv.Itoa(id), readUntil("id: "))
+	}
+	readUntil(": connected")
+}
+
+func TestStreamMerchantEvents_Tenants(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &memoryTransactionEventRepository{}
+	bus := &listeningBus{listening: make(chan struct{})}
+	eventStream := services.NewEventStreamService(repo, bus, 8)
+	go eventStream.Start(ctx)
+	<-bus.listening
+
+	tenantA := &domain.Transaction{ID: "0c7ad7b0-0000-4000-8000-00000000000a", MerchantID: "merchant-1", TenantID: "tenant-a", Amount: 10, CurrencyCode: "BRL", PaymentMethod: "PIX", Status: domain.TransactionPending}
+	tenantB := &domain.Transaction{ID: "0c7ad7b0-0000-4000-8000-00000000000b", MerchantID: "merchant-1", TenantID: "tenant-b", Amount: 20, CurrencyCode: "BRL", PaymentMethod: "PIX", Status: domain.TransactionPending}
+	eventStream.TransactionStatusChanged(ctx, tenantA, "")
+	eventStream.TransactionStatusChanged(ctx, tenantB, "")
+	eventStream.TransactionStatusChanged(ctx, tenantA, domain.TransactionPending)
+
+	keys := &auth.APIKeyStore{}
+	keys.Add("tenant-a-key", auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-a", Scopes: []string{auth.ScopeTransactionsRead}})
+	authMiddleware := middlewares.NewAuth(keys, nil)
+	router := gin.New()
+	handler := NewEventHandler(services.NewTransactionService(new(MockKafkaBroker), new(MockTransactionRepository)), eventStream, time.Minute)
+	handler.RegisterRoutes(router.Group("/", authMiddleware.Authenticate()), authMiddleware)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/transactions/stream", nil)
+	require.NoError(t, err)
+	request.Header.Set("X-API-Key", "tenant-a-key")
+	request.Header.Set("Last-Event-ID", "1")
+
+	// Act
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	readUntil := func(prefix string) string {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+
+	// Assert
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "3", readUntil("id: "), "the replay should skip the event of another tenant")
+	assert.Contains(t, readUntil("data: "), `"tenantId":"tenant-a"`)
+	readUntil(": connected")
+
+	tenantB.Status = domain.TransactionFinished
+	eventStream.TransactionStatusChanged(ctx, tenantB, domain.TransactionPending)
+	tenantA.Status = domain.TransactionFinished
+	eventStream.TransactionStatusChanged(ctx, tenantA, domain.TransactionPending)
+
+	assert.Equal(t, "5", readUntil("id: "), "the live stream should skip the event of another tenant")
+	assert.Contains(t, readUntil("data: "), `"tenantId":"tenant-a"`)
+}
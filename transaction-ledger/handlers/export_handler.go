@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var exportContentTypes = map[string]string{
+	domain.ExportFormatCSV:     "text/csv; charset=utf-8",
+	domain.ExportFormatJSONL:   "application/x-ndjson",
+	domain.ExportFormatParquet: "application/vnd.apache.parquet",
+}
+
+type ExportHandler struct {
+	transactionService *services.TransactionService
+	exportService      *services.ExportService
+	logger             *zap.Logger
+}
+
+func NewExportHandler(transactionService *services.TransactionService, exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		transactionService: transactionService,
+		exportService:      exportService,
+		logger:             logger.Log,
+	}
+}
+
+func (h *ExportHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeTransactionsRead)
+
+	router.GET("/transactions/export", read, h.ExportTransactions)
+	router.GET("/exports/:id", read, h.GetExport)
+	router.GET("/exports/:id/download", read, h.DownloadExport)
+}
+
+// ExportTransactions envia o arquivo em streaming; com Prefer: respond-async gera em segundo plano e
+// responde 202 com o endereço do job
+func (h *ExportHandler) ExportTransactions(c *gin.Context) {
+	format, err := domain.NormalizeExportFormat(c.Query("format"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+	compress, err := strconv.ParseBool(c.DefaultQuery("gzip", "false"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"gzip deve ser true ou false"}})
+		return
+	}
+
+	if prefersAsync(c.GetHeader("Prefer")) {
+		h.submitExport(c, format, compress)
+		return
+	}
+
+	contentType := exportContentTypes[format]
+	if compress {
+		contentType = "application/gzip"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+domain.ExportFileName(format, compress)+`"`)
+	c.Status(http.StatusOK)
+
+	rows, err := h.transactionService.ExportTransactions(c.Request.Context(), format, compress, c.Writer)
+	if err != nil {
+		// o cabeçalho já foi enviado; o cliente percebe a falha pelo arquivo truncado
+		h.logger.Error("erro ao exportar transações",
+			zap.Error(err),
+			zap.String("format", format),
+			zap.Int64("rows", rows),
+		)
+		c.Abort()
+	}
+}
+
+func (h *ExportHandler) submitExport(c *gin.Context, format string, compress bool) {
+	job, err := h.exportService.Submit(c.Request.Context(), format, compress)
+	if err != nil {
+		if errors.Is(err, services.ErrExportQueueFull) {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		h.logger.Error("erro ao criar exportação",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar exportação"})
+		return
+	}
+
+	c.Header("Location", "/exports/"+job.ID)
+	c.Header("Preference-Applied", "respond-async")
+	c.JSON(http.StatusAccepted, exportResponse(job))
+}
+
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, err := h.exportService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "exportação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, exportResponse(job))
+}
+
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, path, err := h.exportService.File(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrExportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "exportação não encontrada"})
+		default:
+			c.JSON(http.StatusConflict, gin.H{"error": "arquivo da exportação indisponível"})
+		}
+		return
+	}
+
+	c.FileAttachment(path, domain.ExportFileName(job.Format, job.Gzip))
+}
+
+func exportResponse(job *domain.ExportJob) *dto.ExportResponseDto {
+	response := &dto.ExportResponseDto{ExportJob: job}
+	if job.Status == domain.ExportCompleted {
+		response.DownloadURL = "/exports/" + job.ID + "/download"
+	}
+	return response
+}
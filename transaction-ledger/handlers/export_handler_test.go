@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type memoryExportRepository struct {
+	jobs map[string]domain.ExportJob
+}
+
+func (r *memoryExportRepository) Create(job *domain.ExportJob) error {
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryExportRepository) FindByID(id string) (*domain.ExportJob, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, services.ErrExportNotFound
+	}
+	return &job, nil
+}
+
+func (r *memoryExportRepository) Update(job *domain.ExportJob) error {
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryExportRepository) FindExpired(now time.Time, limit int) ([]domain.ExportJob, error) {
+	return nil, nil
+}
+
+func (r *memoryExportRepository) FindStale(before time.Time, limit int) ([]domain.ExportJob, error) {
+	return nil, nil
+}
+
+func TestExportTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.Log = mockLogger
+
+	transactions := []domain.Transaction{
+		{ID: "tx-1", MerchantID: "merchant-1", Amount: 100, CurrencyCode: "BRL", PaymentMethod: domain.PaymentMethodPIX, Status: domain.TransactionFinished, Description: "Fornecedor A", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: "tx-2", MerchantID: "merchant-1", Amount: 50.5, CurrencyCode: "BRL", PaymentMethod: domain.PaymentMethodPIX, Status: domain.TransactionPending, Description: "Fornecedor B", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+
+	t.Run("Should stream a gzip CSV with a header and one line per transaction", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		mockRepo.On("Stream", dRepo.TransactionFilter{}, mock.Anything).Return(transactions, nil)
+		service := services.NewTransactionService(new(MockKafkaBroker), mockRepo)
+		handler := NewExportHandler(service, services.NewExportService(&memoryExportRepository{jobs: map[string]domain.ExportJob{}}, service, services.ExportConfig{}))
+
+		router := gin.New()
+		handler.RegisterRoutes(router, middlewares.NewAuth(nil, nil))
+		w := httptest.NewRecorder()
+
+		// Act
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions/export?format=csv&gzip=true", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "transactions.csv.gz")
+
+		reader, err := gzip.NewReader(w.Body)
+		assert.NoError(t, err)
+		records, err := csv.NewReader(reader).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, "id", records[0][0])
+		assert.Equal(t, "tx-1", records[1][0])
+		assert.Equal(t, "50.5", records[2][3])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Should reject unknown formats", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		service := services.NewTransactionService(new(MockKafkaBroker), mockRepo)
+		handler := NewExportHandler(service, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/transactions/export?format=xlsx", nil)
+
+		// Act
+		handler.ExportTransactions(c)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "Stream", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Should write async exports to the directory and serve them by download link", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		mockRepo.On("Stream", mock.Anything, mock.Anything).Return(transactions, nil)
+		service := services.NewTransactionService(new(MockKafkaBroker), mockRepo)
+		dir := t.TempDir()
+		exports := services.NewExportService(&memoryExportRepository{jobs: map[string]domain.ExportJob{}}, service, services.ExportConfig{Dir: dir, QueueSize: 1, TTL: time.Hour})
+		handler := NewExportHandler(service, exports)
+
+		router := gin.New()
+		handler.RegisterRoutes(router, middlewares.NewAuth(nil, nil))
+
+		accepted := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/transactions/export?format=parquet", nil)
+		request.Header.Set("Prefer", "respond-async")
+
+		// Act
+		router.ServeHTTP(accepted, request)
+		job, err := exports.FindByID(context.Background(), filepath.Base(accepted.Header().Get("Location")))
+		assert.NoError(t, err)
+		exports.Run(context.Background(), job)
+
+		status := httptest.NewRecorder()
+		router.ServeHTTP(status, httptest.NewRequest(http.MethodGet, "/exports/"+job.ID, nil))
+		download := httptest.NewRecorder()
+		router.ServeHTTP(download, httptest.NewRequest(http.MethodGet, "/exports/"+job.ID+"/download", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusAccepted, accepted.Code)
+		assert.Equal(t, http.StatusOK, status.Code)
+		assert.Contains(t, status.Body.String(), `"downloadUrl":"/exports/`+job.ID+`/download"`)
+		assert.Equal(t, http.StatusOK, download.Code)
+		assert.Equal(t, "PAR1", download.Body.String()[:4])
+
+		files, _ := os.ReadDir(dir)
+		assert.Len(t, files, 1, "the partial file should be renamed, not left behind")
+	})
+}
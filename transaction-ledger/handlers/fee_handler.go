@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type FeeHandler struct {
+	feeService *services.FeeService
+	logger     *zap.Logger
+}
+
+func NewFeeHandler(feeService *services.FeeService) *FeeHandler {
+	return &FeeHandler{
+		feeService: feeService,
+		logger:     logger.Log,
+	}
+}
+
+func (h *FeeHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	router.GET("/admin/fee-plans", authMiddleware.RequireScope(auth.ScopeFeesRead), h.GetFeePlans)
+	router.POST("/admin/fee-plans", authMiddleware.RequireScope(auth.ScopeFeesWrite), h.CreateFeePlan)
+}
+
+func (h *FeeHandler) GetFeePlans(c *gin.Context) {
+	plans, err := h.feeService.ListPlans(c.Request.Context(), dRepo.FeePlanFilter{
+		MerchantID:    c.Query("merchantId"),
+		PaymentMethod: strings.ToUpper(c.Query("paymentMethod")),
+	})
+	if err != nil {
+		h.logger.Error("erro ao buscar planos de taxas",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar planos de taxas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": plans})
+}
+
+func (h *FeeHandler) CreateFeePlan(c *gin.Context) {
+	var plan domain.FeePlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	plan.CreatedBy = "admin"
+	if principal, ok := middlewares.PrincipalFromGin(c); ok {
+		plan.CreatedBy = principal.Subject
+	}
+
+	if errs := h.feeService.CreatePlan(c.Request.Context(), &plan); len(errs) > 0 {
+		h.logger.Error("erro ao criar plano de taxas",
+			zap.Any("errors", errs),
+		)
+
+		errorMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errorMessages = append(errorMessages, err.Error())
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": errorMessages})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
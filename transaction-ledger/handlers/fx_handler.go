@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type FXHandler struct {
+	fxService *services.FXService
+	logger    *zap.Logger
+}
+
+func NewFXHandler(fxService *services.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+		logger:    logger.Log,
+	}
+}
+
+func (h *FXHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	router.GET("/currencies", h.GetCurrencies)
+	router.GET("/admin/fx-rates", authMiddleware.RequireScope(auth.ScopeFXRead), h.GetRates)
+	router.PUT("/admin/fx-rates", authMiddleware.RequireScope(auth.ScopeFXWrite), h.UpsertRates)
+}
+
+func (h *FXHandler) GetCurrencies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": domain.Currencies.All()})
+}
+
+func (h *FXHandler) GetRates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"baseCurrency": h.fxService.BaseCurrency(),
+		"data":         h.fxService.Rates(),
+	})
+}
+
+func (h *FXHandler) UpsertRates(c *gin.Context) {
+	var rates []domain.FXRate
+	if err := c.ShouldBindJSON(&rates); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	if err := h.fxService.UpsertRates(c.Request.Context(), rates, "admin"); err != nil {
+		h.logger.Error("erro ao atualizar cotações",
+			zap.Error(err),
+		)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.fxService.Rates()})
+}
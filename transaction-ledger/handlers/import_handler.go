@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ImportHandler struct {
+	importService *services.ImportService
+	logger        *zap.Logger
+}
+
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		logger:        logger.Log,
+	}
+}
+
+func (h *ImportHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeTransactionsRead)
+	write := authMiddleware.RequireScope(auth.ScopeTransactionsWrite)
+
+	router.POST("/imports", write, h.CreateImport)
+	router.GET("/imports/:id", read, h.GetImport)
+	router.GET("/imports/:id/errors", read, h.GetImportErrors)
+}
+
+// CreateImport recebe o arquivo no campo multipart "file" e o lê em streaming, sem carregá-lo em memória.
+// Responde 202 quando a importação começa e 200 com o job existente quando o arquivo já foi importado
+// ou está sendo importado.
+func (h *ImportHandler) CreateImport(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"envie o arquivo como multipart/form-data no campo file"}})
+		return
+	}
+
+	var path, contentHash, fileName string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		fileName = part.FileName()
+		path, contentHash, err = h.importService.Stage(part)
+		if err != nil {
+			if errors.Is(err, services.ErrUploadTooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"errors": []string{err.Error()}})
+				return
+			}
+			h.logger.Error("erro ao receber arquivo de importação",
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao receber arquivo"})
+			return
+		}
+		break
+	}
+	if path == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"campo file é obrigatório"}})
+		return
+	}
+
+	job, start, err := h.importService.Submit(c.Request.Context(), fileName, c.Query("format"), contentHash)
+	if err != nil || !start {
+		os.Remove(path)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrorInvalidImportFormat) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		h.logger.Error("erro ao criar importação",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar importação"})
+		return
+	}
+	if !start {
+		c.JSON(http.StatusOK, job)
+		return
+	}
+
+	// a importação segue depois da resposta, em nome do mesmo principal
+	ctx := context.Background()
+	if principal, ok := middlewares.PrincipalFromGin(c); ok {
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+	snapshot := *job
+	go h.run(ctx, job, path)
+
+	c.Header("Location", "/imports/"+snapshot.ID)
+	c.JSON(http.StatusAccepted, &snapshot)
+}
+
+func (h *ImportHandler) run(ctx context.Context, job *domain.ImportJob, path string) {
+	defer os.Remove(path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		h.logger.Error("erro ao abrir arquivo de importação",
+			zap.Error(err),
+			zap.String("importId", job.ID),
+		)
+		return
+	}
+	defer file.Close()
+
+	// o erro já fica registrado no job
+	h.importService.Import(ctx, job, file)
+}
+
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	job, err := h.importService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "importação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *ImportHandler) GetImportErrors(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return
+	}
+
+	result, err := h.importService.RowErrors(c.Request.Context(), c.Param("id"), page, pageSize)
+	if err != nil {
+		if errors.Is(err, services.ErrImportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "importação não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar erros da importação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
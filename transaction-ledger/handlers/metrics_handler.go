@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/loadshed"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler expõe o estado do limitador de concorrência no formato texto do Prometheus
+type MetricsHandler struct {
+	limiter *loadshed.AdaptiveLimiter
+}
+
+func NewMetricsHandler(limiter *loadshed.AdaptiveLimiter) *MetricsHandler {
+	return &MetricsHandler{limiter: limiter}
+}
+
+func (h *MetricsHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	router.GET("/metrics", authMiddleware.RequireScope(auth.ScopeMetricsRead), h.GetMetrics)
+}
+
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	state := h.limiter.State()
+
+	var b strings.Builder
+	writeMetric(&b, "ledger_loadshed_limit", "gauge", "Limite atual de requisições simultâneas na criação", float64(state.Limit))
+	writeMetric(&b, "ledger_loadshed_in_flight", "gauge", "Requisições de criação em andamento", float64(state.InFlight))
+	writeMetric(&b, "ledger_loadshed_latency_seconds", "gauge", "Latência média recente da criação", state.Latency.Seconds())
+	writeMetric(&b, "ledger_loadshed_base_latency_seconds", "gauge", "Latência de referência da criação", state.BaseLatency.Seconds())
+	writeMetric(&b, "ledger_loadshed_accepted_total", "counter", "Requisições aceitas pelo limitador", float64(state.Accepted))
+	writeMetric(&b, "ledger_loadshed_rejected_total", "counter", "Requisições recusadas com 503 pelo limitador", float64(state.Rejected))
+	writeMetric(&b, "ledger_loadshed_overloaded", "gauge", "1 quando algum sinal externo está acima do limiar", boolMetric(state.Overloaded))
+
+	for i, signal := range state.Signals {
+		writeSignalMetric(&b, i == 0, "ledger_loadshed_signal_value", "Valor lido do sinal externo", signal.Name, signal.Value)
+	}
+	for i, signal := range state.Signals {
+		writeSignalMetric(&b, i == 0, "ledger_loadshed_signal_threshold", "Limiar do sinal externo", signal.Name, signal.Threshold)
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func writeMetric(b *strings.Builder, name, kind, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+}
+
+// writeSignalMetric escreve HELP e TYPE só na primeira série de cada métrica com labels
+func writeSignalMetric(b *strings.Builder, header bool, name, help, signal string, value float64) {
+	if header {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	fmt.Fprintf(b, "%s{signal=%q} %g\n", name, signal, value)
+}
+
+func boolMetric(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
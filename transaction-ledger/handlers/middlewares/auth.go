@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const principalContextKey = "principal"
+
+// Auth autentica requisições por API key (X-API-Key) ou bearer token JWT.
+// Sem nenhum mecanismo configurado a autenticação fica desabilitada.
+type Auth struct {
+	apiKeys *auth.APIKeyStore
+	jwt     *auth.JWTValidator
+	logger  *zap.Logger
+}
+
+func NewAuth(apiKeys *auth.APIKeyStore, jwtValidator *auth.JWTValidator) *Auth {
+	return &Auth{apiKeys: apiKeys, jwt: jwtValidator, logger: logger.Log}
+}
+
+func (a *Auth) Enabled() bool {
+	return a != nil && (a.apiKeys != nil || a.jwt != nil)
+}
+
+func (a *Auth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.Enabled() {
+			c.Next()
+			return
+		}
+
+		principal, err := a.authenticate(c)
+		if err != nil {
+			a.logger.Warn("falha de autenticação",
+				zap.Error(err),
+				zap.String("path", c.FullPath()),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "não autorizado"})
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func (a *Auth) authenticate(c *gin.Context) (*auth.Principal, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" && a.apiKeys != nil {
+		return a.apiKeys.Authenticate(key)
+	}
+
+	header := c.GetHeader("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found || a.jwt == nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return a.jwt.Validate(c.Request.Context(), strings.TrimSpace(token))
+}
+
+// RequireScope exige que o principal autenticado possua todos os escopos informados
+func (a *Auth) RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.Enabled() {
+			c.Next()
+			return
+		}
+
+		principal, ok := PrincipalFromGin(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "não autorizado"})
+			return
+		}
+
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "escopo insuficiente", "requiredScope": scope})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func PrincipalFromGin(c *gin.Context) (*auth.Principal, bool) {
+	value, exists := c.Get(principalContextKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
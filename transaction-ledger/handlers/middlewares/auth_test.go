@@ -0,0 +1,103 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthRouter(authMiddleware *middlewares.Auth) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/", authMiddleware.Authenticate())
+	api.GET("/metrics", authMiddleware.RequireScope(auth.ScopeMetricsRead), func(c *gin.Context) {
+		principal, _ := auth.PrincipalFromContext(c.Request.Context())
+		if principal == nil {
+			c.String(http.StatusOK, "anonymous")
+			return
+		}
+		c.String(http.StatusOK, principal.MerchantID+"/"+principal.TenantID)
+	})
+	return router
+}
+
+func TestAuth(t *testing.T) {
+	keys := &auth.APIKeyStore{}
+	keys.Add("metrics-key", auth.Principal{MerchantID: "merchant-1", TenantID: "tenant-a", Scopes: []string{auth.ScopeMetricsRead}})
+	keys.Add("reader-key", auth.Principal{MerchantID: "merchant-1", Scopes: []string{auth.ScopeTransactionsRead}})
+
+	tests := []struct {
+		name           string
+		authMiddleware *middlewares.Auth
+		apiKey         string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Should let every request through when no mechanism is configured",
+			authMiddleware: middlewares.NewAuth(nil, nil),
+			expectedStatus: http.StatusOK,
+			expectedBody:   "anonymous",
+		},
+		{
+			name:           "Should reject a request without credentials",
+			authMiddleware: middlewares.NewAuth(keys, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Should reject an unknown API key",
+			authMiddleware: middlewares.NewAuth(keys, nil),
+			apiKey:         "unknown-key",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Should reject a bearer token when JWT is not configured",
+			authMiddleware: middlewares.NewAuth(keys, nil),
+			authorization:  "Bearer token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Should reject a key without the required scope",
+			authMiddleware: middlewares.NewAuth(keys, nil),
+			apiKey:         "reader-key",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Should put the principal of a valid key in the request context",
+			authMiddleware: middlewares.NewAuth(keys, nil),
+			apiKey:         "metrics-key",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "merchant-1/tenant-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := newAuthRouter(tt.authMiddleware)
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/loadshed"
+	"github.com/gin-gonic/gin"
+)
+
+// LoadShed recusa com 503 as requisições acima do limite de concorrência adaptativo
+func LoadShed(limiter *loadshed.AdaptiveLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, ok := limiter.Acquire()
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(limiter.RetryAfter())))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code":  "OVERLOADED",
+				"error": "serviço sobrecarregado, tente novamente",
+			})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
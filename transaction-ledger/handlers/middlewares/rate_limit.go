@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/ratelimit"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimitKeyFunc extrai a chave do bucket; string vazia ignora o limite para a requisição
+type RateLimitKeyFunc func(c *gin.Context) string
+
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// APIKeyKey identifica o chamador pela API key ou, para JWT, pelo subject do token
+func APIKeyKey(c *gin.Context) string {
+	principal, ok := PrincipalFromGin(c)
+	if !ok {
+		return ""
+	}
+	if principal.KeyID != "" {
+		return "key:" + principal.KeyID
+	}
+	if principal.Subject != "" {
+		return "sub:" + principal.Subject
+	}
+	if principal.MerchantID != "" {
+		return "merchant:" + principal.MerchantID
+	}
+	return ""
+}
+
+func RateLimit(limiter ratelimit.Limiter, limit ratelimit.Limit, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" || !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			// se o backend falhar, não derruba o tráfego
+			logger.Log.Error("erro ao aplicar rate limit",
+				zap.Error(err),
+				zap.String("key", key),
+			)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code":  "RATE_LIMIT_EXCEEDED",
+				"error": "limite de requisições excedido",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
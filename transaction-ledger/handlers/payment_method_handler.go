@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentMethodHandler struct {
+	registry *domain.PaymentMethodRegistry
+}
+
+func NewPaymentMethodHandler(registry *domain.PaymentMethodRegistry) *PaymentMethodHandler {
+	return &PaymentMethodHandler{registry: registry}
+}
+
+func (h *PaymentMethodHandler) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/payment-methods", h.GetPaymentMethods)
+}
+
+func (h *PaymentMethodHandler) GetPaymentMethods(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.registry.All()})
+}
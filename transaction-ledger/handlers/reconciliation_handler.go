@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+	logger                *zap.Logger
+}
+
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+		logger:                logger.Log,
+	}
+}
+
+func (h *ReconciliationHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeTransactionsRead)
+	write := authMiddleware.RequireScope(auth.ScopeTransactionsWrite)
+
+	router.POST("/reconciliations", write, h.CreateReconciliation)
+	router.GET("/reconciliations/:id", read, h.GetReconciliation)
+}
+
+// CreateReconciliation recebe o arquivo de liquidação no campo multipart "file"; from e to (AAAA-MM-DD)
+// delimitam as transações do ledger esperadas no arquivo. A conciliação segue depois da resposta 202.
+func (h *ReconciliationHandler) CreateReconciliation(c *gin.Context) {
+	from, fromErr := time.Parse(time.DateOnly, c.Query("from"))
+	to, toErr := time.Parse(time.DateOnly, c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"from e to são obrigatórios no formato AAAA-MM-DD"}})
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"envie o arquivo como multipart/form-data no campo file"}})
+		return
+	}
+
+	var path, contentHash, fileName string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		fileName = part.FileName()
+		path, contentHash, err = h.reconciliationService.Stage(part)
+		if err != nil {
+			if errors.Is(err, services.ErrUploadTooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"errors": []string{err.Error()}})
+				return
+			}
+			h.logger.Error("erro ao receber arquivo de liquidação",
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao receber arquivo"})
+			return
+		}
+		break
+	}
+	if path == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{"campo file é obrigatório"}})
+		return
+	}
+
+	reconciliation, err := h.reconciliationService.Submit(c.Request.Context(), fileName, contentHash, from, to)
+	if err != nil {
+		os.Remove(path)
+		if errors.Is(err, domain.ErrorInvalidReconciliationPeriod) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		h.logger.Error("erro ao criar conciliação",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar conciliação"})
+		return
+	}
+
+	snapshot := *reconciliation
+	h.reconciliationService.Launch(reconciliation, path)
+
+	c.Header("Location", "/reconciliations/"+snapshot.ID)
+	c.JSON(http.StatusAccepted, &snapshot)
+}
+
+// GetReconciliation devolve o relatório com as divergências paginadas; result filtra por classificação
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return
+	}
+
+	report, err := h.reconciliationService.Report(c.Request.Context(), c.Param("id"), strings.ToUpper(c.Query("result")), page, pageSize)
+	if err != nil {
+		if errors.Is(err, services.ErrReconciliationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "conciliação não encontrada"})
+			return
+		}
+		h.logger.Error("erro ao buscar conciliação",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar conciliação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReviewHandler struct {
+	reviewService *services.ReviewService
+	logger        *zap.Logger
+}
+
+func NewReviewHandler(reviewService *services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+		logger:        logger.Log,
+	}
+}
+
+func (h *ReviewHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeReviewsRead)
+	write := authMiddleware.RequireScope(auth.ScopeReviewsWrite)
+
+	router.GET("/reviews", read, h.GetReviews)
+	router.POST("/reviews/:id/approve", write, h.ApproveReview)
+	router.POST("/reviews/:id/reject", write, h.RejectReview)
+}
+
+func (h *ReviewHandler) GetReviews(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return
+	}
+
+	result, err := h.reviewService.FindPaginated(c.Request.Context(), c.DefaultQuery("status", domain.ReviewPending), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar revisões"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	h.decide(c, h.reviewService.Approve)
+}
+
+func (h *ReviewHandler) RejectReview(c *gin.Context) {
+	h.decide(c, h.reviewService.Reject)
+}
+
+func (h *ReviewHandler) decide(c *gin.Context, decision func(ctx context.Context, id, reviewer, notes string) (*domain.Review, error)) {
+	var request dto.ReviewDecisionRequestDto
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	reviewer := request.Reviewer
+	if principal, ok := middlewares.PrincipalFromGin(c); ok {
+		reviewer = principal.Subject
+	}
+
+	id := c.Param("id")
+	review, err := decision(c.Request.Context(), id, reviewer, request.Notes)
+	if err != nil {
+		h.logger.Error("erro ao decidir revisão",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrTransactionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "revisão não encontrada"})
+		case errors.Is(err, domain.ErrorReviewAlreadyDecided):
+			c.JSON(http.StatusConflict, gin.H{"error": "revisão já decidida"})
+		case errors.Is(err, domain.ErrorTransactionStatusChanged):
+			c.JSON(http.StatusConflict, gin.H{"error": "a transação não está mais em revisão"})
+		case errors.Is(err, domain.ErrorReviewerRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "revisor não informado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao decidir revisão"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SettlementHandler struct {
+	settlementService *services.SettlementService
+	logger            *zap.Logger
+}
+
+func NewSettlementHandler(settlementService *services.SettlementService) *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: settlementService,
+		logger:            logger.Log,
+	}
+}
+
+func (h *SettlementHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeSettlementsRead)
+	write := authMiddleware.RequireScope(auth.ScopeSettlementsWrite)
+
+	router.GET("/settlement-batches", read, h.GetSettlementBatches)
+	router.GET("/settlement-batches/:id", read, h.GetSettlementBatch)
+	router.GET("/settlement-batches/:id/transactions", read, h.GetSettlementBatchTransactions)
+	router.POST("/settlement-batches/:id/close", write, h.CloseSettlementBatch)
+	router.POST("/settlement-batches/:id/pay", write, h.PaySettlementBatch)
+}
+
+func (h *SettlementHandler) pagination(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return 0, 0, false
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// GetSettlementBatches lista os lotes; status, paymentMethod e currencyCode filtram a listagem
+func (h *SettlementHandler) GetSettlementBatches(c *gin.Context) {
+	page, pageSize, ok := h.pagination(c)
+	if !ok {
+		return
+	}
+
+	filter := dRepo.SettlementBatchFilter{
+		Status:        strings.ToUpper(c.Query("status")),
+		PaymentMethod: strings.ToUpper(c.Query("paymentMethod")),
+		CurrencyCode:  strings.ToUpper(c.Query("currencyCode")),
+	}
+	result, err := h.settlementService.FindPaginated(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("erro ao buscar lotes de liquidação",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar lotes de liquidação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *SettlementHandler) GetSettlementBatch(c *gin.Context) {
+	batch, err := h.settlementService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+func (h *SettlementHandler) GetSettlementBatchTransactions(c *gin.Context) {
+	page, pageSize, ok := h.pagination(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.settlementService.FindTransactions(c.Request.Context(), c.Param("id"), page, pageSize)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CloseSettlementBatch fecha o lote antes do fechamento automático e trava suas transações
+func (h *SettlementHandler) CloseSettlementBatch(c *gin.Context) {
+	batch, err := h.settlementService.Close(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// PaySettlementBatch registra o repasse de um lote fechado com a referência do pagamento
+func (h *SettlementHandler) PaySettlementBatch(c *gin.Context) {
+	var request dto.PayoutRequestDto
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	batch, err := h.settlementService.MarkPaid(c.Request.Context(), c.Param("id"), request.PayoutReference)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+func (h *SettlementHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSettlementBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "lote de liquidação não encontrado"})
+	case errors.Is(err, domain.ErrorPayoutReferenceRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorSettlementBatchNotOpen), errors.Is(err, domain.ErrorSettlementBatchNotClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("erro no lote de liquidação",
+			zap.Error(err),
+			zap.String("id", c.Param("id")),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro no lote de liquidação"})
+	}
+}
@@ -1,26 +1,72 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/pix"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type TransactionHandler struct {
 	transactionService *services.TransactionService
+	intakeService      *services.IntakeService
 	logger             *zap.Logger
 }
 
-func NewTransactionHandler(transactionService *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{
+type TransactionHandlerOption func(*TransactionHandler)
+
+// WithIntakeService habilita a criação assíncrona via Prefer: respond-async
+func WithIntakeService(intakeService *services.IntakeService) TransactionHandlerOption {
+	return func(h *TransactionHandler) {
+		h.intakeService = intakeService
+	}
+}
+
+func NewTransactionHandler(transactionService *services.TransactionService, opts ...TransactionHandlerOption) *TransactionHandler {
+	h := &TransactionHandler{
 		transactionService: transactionService,
 		logger:             logger.Log,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// RegisterRoutes registra as rotas de transação exigindo o escopo adequado em cada uma
+// createMiddlewares (ex.: rate limit) são aplicados apenas na criação, após a checagem de escopo
+func (h *TransactionHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth, createMiddlewares ...gin.HandlerFunc) {
+	read := authMiddleware.RequireScope(auth.ScopeTransactionsRead)
+	write := authMiddleware.RequireScope(auth.ScopeTransactionsWrite)
+
+	createChain := append([]gin.HandlerFunc{write}, createMiddlewares...)
+	router.POST("/transaction", append(createChain, h.CreateTransaction)...)
+	router.POST("/transactions/batch", append(createChain, h.CreateTransactionBatch)...)
+	router.GET("/transactions", read, h.GetTransactionsPaginated)
+	router.GET("/transaction/:id", read, h.GetTransactionByID)
+	router.GET("/transaction/:id/pix/qrcode.png", read, h.GetPixQRCode)
+	router.GET("/transaction/:id/installments", read, h.GetInstallments)
+	router.GET("/transaction/:id/audit", read, h.GetAuditTrail)
+	router.POST("/transaction/:id/capture", write, h.CaptureTransaction)
+	router.POST("/transaction/:id/void", write, h.VoidTransaction)
+	router.POST("/transaction/:id/cancel", write, h.CancelTransaction)
+	if h.intakeService != nil {
+		router.GET("/transaction-requests/:id", read, h.GetIntakeStatus)
+	}
 }
 
 func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
@@ -33,12 +79,41 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	if h.intakeService != nil && prefersAsync(c.GetHeader("Prefer")) {
+		h.enqueueTransaction(c, transactionDto)
+		return
+	}
+
 	transaction, errs := h.transactionService.CreateTransaction(c.Request.Context(), &transactionDto)
 	if len(errs) > 0 {
 		h.logger.Error("erro ao criar transação",
 			zap.Any("errors", errs),
 		)
 
+		var quotaErr *services.QuotaExceededError
+		if errors.As(errs[0], &quotaErr) {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(quotaErr.ResetAt).Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code":   services.ErrorCodeDailyQuotaExceeded,
+				"errors": []string{quotaErr.Error()},
+			})
+			return
+		}
+
+		if errors.Is(errs[0], services.ErrTransactionDeclined) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+				"id":     transaction.ID,
+				"code":   services.ErrorCodeRiskDeclined,
+				"errors": []string{errs[0].Error()},
+			})
+			return
+		}
+
+		if errors.Is(errs[0], services.ErrCardVaultUnavailable) || errors.Is(errs[0], services.ErrReviewUnavailable) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"errors": []string{errs[0].Error()}})
+			return
+		}
+
 		// Pré-aloca o slice de erros
 		errorMessages := make([]string, 0, len(errs))
 		for _, err := range errs {
@@ -49,7 +124,51 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.FromTransaction(&transaction))
+	c.JSON(http.StatusCreated, dto.FromTransaction(transaction))
+}
+
+// enqueueTransaction responde 202 com o status da requisição na fila; a transação é gravada por um worker
+func (h *TransactionHandler) enqueueTransaction(c *gin.Context, transactionDto dto.TransactionRequestDto) {
+	status, errs := h.intakeService.Submit(c.Request.Context(), transactionDto)
+	if len(errs) > 0 {
+		if errors.Is(errs[0], services.ErrIntakeQueueFull) {
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"errors": []string{errs[0].Error()}})
+			return
+		}
+
+		errorMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errorMessages = append(errorMessages, err.Error())
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": errorMessages})
+		return
+	}
+
+	c.Header("Location", "/transaction-requests/"+status.ID)
+	c.Header("Preference-Applied", "respond-async")
+	c.JSON(http.StatusAccepted, status)
+}
+
+func (h *TransactionHandler) GetIntakeStatus(c *gin.Context) {
+	status, err := h.intakeService.Status(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "requisição não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// prefersAsync procura respond-async entre as preferências do cabeçalho Prefer (RFC 7240)
+func prefersAsync(header string) bool {
+	for _, preference := range strings.Split(header, ",") {
+		token, _, _ := strings.Cut(preference, ";")
+		if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *TransactionHandler) GetTransactionsPaginated(c *gin.Context) {
@@ -77,6 +196,49 @@ func (h *TransactionHandler) GetTransactionsPaginated(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// CreateTransactionBatch responde 201 quando todos os itens foram aceitos, 207 quando só parte foi
+// e 422 quando nenhum foi gravado; o corpo sempre traz o resultado de cada item pelo índice. Com o
+// cabeçalho Idempotency-Key, o reenvio do mesmo lote devolve a resposta gravada sem criar nada.
+func (h *TransactionHandler) CreateTransactionBatch(c *gin.Context) {
+	var request dto.TransactionBatchRequestDto
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	request.IdempotencyKey = c.GetHeader("Idempotency-Key")
+
+	response, err := h.transactionService.CreateBatch(c.Request.Context(), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBatchTooLarge):
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"errors": []string{err.Error()}})
+		case errors.Is(err, services.ErrEmptyBatch), errors.Is(err, services.ErrInvalidBatchMode), errors.Is(err, services.ErrInvalidIdempotencyKey):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		case errors.Is(err, domain.ErrorIdempotencyKeyUsed):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"errors": []string{err.Error()}})
+		default:
+			h.logger.Error("erro ao criar lote de transações",
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar lote de transações"})
+		}
+		return
+	}
+
+	if response.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	status := http.StatusMultiStatus
+	switch {
+	case response.Rejected == 0:
+		status = http.StatusCreated
+	case response.Accepted == 0:
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, response)
+}
+
 func (h *TransactionHandler) GetTransactionByID(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -84,8 +246,24 @@ func (h *TransactionHandler) GetTransactionByID(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.transactionService.FindByID(c.Request.Context(), id)
+	wait, err := parseWait(c.Query("wait"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetro wait inválido"})
+		return
+	}
+
+	transaction, err := h.transactionService.WaitForStatus(c.Request.Context(), id, wait)
 	if err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			// o cliente desconectou durante a espera
+			return
+		case errors.Is(err, services.ErrTooManyWaiters):
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "muitas requisições aguardando status, tente novamente"})
+			return
+		}
+
 		h.logger.Error("erro ao buscar transação",
 			zap.Error(err),
 			zap.String("id", id),
@@ -96,3 +274,138 @@ func (h *TransactionHandler) GetTransactionByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, transaction)
 }
+
+// parseWait aceita durações ("10s", "1500ms") ou segundos inteiros ("10")
+func parseWait(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil || wait < 0 {
+		return 0, errors.New("invalid wait")
+	}
+	return wait, nil
+}
+
+func (h *TransactionHandler) GetPixQRCode(c *gin.Context) {
+	id := c.Param("id")
+
+	transaction, err := h.transactionService.FindByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transação não encontrada"})
+		return
+	}
+
+	if transaction.PixPayload == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transação não possui cobrança PIX"})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "256"))
+	if err != nil || size < 64 || size > 1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho inválido"})
+		return
+	}
+
+	png, err := pix.QRCodePNG(transaction.PixPayload, size)
+	if err != nil {
+		h.logger.Error("erro ao gerar QR Code",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao gerar QR Code"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+func (h *TransactionHandler) GetInstallments(c *gin.Context) {
+	id := c.Param("id")
+
+	transaction, schedule, err := h.transactionService.FindInstallments(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("erro ao buscar parcelas",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrTransactionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transação não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar parcelas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromInstallmentSchedule(transaction, schedule))
+}
+
+func (h *TransactionHandler) GetAuditTrail(c *gin.Context) {
+	id := c.Param("id")
+
+	entries, err := h.transactionService.FindAuditTrail(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("erro ao buscar trilha de auditoria",
+			zap.Error(err),
+			zap.String("id", id),
+		)
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrTransactionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transação não encontrada"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar trilha de auditoria"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+func (h *TransactionHandler) CaptureTransaction(c *gin.Context) {
+	var request dto.CaptureRequestDto
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	transaction, err := h.transactionService.Capture(c.Request.Context(), c.Param("id"), request.Amount)
+	h.respondOperation(c, transaction, err)
+}
+
+func (h *TransactionHandler) VoidTransaction(c *gin.Context) {
+	transaction, err := h.transactionService.Void(c.Request.Context(), c.Param("id"))
+	h.respondOperation(c, transaction, err)
+}
+
+// CancelTransaction pede o cancelamento de uma transação PENDING; o resultado chega pelo processamento
+func (h *TransactionHandler) CancelTransaction(c *gin.Context) {
+	transaction, err := h.transactionService.Cancel(c.Request.Context(), c.Param("id"))
+	h.respondOperation(c, transaction, err)
+}
+
+// respondOperation responde 202: o resultado da captura/cancelamento chega depois pelo processamento
+func (h *TransactionHandler) respondOperation(c *gin.Context, transaction *domain.Transaction, err error) {
+	if err != nil {
+		h.logger.Error("erro ao solicitar etapa da autorização",
+			zap.Error(err),
+			zap.String("id", c.Param("id")),
+		)
+
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrTransactionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "transação não encontrada"})
+		case errors.Is(err, domain.ErrorInvalidCaptureAmount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorTransactionNotAuthorized), errors.Is(err, domain.ErrorAuthorizationExpired), errors.Is(err, domain.ErrorTransactionStatusChanged),
+			errors.Is(err, domain.ErrorTransactionNotCancellable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao solicitar etapa da autorização"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.FromTransaction(transaction))
+}
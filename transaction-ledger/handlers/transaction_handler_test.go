@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/akafka"
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
@@ -14,6 +17,8 @@ import (
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
 	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +35,11 @@ func (m *MockKafkaBroker) Publish(topic string, message []byte) error {
 	return args.Error(0)
 }
 
+func (m *MockKafkaBroker) PublishBatch(messages []akafka.Message) error {
+	args := m.Called(messages)
+	return args.Error(0)
+}
+
 func (m *MockKafkaBroker) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -39,6 +49,10 @@ func (m *MockKafkaBroker) Consume(topics []string, msgChan chan *kafka.Message)
 	m.Called(topics, msgChan)
 }
 
+func (m *MockKafkaBroker) ConsumeBroadcast(topics []string, msgChan chan *kafka.Message) {
+	m.Called(topics, msgChan)
+}
+
 func (m *MockKafkaBroker) CreateTopicsIfNotExists(topics []string) error {
 	args := m.Called(topics)
 	return args.Error(0)
@@ -53,6 +67,19 @@ func (m *MockTransactionRepository) Create(transaction *domain.Transaction) erro
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) CreateBatch(creation *dRepo.TransactionCreation) error {
+	args := m.Called(creation)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) FindBatchRequest(merchantID, idempotencyKey string) (*domain.BatchRequest, error) {
+	args := m.Called(merchantID, idempotencyKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BatchRequest), args.Error(1)
+}
+
 func (m *MockTransactionRepository) FindByID(id string) (*domain.Transaction, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -61,11 +88,37 @@ func (m *MockTransactionRepository) FindByID(id string) (*domain.Transaction, er
 	return args.Get(0).(*domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) FindExistingIDs(ids []string) ([]string, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindByIDs(ids []string) ([]domain.Transaction, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) Update(transaction *domain.Transaction) error {
 	args := m.Called(transaction)
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) UpdateIfStatus(transaction *domain.Transaction, expectedStatus string) error {
+	args := m.Called(transaction, expectedStatus)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) UpdateIfStatusWithSchedule(transaction *domain.Transaction, expectedStatus string, schedule []domain.Installment) error {
+	args := m.Called(transaction, expectedStatus, schedule)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepository) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -79,14 +132,57 @@ func (m *MockTransactionRepository) FindAll() ([]*domain.Transaction, error) {
 	return args.Get(0).([]*domain.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) FindPaginated(page int, pageSize int) ([]domain.Transaction, int64, error) {
-	args := m.Called(page, pageSize)
+func (m *MockTransactionRepository) FindPaginated(filter dRepo.TransactionFilter, page int, pageSize int) ([]domain.Transaction, int64, error) {
+	args := m.Called(filter, page, pageSize)
 	if args.Get(0) == nil {
 		return nil, 0, args.Error(2)
 	}
 	return args.Get(0).([]domain.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockTransactionRepository) Stream(filter dRepo.TransactionFilter, fn func(transaction *domain.Transaction) error) error {
+	args := m.Called(filter, fn)
+	if transactions, ok := args.Get(0).([]domain.Transaction); ok {
+		for i := range transactions {
+			if err := fn(&transactions[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTransactionRepository) SumAmountByMerchant(merchantID, currencyCode string, since time.Time) (float64, error) {
+	args := m.Called(merchantID, currencyCode, since)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) CountByMerchantSince(merchantID string, since time.Time) (int64, error) {
+	args := m.Called(merchantID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) CountByCardSince(fingerprint string, since time.Time) (int64, error) {
+	args := m.Called(fingerprint, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindExpired(now time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindStuckProcessing(before time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
 var _ akafka.KafkaBroker = (*MockKafkaBroker)(nil)
 var _ dRepo.TransactionRepository = (*MockTransactionRepository)(nil)
 
@@ -131,7 +227,7 @@ func TestCreateTransaction(t *testing.T) {
 			Description:   "Test transaction",
 		}
 
-		mockRepo.On("Create", mock.AnythingOfType("*domain.Transaction")).Return(nil)
+		mockRepo.On("CreateBatch", mock.AnythingOfType("*repository.TransactionCreation")).Return(nil)
 		mockKafka.On("Publish", "process-transaction", mock.Anything).Return(nil)
 
 		w := httptest.NewRecorder()
@@ -190,7 +286,7 @@ func TestCreateTransaction(t *testing.T) {
 			Description:   "Test transaction",
 		}
 
-		mockRepo.On("Create", mock.AnythingOfType("*domain.Transaction")).Return(nil)
+		mockRepo.On("CreateBatch", mock.AnythingOfType("*repository.TransactionCreation")).Return(nil)
 		mockKafka.On("Publish", "process-transaction", mock.Anything).Return(errors.New("erro ao publicar no kafka"))
 
 		w := httptest.NewRecorder()
@@ -208,4 +304,354 @@ func TestCreateTransaction(t *testing.T) {
 		mockKafka.AssertExpectations(t)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Should return 429 when the merchant daily quota is exceeded", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		quota := services.NewQuotaService(mockRepo, 1000, nil)
+		service := services.NewTransactionService(mockKafka, mockRepo, services.WithQuotaService(quota))
+		handler := NewTransactionHandler(service)
+
+		requestDto := dto.TransactionRequestDto{
+			Amount:        100.0,
+			PaymentMethod: domain.PaymentMethodPIX,
+			CurrencyCode:  "BRL",
+			Description:   "Test transaction",
+		}
+
+		mockRepo.On("SumAmountByMerchant", "merchant-1", "BRL", mock.AnythingOfType("time.Time")).Return(950.0, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(requestDto)
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{MerchantID: "merchant-1"})
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBuffer(jsonData)).WithContext(ctx)
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Act
+		handler.CreateTransaction(c)
+
+		// Assert
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), services.ErrorCodeDailyQuotaExceeded)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+		mockKafka.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCaptureTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.Log = mockLogger
+
+	authorized := func(status string) *domain.Transaction {
+		expiresAt := time.Now().Add(time.Hour)
+		return &domain.Transaction{
+			ID:                "tx-1",
+			Amount:            100,
+			PaymentMethod:     domain.PaymentMethodCreditCard,
+			CurrencyCode:      "BRL",
+			Status:            status,
+			AuthorizationOnly: true,
+			ExpiresAt:         &expiresAt,
+		}
+	}
+
+	t.Run("Should request a partial capture of an authorization", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		handler := NewTransactionHandler(services.NewTransactionService(mockKafka, mockRepo))
+
+		mockRepo.On("FindByID", "tx-1").Return(authorized(domain.TransactionAuthorized), nil)
+		mockRepo.On("UpdateIfStatus", mock.AnythingOfType("*domain.Transaction"), domain.TransactionAuthorized).Return(nil)
+		mockKafka.On("Publish", "process-transaction", mock.Anything).Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "tx-1"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/transaction/tx-1/capture", bytes.NewBufferString(`{"amount": 60}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Act
+		handler.CaptureTransaction(c)
+
+		// Assert
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), domain.TransactionCapturePending)
+		updated := mockRepo.Calls[1].Arguments.Get(0).(*domain.Transaction)
+		assert.Equal(t, 60.0, updated.CapturedAmount)
+		mockKafka.AssertExpectations(t)
+	})
+
+	t.Run("Should return 409 when the transaction is not authorized", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		handler := NewTransactionHandler(services.NewTransactionService(mockKafka, mockRepo))
+
+		mockRepo.On("FindByID", "tx-1").Return(authorized(domain.TransactionFinished), nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "tx-1"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/transaction/tx-1/void", nil)
+
+		// Act
+		handler.VoidTransaction(c)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockRepo.AssertNotCalled(t, "UpdateIfStatus", mock.Anything, mock.Anything)
+		mockKafka.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetTransactionByID_Wait(t *testing.T) {
+	pending := &domain.Transaction{ID: "tx-1", Amount: 100, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Status: domain.TransactionPending}
+	finished := &domain.Transaction{ID: "tx-1", Amount: 100, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Status: domain.TransactionFinished}
+
+	newContext := func(ctx context.Context, wait string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "tx-1"}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/transaction/tx-1?wait="+wait, nil).WithContext(ctx)
+		return c, w
+	}
+
+	t.Run("Should return as soon as the consumer applies the result", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		waiter := services.NewStatusWaiter(10, time.Minute)
+		handler := NewTransactionHandler(services.NewTransactionService(new(MockKafkaBroker), mockRepo, services.WithStatusWaiter(waiter)))
+
+		mockRepo.On("FindByID", "tx-1").Return(pending, nil).Once()
+		mockRepo.On("FindByID", "tx-1").Return(finished, nil).Once()
+		c, w := newContext(context.Background(), "10s")
+
+		go func() {
+			assert.Eventually(t, func() bool { return waiter.Waiting() == 1 }, time.Second, time.Millisecond)
+			waiter.Notify("tx-1")
+		}()
+
+		// Act
+		started := time.Now()
+		handler.GetTransactionByID(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), domain.TransactionFinished)
+		assert.Less(t, time.Since(started), 5*time.Second)
+		assert.Zero(t, waiter.Waiting())
+		mockRepo.AssertNumberOfCalls(t, "FindByID", 2)
+	})
+
+	t.Run("Should release the slot when the client disconnects", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		waiter := services.NewStatusWaiter(10, time.Minute)
+		handler := NewTransactionHandler(services.NewTransactionService(new(MockKafkaBroker), mockRepo, services.WithStatusWaiter(waiter)))
+
+		mockRepo.On("FindByID", "tx-1").Return(pending, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		c, _ := newContext(ctx, "10s")
+
+		go func() {
+			assert.Eventually(t, func() bool { return waiter.Waiting() == 1 }, time.Second, time.Millisecond)
+			cancel()
+		}()
+
+		// Act
+		handler.GetTransactionByID(c)
+
+		// Assert
+		assert.Zero(t, waiter.Waiting())
+		mockRepo.AssertNumberOfCalls(t, "FindByID", 1)
+	})
+
+	t.Run("Should return 503 when the waiter cap is reached", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockTransactionRepository)
+		handler := NewTransactionHandler(services.NewTransactionService(new(MockKafkaBroker), mockRepo, services.WithStatusWaiter(services.NewStatusWaiter(0, time.Minute))))
+		c, w := newContext(context.Background(), "10")
+
+		// Act
+		handler.GetTransactionByID(c)
+
+		// Assert
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+}
+
+func TestCreateTransactionBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.Log = mockLogger
+
+	body := func(mode string) *bytes.Buffer {
+		jsonData, _ := json.Marshal(dto.TransactionBatchRequestDto{
+			Mode: mode,
+			Items: []dto.TransactionRequestDto{
+				{Amount: 100, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: "Fornecedor A"},
+				{Amount: -5, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: "Fornecedor B"},
+				{Amount: 50, PaymentMethod: domain.PaymentMethodPIX, CurrencyCode: "BRL", Description: "Fornecedor C"},
+			},
+		})
+		return bytes.NewBuffer(jsonData)
+	}
+
+	t.Run("Should persist nothing in ALL_OR_NOTHING mode when an item is invalid", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		handler := NewTransactionHandler(services.NewTransactionService(mockKafka, mockRepo))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions/batch", body(""))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Act
+		handler.CreateTransactionBatch(c)
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response dto.TransactionBatchResponseDto
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, dto.BatchModeAllOrNothing, response.Mode)
+		assert.Zero(t, response.Accepted)
+		assert.Equal(t, 1, response.Results[1].Index)
+		assert.Contains(t, response.Results[1].Errors, domain.ErrorInvalidAmount.Error())
+		assert.Empty(t, response.Results[0].Errors)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+		mockKafka.AssertNotCalled(t, "PublishBatch", mock.Anything)
+	})
+
+	t.Run("Should persist and publish the valid items in BEST_EFFORT mode", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		handler := NewTransactionHandler(services.NewTransactionService(mockKafka, mockRepo))
+
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(creation *dRepo.TransactionCreation) bool { return len(creation.Transactions) == 2 })).Return(nil)
+		mockKafka.On("PublishBatch", mock.MatchedBy(func(messages []akafka.Message) bool {
+			return len(messages) == 2 && messages[0].Topic == "process-transaction"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions/batch", body("best_effort"))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Act
+		handler.CreateTransactionBatch(c)
+
+		// Assert
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+		var response dto.TransactionBatchResponseDto
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Accepted)
+		assert.Equal(t, 1, response.Rejected)
+		assert.NotEmpty(t, response.Results[0].ID)
+		assert.Empty(t, response.Results[1].ID)
+		assert.Equal(t, domain.TransactionPending, response.Results[2].Status)
+		mockRepo.AssertExpectations(t)
+		mockKafka.AssertExpectations(t)
+	})
+
+	t.Run("Should reject batches above the item limit", func(t *testing.T) {
+		// Arrange
+		handler := NewTransactionHandler(services.NewTransactionService(new(MockKafkaBroker), new(MockTransactionRepository), services.WithBatchLimit(2)))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions/batch", body(dto.BatchModeBestEffort))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Act
+		handler.CreateTransactionBatch(c)
+
+		// Assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func TestCreateTransaction_RespondAsync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Should enqueue the request and expose its status resource", func(t *testing.T) {
+		// Arrange
+		mockKafka := new(MockKafkaBroker)
+		mockRepo := new(MockTransactionRepository)
+		persisted := make(chan struct{})
+		mockRepo.On("CreateBatch", mock.MatchedBy(func(creation *dRepo.TransactionCreation) bool { return len(creation.Transactions) == 1 })).
+			Run(func(args mock.Arguments) { close(persisted) }).
+			Return(nil)
+		mockKafka.On("PublishBatch", mock.Anything).Return(nil)
+
+		service := services.NewTransactionService(mockKafka, mockRepo)
+		intake := services.NewIntakeService(service, services.IntakeConfig{QueueSize: 10, Workers: 1, BatchSize: 10, BatchWait: time.Millisecond, ResultTTL: time.Minute})
+		handler := NewTransactionHandler(service, WithIntakeService(intake))
+
+		router := gin.New()
+		handler.RegisterRoutes(router, middlewares.NewAuth(nil, nil))
+
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/transaction", strings.NewReader(`{"amount":10,"paymentMethod":"PIX","currencyCode":"BRL","description":"async"}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Prefer", "respond-async, wait=5")
+
+		// Act
+		router.ServeHTTP(w, request)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		intake.Start(ctx)
+		<-persisted
+		cancel()
+		intake.Wait()
+
+		status := httptest.NewRecorder()
+		router.ServeHTTP(status, httptest.NewRequest(http.MethodGet, w.Header().Get("Location"), nil))
+
+		// Assert
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "respond-async", w.Header().Get("Preference-Applied"))
+
+		var queued dto.IntakeStatusDto
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &queued))
+		assert.Equal(t, dto.IntakeQueued, queued.Status)
+		assert.Equal(t, "/transaction-requests/"+queued.ID, w.Header().Get("Location"))
+
+		var completed dto.IntakeStatusDto
+		assert.Equal(t, http.StatusOK, status.Code)
+		assert.NoError(t, json.Unmarshal(status.Body.Bytes(), &completed))
+		assert.Equal(t, dto.IntakeCompleted, completed.Status)
+		assert.Equal(t, queued.ID, completed.TransactionID, "the intake ID should become the transaction ID")
+		assert.Equal(t, domain.TransactionPending, completed.TransactionStatus)
+	})
+
+	t.Run("Should reject invalid requests before enqueueing", func(t *testing.T) {
+		// Arrange
+		service := services.NewTransactionService(new(MockKafkaBroker), new(MockTransactionRepository))
+		intake := services.NewIntakeService(service, services.IntakeConfig{QueueSize: 1, Workers: 1})
+		handler := NewTransactionHandler(service, WithIntakeService(intake))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/transaction", strings.NewReader(`{"amount":-1,"paymentMethod":"PIX","currencyCode":"BRL","description":"async"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Prefer", "respond-async")
+
+		// Act
+		handler.CreateTransaction(c)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Zero(t, intake.QueueDepth())
+	})
 }
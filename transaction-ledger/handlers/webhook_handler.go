@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/application/services"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/handlers/middlewares"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	logger         *zap.Logger
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger.Log,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router gin.IRoutes, authMiddleware *middlewares.Auth) {
+	read := authMiddleware.RequireScope(auth.ScopeWebhooksRead)
+	write := authMiddleware.RequireScope(auth.ScopeWebhooksWrite)
+
+	router.GET("/webhooks", read, h.GetWebhooks)
+	router.POST("/webhooks", write, h.CreateWebhook)
+	router.DELETE("/webhooks/:id", write, h.DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", read, h.GetDeliveries)
+	router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", write, h.Redeliver)
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.List(c.Request.Context())
+	if err != nil {
+		h.logger.Error("erro ao buscar webhooks",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": webhooks})
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var request dto.WebhookRequestDto
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+		return
+	}
+
+	webhook, err := h.webhookService.Register(c.Request.Context(), request)
+	if err != nil {
+		if errors.Is(err, domain.ErrorInvalidWebhookURL) || errors.Is(err, domain.ErrorInvalidWebhookStatus) || errors.Is(err, domain.ErrorWebhookAddressNotAllowed) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": []string{err.Error()}})
+			return
+		}
+		h.logger.Error("erro ao cadastrar webhook",
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao cadastrar webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.WebhookCreatedResponseDto{Webhook: webhook, Secret: webhook.Secret})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhookService.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err, "erro ao remover webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "página inválida"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tamanho de página inválido"})
+		return
+	}
+
+	result, err := h.webhookService.Deliveries(c.Request.Context(), c.Param("id"), page, pageSize)
+	if err != nil {
+		h.respondError(c, err, "erro ao buscar entregas do webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		h.respondError(c, err, "erro ao reenviar webhook")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook não encontrado"})
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "entrega não encontrada"})
+	default:
+		h.logger.Error(message,
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const AuthMethodAPIKey = "api_key"
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type apiKeyEntry struct {
+	Key        string   `json:"key"`
+	MerchantID string   `json:"merchantId"`
+	TenantID   string   `json:"tenantId"`
+	Scopes     []string `json:"scopes"`
+}
+
+// APIKeyStore guarda as API keys estáticas indexadas pelo hash SHA-256 da chave
+type APIKeyStore struct {
+	keys map[string]Principal
+}
+
+func NewAPIKeyStoreFromFile(path string) (*APIKeyStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de api keys: %v", err)
+	}
+
+	var entries []apiKeyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("erro ao converter api keys: %v", err)
+	}
+
+	store := &APIKeyStore{keys: make(map[string]Principal, len(entries))}
+	for _, entry := range entries {
+		store.Add(entry.Key, Principal{
+			Subject:    entry.MerchantID,
+			MerchantID: entry.MerchantID,
+			TenantID:   entry.TenantID,
+			Scopes:     entry.Scopes,
+		})
+	}
+
+	return store, nil
+}
+
+func (s *APIKeyStore) Add(key string, principal Principal) {
+	if s.keys == nil {
+		s.keys = make(map[string]Principal)
+	}
+	hash := hashAPIKey(key)
+	principal.Method = AuthMethodAPIKey
+	principal.KeyID = hash[:16]
+	s.keys[hash] = principal
+}
+
+func (s *APIKeyStore) Authenticate(key string) (*Principal, error) {
+	principal, ok := s.keys[hashAPIKey(key)]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	return &principal, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// KeySet mantém em cache as chaves públicas de um JWKS (arquivo ou URL).
+// As chaves são recarregadas quando o TTL expira ou quando chega um kid desconhecido,
+// o que cobre a rotação de chaves sem reiniciar o serviço.
+type KeySet struct {
+	source             string
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+	client             *http.Client
+	now                func() time.Time
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	refreshedAt time.Time
+}
+
+func NewKeySet(source string, ttl time.Duration) *KeySet {
+	return &KeySet{
+		source:             source,
+		ttl:                ttl,
+		minRefreshInterval: 10 * time.Second,
+		client:             &http.Client{Timeout: 5 * time.Second},
+		now:                time.Now,
+	}
+}
+
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	key, found := k.keys[kid]
+	fresh := k.now().Sub(k.fetchedAt) < k.ttl
+	k.mu.RUnlock()
+
+	if found && fresh {
+		return key, nil
+	}
+
+	if err := k.refresh(ctx, found); err != nil {
+		if found {
+			// mantém a chave antiga se o JWKS estiver indisponível
+			return key, nil
+		}
+		return nil, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, found = k.keys[kid]
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (k *KeySet) refresh(ctx context.Context, force bool) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if !force && now.Sub(k.refreshedAt) < k.minRefreshInterval {
+		return nil
+	}
+	k.refreshedAt = now
+
+	data, err := k.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	k.keys = keys
+	k.fetchedAt = now
+	return nil
+}
+
+func (k *KeySet) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		data, err := os.ReadFile(k.source)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler JWKS: %v", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("erro ao buscar JWKS: status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("erro ao converter JWKS: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("chave %q inválida: %v", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (j *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("curva não suportada: %s", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("ponto fora da curva")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("tipo de chave não suportado: %s", j.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
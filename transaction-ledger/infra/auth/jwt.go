@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const AuthMethodJWT = "jwt"
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type JWTConfig struct {
+	Issuer        string
+	Audience      string
+	MerchantClaim string
+	TenantClaim   string
+	Leeway        time.Duration
+}
+
+// JWTValidator valida bearer tokens RS256/ES256 contra as chaves de um KeySet
+type JWTValidator struct {
+	keys   *KeySet
+	config JWTConfig
+}
+
+func NewJWTValidator(keys *KeySet, config JWTConfig) *JWTValidator {
+	if config.MerchantClaim == "" {
+		config.MerchantClaim = "merchant_id"
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = "tenant_id"
+	}
+	return &JWTValidator{keys: keys, config: config}
+}
+
+func (v *JWTValidator) Validate(ctx context.Context, tokenString string) (*Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.config.Leeway),
+	}
+	if v.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.config.Issuer))
+	}
+	if v.config.Audience != "" {
+		options = append(options, jwt.WithAudience(v.config.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	merchantID, _ := claims[v.config.MerchantClaim].(string)
+	tenantID, _ := claims[v.config.TenantClaim].(string)
+
+	return &Principal{
+		Subject:    subject,
+		MerchantID: merchantID,
+		TenantID:   tenantID,
+		Scopes:     scopesFromClaims(claims),
+		Method:     AuthMethodJWT,
+	}, nil
+}
+
+// scopesFromClaims aceita tanto "scope" (string separada por espaços) quanto "scp" (lista)
+func scopesFromClaims(claims jwt.MapClaims) []string {
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+	if scp, ok := claims["scp"].([]interface{}); ok {
+		for _, s := range scp {
+			if value, ok := s.(string); ok {
+				scopes = append(scopes, value)
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   encodeBigInt(key.N),
+		E:   encodeBigInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   encodeBigInt(key.X),
+		Y:   encodeBigInt(key.Y),
+	}
+}
+
+func writeJWKS(t *testing.T, path string, keys ...jsonWebKey) {
+	data, err := json.Marshal(jsonWebKeySet{Keys: keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":         "dashboard",
+		"iss":         "https://auth.local",
+		"aud":         "transaction-ledger",
+		"exp":         time.Now().Add(time.Minute).Unix(),
+		"merchant_id": "merchant-1",
+		"tenant_id":   "tenant-1",
+		"scope":       "transactions:read transactions:write",
+	}
+}
+
+func TestJWTValidator_Validate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksPath, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))
+
+	validator := NewJWTValidator(NewKeySet(jwksPath, time.Minute), JWTConfig{
+		Issuer:   "https://auth.local",
+		Audience: "transaction-ledger",
+	})
+
+	t.Run("Should accept a RS256 token and map the claims", func(t *testing.T) {
+		// Arrange
+		token := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims())
+
+		// Act
+		principal, err := validator.Validate(context.Background(), token)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "merchant-1", principal.MerchantID)
+		assert.Equal(t, "tenant-1", principal.TenantID)
+		assert.Equal(t, AuthMethodJWT, principal.Method)
+		assert.True(t, principal.HasScope(ScopeTransactionsWrite))
+	})
+
+	t.Run("Should accept a ES256 token with scp claim", func(t *testing.T) {
+		// Arrange
+		claims := validClaims()
+		delete(claims, "scope")
+		claims["scp"] = []string{ScopeTransactionsRead}
+		token := signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, claims)
+
+		// Act
+		principal, err := validator.Validate(context.Background(), token)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, principal.HasScope(ScopeTransactionsRead))
+		assert.False(t, principal.HasScope(ScopeTransactionsWrite))
+	})
+
+	t.Run("Should reject an expired token", func(t *testing.T) {
+		// Arrange
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		token := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+
+		// Act
+		_, err := validator.Validate(context.Background(), token)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Should reject a token with the wrong audience", func(t *testing.T) {
+		// Arrange
+		claims := validClaims()
+		claims["aud"] = "other-service"
+		token := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+
+		// Act
+		_, err := validator.Validate(context.Background(), token)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Should reject a HS256 token", func(t *testing.T) {
+		// Arrange
+		token := signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims())
+
+		// Act
+		_, err := validator.Validate(context.Background(), token)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	// Arrange
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, jwksPath, rsaJWK("old", &oldKey.PublicKey))
+
+	keySet := NewKeySet(jwksPath, time.Hour)
+	keySet.minRefreshInterval = 0
+	validator := NewJWTValidator(keySet, JWTConfig{})
+
+	_, err = validator.Validate(context.Background(), signToken(t, jwt.SigningMethodRS256, "old", oldKey, validClaims()))
+	require.NoError(t, err)
+
+	// Act
+	writeJWKS(t, jwksPath, rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey))
+	_, err = validator.Validate(context.Background(), signToken(t, jwt.SigningMethodRS256, "new", newKey, validClaims()))
+
+	// Assert
+	assert.NoError(t, err)
+}
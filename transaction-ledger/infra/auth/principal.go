@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeReviewsRead       = "reviews:read"
+	ScopeReviewsWrite      = "reviews:write"
+	ScopeFXRead            = "fx:read"
+	ScopeFXWrite           = "fx:write"
+	ScopeFeesRead          = "fees:read"
+	ScopeFeesWrite         = "fees:write"
+	ScopeWebhooksRead      = "webhooks:read"
+	ScopeWebhooksWrite     = "webhooks:write"
+	ScopeSettlementsRead   = "settlements:read"
+	ScopeSettlementsWrite  = "settlements:write"
+	ScopeDisputesRead      = "disputes:read"
+	ScopeDisputesWrite     = "disputes:write"
+	ScopeMetricsRead       = "metrics:read"
+)
+
+// Principal representa o chamador autenticado, seja por API key ou por JWT
+type Principal struct {
+	Subject    string
+	MerchantID string
+	TenantID   string
+	Scopes     []string
+	Method     string
+	KeyID      string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Owns diz se o registro do merchant e tenant informados é visível ao chamador. Como em TransactionFilter,
+// só o que estiver preenchido no principal é comparado.
+func (p *Principal) Owns(merchantID, tenantID string) bool {
+	if p.MerchantID != "" && merchantID != p.MerchantID {
+		return false
+	}
+	return p.TenantID == "" || tenantID == p.TenantID
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
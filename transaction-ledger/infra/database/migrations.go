@@ -1,10 +1,110 @@
 package database
 
 import (
+	"slices"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/ratelimit"
 	"gorm.io/gorm"
 )
 
 func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(&domain.Transaction{})
+	if err := dropReplacedIndexes(db); err != nil {
+		return err
+	}
+
+	err := db.AutoMigrate(
+		&domain.Transaction{},
+		&domain.Review{},
+		&domain.BatchRequest{},
+		&domain.DailyQuota{},
+		&domain.FXRate{},
+		&domain.Installment{},
+		&domain.FeePlan{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.TransactionEvent{},
+		&domain.ImportJob{},
+		&domain.ImportRowError{},
+		&domain.ExportJob{},
+		&domain.AuditEntry{},
+		&domain.Reconciliation{},
+		&domain.ReconciliationItem{},
+		&domain.SettlementBatch{},
+		&domain.Dispute{},
+		&domain.DisputeEvidence{},
+		&domain.LedgerAdjustment{},
+		&ratelimit.RateLimitBucket{},
+		&cardvault.VaultedCard{},
+	)
+	if err != nil {
+		return err
+	}
+	return widenAmountColumns(db)
+}
+
+// replacedIndexes são índices que mudaram de colunas e ganharam outro nome. O AutoMigrate não altera nem
+// remove um índice existente, então o antigo é removido aqui antes de o novo ser criado.
+var replacedIndexes = []struct {
+	model any
+	name  string
+}{
+	// o lote OPEN passou a ser único também por tenant (idx_settlement_batches_open_tenant)
+	{&domain.SettlementBatch{}, "idx_settlement_batches_open"},
+	// o job de importação passou a ser único por merchant, tenant e conteúdo (idx_import_job_tenant_content)
+	{&domain.ImportJob{}, "idx_import_job_content"},
+}
+
+func dropReplacedIndexes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, index := range replacedIndexes {
+		if !migrator.HasIndex(index.model, index.name) {
+			continue
+		}
+		if err := migrator.DropIndex(index.model, index.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// amountColumns são as colunas de valor que passaram para decimal(18,4), para caberem moedas com 3 casas
+// decimais e valores acima de 99 milhões
+var amountColumns = []struct {
+	model   any
+	columns []string
+}{
+	{&domain.Transaction{}, []string{"amount", "captured_amount", "installments_total", "gross_amount", "fee_amount", "net_amount"}},
+	{&domain.TransactionEvent{}, []string{"amount"}},
+	{&domain.Installment{}, []string{"amount"}},
+	{&domain.FeePlan{}, []string{"fixed_fee"}},
+	{&domain.Dispute{}, []string{"amount"}},
+	{&domain.LedgerAdjustment{}, []string{"amount"}},
+	{&domain.SettlementBatch{}, []string{"gross_amount", "fee_amount", "net_amount"}},
+}
+
+// widenAmountColumns altera as colunas criadas com a precisão antiga. O AutoMigrate não muda a precisão
+// de uma coluna decimal que já existe, então bancos criados antes da mudança são alterados aqui.
+func widenAmountColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, table := range amountColumns {
+		columnTypes, err := migrator.ColumnTypes(table.model)
+		if err != nil {
+			return err
+		}
+		for _, columnType := range columnTypes {
+			if !slices.Contains(table.columns, columnType.Name()) {
+				continue
+			}
+			precision, scale, ok := columnType.DecimalSize()
+			if !ok || (precision == 18 && scale == 4) {
+				continue
+			}
+			if err := migrator.AlterColumn(table.model, columnType.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,235 @@
+package loadshed
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Tolerance é quanto a latência recente pode passar da latência de referência antes do limite cair
+	Tolerance float64
+	// Backoff multiplica o limite a cada amostragem em que algum sinal externo está acima do seu limiar
+	Backoff float64
+	// RetryAfter é o valor sugerido ao cliente nas requisições recusadas
+	RetryAfter time.Duration
+}
+
+// Signal é um indicador externo de sobrecarga, como a fila do Kafka ou a espera por conexões do banco
+type Signal struct {
+	Name      string
+	Threshold float64
+	Read      func() float64
+}
+
+type SignalState struct {
+	Name       string
+	Value      float64
+	Threshold  float64
+	Overloaded bool
+}
+
+type State struct {
+	Limit       int
+	InFlight    int
+	Latency     time.Duration
+	BaseLatency time.Duration
+	Accepted    int64
+	Rejected    int64
+	Signals     []SignalState
+	Overloaded  bool
+}
+
+const (
+	// pesos das médias móveis: a curta acompanha a carga atual, a longa serve de referência
+	shortSmoothing = 0.1
+	longSmoothing  = 0.002
+	limitSmoothing = 0.2
+	minGradient    = 0.5
+)
+
+// AdaptiveLimiter limita as requisições simultâneas com um limite que se ajusta pela latência observada:
+// enquanto a latência recente se mantém perto da referência o limite cresce, e quando ela sobe o limite
+// encolhe na mesma proporção. Sinais externos acima do limiar só reduzem o limite, nunca o aumentam.
+type AdaptiveLimiter struct {
+	config  Config
+	signals []Signal
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	shortLatency float64
+	longLatency  float64
+	accepted     int64
+	rejected     int64
+	signalStates []SignalState
+	overloaded   bool
+}
+
+func NewAdaptiveLimiter(config Config, signals ...Signal) *AdaptiveLimiter {
+	if config.MinLimit < 1 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = config.MinLimit
+	}
+	if config.InitialLimit < config.MinLimit || config.InitialLimit > config.MaxLimit {
+		config.InitialLimit = config.MinLimit
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = 1
+	}
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = 0.9
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	states := make([]SignalState, len(signals))
+	for i, signal := range signals {
+		states[i] = SignalState{Name: signal.Name, Threshold: signal.Threshold}
+	}
+
+	return &AdaptiveLimiter{
+		config:       config,
+		signals:      signals,
+		logger:       logger.Log,
+		now:          time.Now,
+		limit:        float64(config.InitialLimit),
+		signalStates: states,
+	}
+}
+
+// Acquire reserva uma vaga; quando ok, release deve ser chamado ao fim da requisição
+func (l *AdaptiveLimiter) Acquire() (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		l.rejected++
+		return nil, false
+	}
+
+	l.inFlight++
+	l.accepted++
+	started := l.now()
+	inFlight := l.inFlight
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.release(l.now().Sub(started), inFlight)
+		})
+	}, true
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, inFlight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	sample := float64(latency)
+	if l.longLatency == 0 {
+		l.shortLatency = sample
+		l.longLatency = sample
+		return
+	}
+	l.shortLatency += (sample - l.shortLatency) * shortSmoothing
+	l.longLatency += (sample - l.longLatency) * longSmoothing
+
+	// depois de um período lento a referência fica inflada; ela volta para a recente quando a carga alivia
+	if l.longLatency > l.shortLatency*l.config.Tolerance {
+		l.longLatency = l.shortLatency
+	}
+
+	gradient := math.Max(minGradient, math.Min(1, l.config.Tolerance*l.longLatency/l.shortLatency))
+	target := l.limit*gradient + math.Sqrt(l.limit)
+
+	// sem uso próximo do limite não há evidência de que ele aguente mais, e sob sinal externo só reduz
+	if target > l.limit && (l.overloaded || inFlight < int(l.limit)/2) {
+		return
+	}
+
+	l.limit = l.clamp(l.limit*(1-limitSmoothing) + target*limitSmoothing)
+}
+
+// Start lê os sinais externos a cada interval até o contexto ser cancelado
+func (l *AdaptiveLimiter) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Sample()
+		}
+	}
+}
+
+// Sample lê os sinais externos e reduz o limite se algum estiver acima do limiar
+func (l *AdaptiveLimiter) Sample() {
+	states := make([]SignalState, len(l.signals))
+	overloaded := false
+	for i, signal := range l.signals {
+		value := signal.Read()
+		states[i] = SignalState{
+			Name:       signal.Name,
+			Value:      value,
+			Threshold:  signal.Threshold,
+			Overloaded: signal.Threshold > 0 && value > signal.Threshold,
+		}
+		overloaded = overloaded || states[i].Overloaded
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if overloaded && !l.overloaded {
+		l.logger.Warn("sinal de sobrecarga detectado, reduzindo o limite de concorrência",
+			zap.Any("signals", states),
+			zap.Float64("limit", l.limit),
+		)
+	}
+	if overloaded {
+		l.limit = l.clamp(l.limit * l.config.Backoff)
+	}
+	l.signalStates = states
+	l.overloaded = overloaded
+}
+
+func (l *AdaptiveLimiter) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return State{
+		Limit:       int(l.limit),
+		InFlight:    l.inFlight,
+		Latency:     time.Duration(l.shortLatency),
+		BaseLatency: time.Duration(l.longLatency),
+		Accepted:    l.accepted,
+		Rejected:    l.rejected,
+		Signals:     append([]SignalState(nil), l.signalStates...),
+		Overloaded:  l.overloaded,
+	}
+}
+
+func (l *AdaptiveLimiter) RetryAfter() time.Duration {
+	return l.config.RetryAfter
+}
+
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), limit))
+}
@@ -0,0 +1,108 @@
+package loadshed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve ocupa todas as vagas do limite e libera cada uma depois de latency
+func serve(t *testing.T, limiter *AdaptiveLimiter, now *time.Time, latency time.Duration) {
+	t.Helper()
+
+	var releases []func()
+	for {
+		release, ok := limiter.Acquire()
+		if !ok {
+			break
+		}
+		releases = append(releases, release)
+	}
+	require.NotEmpty(t, releases)
+
+	*now = now.Add(latency)
+	for _, release := range releases {
+		release()
+	}
+}
+
+func TestAdaptiveLimiter_Acquire(t *testing.T) {
+	// Arrange
+	limiter := NewAdaptiveLimiter(Config{InitialLimit: 2, MinLimit: 1, MaxLimit: 10})
+
+	// Act
+	first, firstOK := limiter.Acquire()
+	_, secondOK := limiter.Acquire()
+	_, thirdOK := limiter.Acquire()
+	first()
+	first()
+	_, afterReleaseOK := limiter.Acquire()
+
+	// Assert
+	assert.True(t, firstOK)
+	assert.True(t, secondOK)
+	assert.False(t, thirdOK, "should reject above the limit")
+	assert.True(t, afterReleaseOK, "a released slot should be reusable, and releasing twice should not free two")
+	state := limiter.State()
+	assert.Equal(t, 2, state.InFlight)
+	assert.Equal(t, int64(3), state.Accepted)
+	assert.Equal(t, int64(1), state.Rejected)
+}
+
+func TestAdaptiveLimiter_Latency(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewAdaptiveLimiter(Config{InitialLimit: 20, MinLimit: 5, MaxLimit: 100, Tolerance: 1.5})
+	limiter.now = func() time.Time { return now }
+
+	for range 20 {
+		serve(t, limiter, &now, 200*time.Millisecond)
+	}
+	grown := limiter.State().Limit
+
+	// Act
+	for range 20 {
+		serve(t, limiter, &now, 800*time.Millisecond)
+	}
+	shrunk := limiter.State().Limit
+
+	// Assert
+	assert.Greater(t, grown, 20, "should grow while latency stays at the baseline")
+	assert.Less(t, shrunk, grown, "should shrink when latency rises above the tolerance")
+	assert.GreaterOrEqual(t, shrunk, 5)
+}
+
+func TestAdaptiveLimiter_Signals(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	depth := 0
+	limiter := NewAdaptiveLimiter(Config{InitialLimit: 50, MinLimit: 5, MaxLimit: 100, Tolerance: 1.5, Backoff: 0.5},
+		QueueDepthSignal("kafka_queue_depth", 100, func() int { return depth }))
+	limiter.now = func() time.Time { return now }
+
+	// Act
+	depth = 500
+	limiter.Sample()
+	afterSignal := limiter.State()
+	for range 5 {
+		serve(t, limiter, &now, 100*time.Millisecond)
+	}
+	whileOverloaded := limiter.State().Limit
+
+	depth = 0
+	limiter.Sample()
+	for range 5 {
+		serve(t, limiter, &now, 100*time.Millisecond)
+	}
+	recovered := limiter.State()
+
+	// Assert
+	assert.Equal(t, 25, afterSignal.Limit)
+	assert.True(t, afterSignal.Overloaded)
+	assert.Equal(t, float64(500), afterSignal.Signals[0].Value)
+	assert.LessOrEqual(t, whileOverloaded, 25, "should not grow while a signal is above its threshold")
+	assert.False(t, recovered.Overloaded)
+	assert.Greater(t, recovered.Limit, whileOverloaded)
+}
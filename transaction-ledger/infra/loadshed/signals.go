@@ -0,0 +1,37 @@
+package loadshed
+
+import (
+	"database/sql"
+	"time"
+)
+
+// QueueDepthSignal usa o tamanho de uma fila interna, como a do writer do Kafka
+func QueueDepthSignal(name string, threshold int, depth func() int) Signal {
+	return Signal{
+		Name:      name,
+		Threshold: float64(threshold),
+		Read: func() float64 {
+			return float64(depth())
+		},
+	}
+}
+
+// DBPoolWaitSignal usa a espera média, em segundos, por uma conexão livre do pool desde a leitura anterior
+func DBPoolWaitSignal(db *sql.DB, threshold time.Duration) Signal {
+	previous := db.Stats()
+	return Signal{
+		Name:      "db_pool_wait_seconds",
+		Threshold: threshold.Seconds(),
+		Read: func() float64 {
+			current := db.Stats()
+			waits := current.WaitCount - previous.WaitCount
+			waited := current.WaitDuration - previous.WaitDuration
+			previous = current
+
+			if waits <= 0 {
+				return 0
+			}
+			return (waited / time.Duration(waits)).Seconds()
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBus entrega as mensagens só dentro do processo; serve para uma única réplica e para testes
+type MemoryBus struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]func(payload []byte)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{handlers: make(map[int]func(payload []byte))}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+func (b *MemoryBus) Listen(ctx context.Context, handler func(payload []byte)) error {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.handlers, id)
+	b.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PostgresBus usa LISTEN/NOTIFY para que todas as réplicas do ledger recebam cada mensagem publicada.
+// O payload do NOTIFY é limitado a 8000 bytes, então só mensagens pequenas devem trafegar por aqui.
+type PostgresBus struct {
+	db      *gorm.DB
+	channel string
+	logger  *zap.Logger
+}
+
+func NewPostgresBus(db *gorm.DB, channel string) *PostgresBus {
+	return &PostgresBus{db: db, channel: channel, logger: logger.Log}
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, payload []byte) error {
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+// Listen mantém uma conexão dedicada escutando o canal e reconecta com backoff até o contexto ser cancelado.
+// Mensagens publicadas enquanto a conexão está caída são perdidas; quem precisa delas relê do banco.
+func (b *PostgresBus) Listen(ctx context.Context, handler func(payload []byte)) error {
+	backoff := time.Second
+	for {
+		err := b.listen(ctx, handler)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		b.logger.Error("conexão de LISTEN perdida, reconectando",
+			zap.Error(err),
+			zap.String("channel", b.channel),
+			zap.Duration("backoff", backoff),
+		)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (b *PostgresBus) listen(ctx context.Context, handler func(payload []byte)) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("driver do banco não suporta LISTEN")
+		}
+		pgConn := stdConn.Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+			return err
+		}
+		b.logger.Info("escutando canal de eventos",
+			zap.String("channel", b.channel),
+		)
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// descarta a conexão em vez de devolvê-la ao pool ainda inscrita no canal
+				return errors.Join(err, driver.ErrBadConn)
+			}
+			handler([]byte(notification.Payload))
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit define um token bucket: Rate tokens por segundo com capacidade Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter consome um token do bucket identificado pela chave
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take aplica o algoritmo de token bucket sobre o estado atual e devolve o novo saldo
+func take(tokens float64, last, now time.Time, limit Limit) (float64, Result) {
+	burst := float64(limit.Burst)
+	if !last.IsZero() {
+		tokens = math.Min(burst, tokens+now.Sub(last).Seconds()*limit.Rate)
+	} else {
+		tokens = burst
+	}
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = secondsToDuration((burst - tokens) / limit.Rate)
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter mantém os buckets em memória; os limites valem apenas para a réplica local
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	idleTTL time.Duration
+	swept   time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+		idleTTL: 10 * time.Minute,
+	}
+}
+
+func (m *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{}
+		m.buckets[key] = b
+	}
+
+	tokens, result := take(b.tokens, b.last, now, limit)
+	b.tokens = tokens
+	b.last = now
+
+	return result, nil
+}
+
+// sweep remove buckets ociosos para não crescer indefinidamente com IPs distintos
+func (m *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(m.swept) < m.idleTTL {
+		return
+	}
+	m.swept = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > m.idleTTL {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+
+	// Act
+	first, _ := limiter.Allow(context.Background(), "key", limit)
+	second, _ := limiter.Allow(context.Background(), "key", limit)
+	third, _ := limiter.Allow(context.Background(), "key", limit)
+	other, _ := limiter.Allow(context.Background(), "other", limit)
+
+	// Assert
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, time.Second, third.RetryAfter)
+	assert.Equal(t, 2*time.Second, third.Reset)
+	assert.True(t, other.Allowed)
+}
+
+func TestMemoryLimiter_Refill(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 1}
+
+	allowed, _ := limiter.Allow(context.Background(), "key", limit)
+	denied, _ := limiter.Allow(context.Background(), "key", limit)
+
+	// Act
+	now = now.Add(500 * time.Millisecond)
+	refilled, _ := limiter.Allow(context.Background(), "key", limit)
+
+	// Assert
+	assert.True(t, allowed.Allowed)
+	assert.False(t, denied.Allowed)
+	assert.True(t, refilled.Allowed)
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const bucketCleanupBatchSize = 1000
+
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey;type:varchar(255)"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"type:timestamp;not null;autoUpdateTime:false;index"`
+}
+
+// PostgresLimiter compartilha os buckets entre réplicas usando uma linha por chave com SELECT ... FOR UPDATE
+type PostgresLimiter struct {
+	db     *gorm.DB
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewPostgresLimiter(db *gorm.DB) *PostgresLimiter {
+	return &PostgresLimiter{db: db, now: time.Now, logger: logger.Log}
+}
+
+func (p *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	var result Result
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := p.now()
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&RateLimitBucket{Key: key, Tokens: float64(limit.Burst), UpdatedAt: now}).Error
+		if err != nil {
+			return err
+		}
+
+		var b RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&b, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		// protege contra relógios adiantados em outras réplicas
+		last := b.UpdatedAt
+		if last.After(now) {
+			last = now
+		}
+
+		var tokens float64
+		tokens, result = take(b.Tokens, last, now, limit)
+
+		return tx.Model(&RateLimitBucket{}).Where("key = ?", key).
+			Updates(map[string]interface{}{"tokens": tokens, "updated_at": now}).Error
+	})
+
+	return result, err
+}
+
+// StartCleanup apaga periodicamente os buckets sem uso há mais de idleTTL, até o contexto ser cancelado.
+// Um bucket ocioso por mais tempo do que leva para encher equivale a um novo, então idleTTL deve ser maior
+// que Burst/Rate dos limites configurados.
+func (p *PostgresLimiter) StartCleanup(ctx context.Context, interval, idleTTL time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Cleanup(ctx, p.now().Add(-idleTTL))
+		}
+	}
+}
+
+// Cleanup apaga em lotes os buckets atualizados pela última vez antes de before
+func (p *PostgresLimiter) Cleanup(ctx context.Context, before time.Time) int64 {
+	var deleted int64
+	for ctx.Err() == nil {
+		// o DELETE do Postgres não aceita LIMIT; a subconsulta apaga em lotes sem travar a tabela inteira
+		result := p.db.WithContext(ctx).Where("key IN (?)", p.db.Model(&RateLimitBucket{}).Select("key").
+			Where("updated_at < ?", before).Limit(bucketCleanupBatchSize)).
+			Delete(&RateLimitBucket{})
+		if result.Error != nil {
+			p.logger.Error("erro ao apagar buckets ociosos do rate limit",
+				zap.Error(result.Error),
+			)
+			break
+		}
+		deleted += result.RowsAffected
+		if result.RowsAffected < bucketCleanupBatchSize {
+			break
+		}
+	}
+	if deleted > 0 {
+		p.logger.Info("buckets ociosos do rate limit apagados",
+			zap.Int64("deleted", deleted),
+		)
+	}
+	return deleted
+}
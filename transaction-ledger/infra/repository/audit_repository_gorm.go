@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+)
+
+type AuditRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewAuditRepositoryGorm(db *gorm.DB) *AuditRepositoryGorm {
+	return &AuditRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *AuditRepositoryGorm) Create(entry *domain.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *AuditRepositoryGorm) FindByEntity(entityType, entityID string) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
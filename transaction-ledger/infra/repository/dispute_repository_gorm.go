@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DisputeRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewDisputeRepositoryGorm(db *gorm.DB) *DisputeRepositoryGorm {
+	return &DisputeRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *DisputeRepositoryGorm) Create(dispute *domain.Dispute) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "processor_dispute_id"}}, DoNothing: true}).Create(dispute)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *DisputeRepositoryGorm) SumDisputedAmount(transactionID string) (float64, error) {
+	var total float64
+	err := r.db.Model(&domain.Dispute{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("transaction_id = ? AND status <> ?", transactionID, domain.DisputeWon).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *DisputeRepositoryGorm) FindByID(id string) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	err := r.db.First(&dispute, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *DisputeRepositoryGorm) FindByProcessorID(processorDisputeID string) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	err := r.db.First(&dispute, "processor_dispute_id = ?", processorDisputeID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *DisputeRepositoryGorm) FindPaginated(filter dRepo.DisputeFilter, page, pageSize int) ([]domain.Dispute, int64, error) {
+	var disputes []domain.Dispute
+	var total int64
+
+	offset := (page - 1) * pageSize
+
+	if err := applyDisputeFilter(r.db.Model(&domain.Dispute{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := applyDisputeFilter(r.db, filter).Order("evidence_due_at ASC").Offset(offset).Limit(pageSize).Find(&disputes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return disputes, total, nil
+}
+
+func (r *DisputeRepositoryGorm) UpdateIfStatus(dispute *domain.Dispute, expectedStatus string) error {
+	return updateDisputeIfStatus(r.db, dispute, expectedStatus)
+}
+
+func (r *DisputeRepositoryGorm) Resolve(dispute *domain.Dispute, expectedStatus string, adjustment *domain.LedgerAdjustment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateDisputeIfStatus(tx, dispute, expectedStatus); err != nil {
+			return err
+		}
+		if adjustment == nil {
+			return nil
+		}
+		return tx.Create(adjustment).Error
+	})
+}
+
+func updateDisputeIfStatus(db *gorm.DB, dispute *domain.Dispute, expectedStatus string) error {
+	result := db.Model(dispute).Where("status = ?", expectedStatus).Select("*").Omit("created_at").Updates(dispute)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrorDisputeStatusChanged
+	}
+	return nil
+}
+
+func (r *DisputeRepositoryGorm) FindOverdue(now time.Time, limit int) ([]domain.Dispute, error) {
+	var disputes []domain.Dispute
+	err := r.db.Where("status = ? AND evidence_due_at < ?", domain.DisputeOpened, now).
+		Order("evidence_due_at ASC").Limit(limit).Find(&disputes).Error
+	return disputes, err
+}
+
+func (r *DisputeRepositoryGorm) AddEvidence(evidence *domain.DisputeEvidence) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// o lock de linha serializa o anexo com o envio da evidência, que troca o status
+		var dispute domain.Dispute
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, "id = ?", evidence.DisputeID).Error; err != nil {
+			return err
+		}
+		if dispute.Status != domain.DisputeOpened {
+			return domain.ErrorDisputeNotOpen
+		}
+		return tx.Create(evidence).Error
+	})
+}
+
+func (r *DisputeRepositoryGorm) FindEvidence(disputeID string) ([]domain.DisputeEvidence, error) {
+	var evidence []domain.DisputeEvidence
+	err := r.db.Where("dispute_id = ?", disputeID).Order("created_at ASC").Find(&evidence).Error
+	return evidence, err
+}
+
+func (r *DisputeRepositoryGorm) FindAdjustment(disputeID string) (*domain.LedgerAdjustment, error) {
+	var adjustment domain.LedgerAdjustment
+	err := r.db.First(&adjustment, "dispute_id = ?", disputeID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+func (r *DisputeRepositoryGorm) FindAdjustments(merchantID, tenantID string, page, pageSize int) ([]domain.LedgerAdjustment, int64, error) {
+	query := r.db.Model(&domain.LedgerAdjustment{})
+	if merchantID != "" {
+		query = query.Where("merchant_id = ?", merchantID)
+	}
+	if tenantID != "" {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var adjustments []domain.LedgerAdjustment
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&adjustments).Error
+	return adjustments, total, err
+}
+
+func applyDisputeFilter(query *gorm.DB, filter dRepo.DisputeFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+	if filter.TransactionID != "" {
+		query = query.Where("transaction_id = ?", filter.TransactionID)
+	}
+	return query
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+)
+
+type ExportRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewExportRepositoryGorm(db *gorm.DB) *ExportRepositoryGorm {
+	return &ExportRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *ExportRepositoryGorm) Create(job *domain.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ExportRepositoryGorm) FindByID(id string) (*domain.ExportJob, error) {
+	var job domain.ExportJob
+	err := r.db.First(&job, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportRepositoryGorm) Update(job *domain.ExportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *ExportRepositoryGorm) FindExpired(now time.Time, limit int) ([]domain.ExportJob, error) {
+	var jobs []domain.ExportJob
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.ExportCompleted, now).
+		Order("expires_at ASC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *ExportRepositoryGorm) FindStale(before time.Time, limit int) ([]domain.ExportJob, error) {
+	var jobs []domain.ExportJob
+	err := r.db.Where("status IN ? AND updated_at < ?", []string{domain.ExportPending, domain.ExportRunning}, before).
+		Order("updated_at ASC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
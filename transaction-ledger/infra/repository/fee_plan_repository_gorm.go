@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"gorm.io/gorm"
+)
+
+type FeePlanRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewFeePlanRepositoryGorm(db *gorm.DB) *FeePlanRepositoryGorm {
+	return &FeePlanRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *FeePlanRepositoryGorm) Create(plan *domain.FeePlan) error {
+	return r.db.Create(plan).Error
+}
+
+func (r *FeePlanRepositoryGorm) FindAll(filter dRepo.FeePlanFilter) ([]domain.FeePlan, error) {
+	query := r.db.Model(&domain.FeePlan{})
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filter.PaymentMethod)
+	}
+
+	var plans []domain.FeePlan
+	err := query.Order("effective_from DESC").Find(&plans).Error
+	return plans, err
+}
+
+func (r *FeePlanRepositoryGorm) FindEffective(merchantID, paymentMethod string, at time.Time) ([]domain.FeePlan, error) {
+	var plans []domain.FeePlan
+	err := r.db.Where("(merchant_id = ? OR merchant_id = '') AND payment_method = ? AND effective_from <= ?", merchantID, paymentMethod, at).
+		Order("effective_from DESC").Find(&plans).Error
+	return plans, err
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FXRateRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewFXRateRepositoryGorm(db *gorm.DB) *FXRateRepositoryGorm {
+	return &FXRateRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *FXRateRepositoryGorm) Upsert(rates []domain.FXRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(&rates).Error
+}
+
+func (r *FXRateRepositoryGorm) FindAll() ([]domain.FXRate, error) {
+	var rates []domain.FXRate
+	err := r.db.Find(&rates).Error
+	return rates, err
+}
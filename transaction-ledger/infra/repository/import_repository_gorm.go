@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewImportRepositoryGorm(db *gorm.DB) *ImportRepositoryGorm {
+	return &ImportRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *ImportRepositoryGorm) Create(job *domain.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ImportRepositoryGorm) FindByID(id string) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	err := r.db.First(&job, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ImportRepositoryGorm) FindByContentHash(merchantID, tenantID, contentHash string) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	err := r.db.First(&job, "merchant_id = ? AND tenant_id = ? AND content_hash = ?", merchantID, tenantID, contentHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ImportRepositoryGorm) Claim(job *domain.ImportJob, staleBefore time.Time) (bool, error) {
+	result := r.db.Model(&domain.ImportJob{}).
+		Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))",
+			job.ID, []string{domain.ImportPending, domain.ImportFailed}, domain.ImportRunning, staleBefore).
+		Updates(map[string]any{
+			"status":       job.Status,
+			"error":        job.Error,
+			"started_at":   job.StartedAt,
+			"completed_at": job.CompletedAt,
+			"updated_at":   time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *ImportRepositoryGorm) SaveProgress(job *domain.ImportJob, rowErrors []domain.ImportRowError) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// um bloco refeito depois de uma queda pode repetir erros já gravados
+		if len(rowErrors) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rowErrors).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(job).Error
+	})
+}
+
+func (r *ImportRepositoryGorm) Update(job *domain.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *ImportRepositoryGorm) FindRowErrors(jobID string, page, pageSize int) ([]domain.ImportRowError, int64, error) {
+	var rowErrors []domain.ImportRowError
+	var total int64
+
+	if err := r.db.Model(&domain.ImportRowError{}).Where("import_job_id = ?", jobID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Where("import_job_id = ?", jobID).Order("row ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&rowErrors).Error
+	return rowErrors, total, err
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+)
+
+type InstallmentRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepositoryGorm(db *gorm.DB) *InstallmentRepositoryGorm {
+	return &InstallmentRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *InstallmentRepositoryGorm) FindByTransactionID(transactionID string) ([]domain.Installment, error) {
+	var installments []domain.Installment
+	err := r.db.Where("transaction_id = ?", transactionID).Order("number ASC").Find(&installments).Error
+	return installments, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepositoryGorm(db *gorm.DB) *ReconciliationRepositoryGorm {
+	return &ReconciliationRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *ReconciliationRepositoryGorm) Create(reconciliation *domain.Reconciliation) error {
+	return r.db.Create(reconciliation).Error
+}
+
+func (r *ReconciliationRepositoryGorm) FindByID(id string) (*domain.Reconciliation, error) {
+	var reconciliation domain.Reconciliation
+	err := r.db.First(&reconciliation, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reconciliation, nil
+}
+
+func (r *ReconciliationRepositoryGorm) UpdateIfRunning(reconciliation *domain.Reconciliation) error {
+	result := r.db.Model(reconciliation).Where("status = ?", domain.ReconciliationRunning).Select("*").Updates(reconciliation)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrorReconciliationNotRunning
+	}
+	return nil
+}
+
+func (r *ReconciliationRepositoryGorm) FailStale(before time.Time, message string, now time.Time) (int64, error) {
+	result := r.db.Model(&domain.Reconciliation{}).
+		Where("status = ? AND updated_at < ?", domain.ReconciliationRunning, before).
+		Updates(map[string]any{"status": domain.ReconciliationFailed, "error": message, "completed_at": now, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ReconciliationRepositoryGorm) SaveItems(items []domain.ReconciliationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(items, 500).Error
+}
+
+func (r *ReconciliationRepositoryGorm) FindItems(reconciliationID, result string, page, pageSize int) ([]domain.ReconciliationItem, int64, error) {
+	query := r.db.Model(&domain.ReconciliationItem{}).Where("reconciliation_id = ?", reconciliationID)
+	if result != "" {
+		query = query.Where("result = ?", result)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.ReconciliationItem
+	err := query.Order("id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error
+	return items, total, err
+}
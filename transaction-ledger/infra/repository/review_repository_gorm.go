@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"gorm.io/gorm"
+)
+
+type ReviewRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewReviewRepositoryGorm(db *gorm.DB) *ReviewRepositoryGorm {
+	return &ReviewRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *ReviewRepositoryGorm) Create(review *domain.Review) error {
+	return r.db.Create(review).Error
+}
+
+func (r *ReviewRepositoryGorm) FindByID(id string) (*domain.Review, error) {
+	var review domain.Review
+	err := r.db.First(&review, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepositoryGorm) Decide(review *domain.Review, transaction *domain.Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateReviewIfPending(tx, review); err != nil {
+			return err
+		}
+		return updateTransactionIfStatus(tx, transaction, domain.TransactionReview)
+	})
+}
+
+// updateReviewIfPending persiste a decisão somente se a revisão ainda estiver pendente
+func updateReviewIfPending(db *gorm.DB, review *domain.Review) error {
+	result := db.Model(&domain.Review{}).
+		Where("id = ? AND status = ?", review.ID, domain.ReviewPending).
+		Updates(map[string]interface{}{
+			"status":     review.Status,
+			"reviewer":   review.Reviewer,
+			"notes":      review.Notes,
+			"decided_at": review.DecidedAt,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrorReviewAlreadyDecided
+	}
+	return nil
+}
+
+func (r *ReviewRepositoryGorm) FindPaginated(filter dRepo.ReviewFilter, page, pageSize int) ([]domain.Review, int64, error) {
+	var reviews []domain.Review
+	var total int64
+
+	offset := (page - 1) * pageSize
+
+	if err := applyReviewFilter(r.db.Model(&domain.Review{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := applyReviewFilter(r.db, filter).Order("due_at ASC").Offset(offset).Limit(pageSize).Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (r *ReviewRepositoryGorm) FindOverdue(now time.Time, limit int) ([]domain.Review, error) {
+	var reviews []domain.Review
+	err := r.db.Where("status = ? AND due_at < ?", domain.ReviewPending, now).
+		Order("due_at ASC").Limit(limit).Find(&reviews).Error
+	return reviews, err
+}
+
+func applyReviewFilter(query *gorm.DB, filter dRepo.ReviewFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+	return query
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettlementRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewSettlementRepositoryGorm(db *gorm.DB) *SettlementRepositoryGorm {
+	return &SettlementRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *SettlementRepositoryGorm) FindUnbatched(limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Where("status = ? AND settlement_batch_id IS NULL", domain.TransactionFinished).
+		Order("created_at ASC").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *SettlementRepositoryGorm) FindOrCreateOpen(batch *domain.SettlementBatch) (*domain.SettlementBatch, error) {
+	find := func() (*domain.SettlementBatch, error) {
+		var open domain.SettlementBatch
+		err := r.db.Where("merchant_id = ? AND tenant_id = ? AND currency_code = ? AND payment_method = ? AND reference_date = ? AND status = ?",
+			batch.MerchantID, batch.TenantID, batch.CurrencyCode, batch.PaymentMethod, batch.ReferenceDate, domain.SettlementBatchOpen).
+			First(&open).Error
+		return &open, err
+	}
+
+	open, err := find()
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return open, err
+	}
+
+	// o índice único parcial garante um único lote OPEN por chave entre réplicas; quem perde a corrida relê
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(batch)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return batch, nil
+	}
+	return find()
+}
+
+func (r *SettlementRepositoryGorm) Assign(batch *domain.SettlementBatch, ids []string) (int64, error) {
+	var assigned int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// FOR SHARE espera um Close concorrente terminar; depois dele o lote não recebe mais transações
+		var current domain.SettlementBatch
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&current, "id = ?", batch.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != domain.SettlementBatchOpen {
+			return domain.ErrorSettlementBatchNotOpen
+		}
+
+		result := tx.Model(&domain.Transaction{}).
+			Where("id IN ? AND status = ? AND settlement_batch_id IS NULL", ids, domain.TransactionFinished).
+			Update("settlement_batch_id", batch.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		assigned = result.RowsAffected
+
+		if err := r.detachUnfinished(tx, batch.ID); err != nil {
+			return err
+		}
+		*batch = current
+		return r.saveTotals(tx, batch)
+	})
+	return assigned, err
+}
+
+func (r *SettlementRepositoryGorm) Close(batch *domain.SettlementBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.SettlementBatch{}).
+			Where("id = ? AND status = ?", batch.ID, domain.SettlementBatchOpen).
+			Updates(map[string]any{"status": batch.Status, "closed_at": batch.ClosedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrorSettlementBatchNotOpen
+		}
+
+		// trava primeiro: quem mudar o status depois disso encontra a transação travada e é rejeitado
+		if err := tx.Model(&domain.Transaction{}).Where("settlement_batch_id = ? AND status = ?", batch.ID, domain.TransactionFinished).
+			Update("settlement_locked", true).Error; err != nil {
+			return err
+		}
+		if err := r.detachUnfinished(tx, batch.ID); err != nil {
+			return err
+		}
+		return r.saveTotals(tx, batch)
+	})
+}
+
+// detachUnfinished solta do lote as transações que deixaram de estar FINISHED depois de atribuídas; elas
+// não entram nos totais nem são travadas, e voltam a ser loteadas se terminarem de novo
+func (r *SettlementRepositoryGorm) detachUnfinished(tx *gorm.DB, batchID string) error {
+	return tx.Model(&domain.Transaction{}).
+		Where("settlement_batch_id = ? AND status <> ? AND settlement_locked = ?", batchID, domain.TransactionFinished, false).
+		Update("settlement_batch_id", nil).Error
+}
+
+// saveTotals recalcula os totais pelo banco, para refletir exatamente as transações FINISHED do lote
+func (r *SettlementRepositoryGorm) saveTotals(tx *gorm.DB, batch *domain.SettlementBatch) error {
+	var totals struct {
+		Count int
+		Gross float64
+		Fee   float64
+	}
+	err := tx.Model(&domain.Transaction{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(CASE WHEN captured_amount > 0 THEN captured_amount ELSE amount END), 0) AS gross, COALESCE(SUM(fee_amount), 0) AS fee").
+		Where("settlement_batch_id = ? AND status = ?", batch.ID, domain.TransactionFinished).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+
+	batch.ApplyTotals(totals.Count, totals.Gross, totals.Fee)
+	return tx.Model(batch).Select("transaction_count", "gross_amount", "fee_amount", "net_amount", "updated_at").Updates(batch).Error
+}
+
+func (r *SettlementRepositoryGorm) UpdateIfStatus(batch *domain.SettlementBatch, expectedStatus string) error {
+	result := r.db.Model(batch).Where("status = ?", expectedStatus).Select("*").Omit("created_at").Updates(batch)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrorTransactionStatusChanged
+	}
+	return nil
+}
+
+func (r *SettlementRepositoryGorm) FindByID(id string) (*domain.SettlementBatch, error) {
+	var batch domain.SettlementBatch
+	err := r.db.First(&batch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *SettlementRepositoryGorm) FindPaginated(filter dRepo.SettlementBatchFilter, page, pageSize int) ([]domain.SettlementBatch, int64, error) {
+	query := r.db.Model(&domain.SettlementBatch{})
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filter.PaymentMethod)
+	}
+	if filter.CurrencyCode != "" {
+		query = query.Where("currency_code = ?", filter.CurrencyCode)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []domain.SettlementBatch
+	err := query.Order("reference_date DESC, created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&batches).Error
+	return batches, total, err
+}
+
+func (r *SettlementRepositoryGorm) FindDueOpen(before time.Time, limit int) ([]domain.SettlementBatch, error) {
+	var batches []domain.SettlementBatch
+	err := r.db.Where("status = ? AND reference_date < ?", domain.SettlementBatchOpen, before).
+		Order("reference_date ASC").Limit(limit).Find(&batches).Error
+	return batches, err
+}
+
+func (r *SettlementRepositoryGorm) FindTransactions(batchID string, page, pageSize int) ([]domain.Transaction, int64, error) {
+	query := r.db.Model(&domain.Transaction{}).Where("settlement_batch_id = ?", batchID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []domain.Transaction
+	err := query.Order("created_at ASC, id ASC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&transactions).Error
+	return transactions, total, err
+}
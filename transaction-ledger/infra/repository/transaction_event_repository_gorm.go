@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
+	"gorm.io/gorm"
+)
+
+type TransactionEventRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewTransactionEventRepositoryGorm(db *gorm.DB) *TransactionEventRepositoryGorm {
+	return &TransactionEventRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *TransactionEventRepositoryGorm) Create(event *domain.TransactionEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *TransactionEventRepositoryGorm) FindAfter(filter dRepo.TransactionEventFilter, afterID int64, limit int) ([]domain.TransactionEvent, error) {
+	query := r.db.Where("id > ?", afterID)
+	if filter.TransactionID != "" {
+		query = query.Where("transaction_id = ?", filter.TransactionID)
+	}
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+
+	var events []domain.TransactionEvent
+	err := query.Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *TransactionEventRepositoryGorm) DeleteBefore(before time.Time, limit int) (int64, error) {
+	// o DELETE do Postgres não aceita LIMIT; a subconsulta apaga em lotes sem travar a tabela inteira
+	result := r.db.Where("id IN (?)", r.db.Model(&domain.TransactionEvent{}).Select("id").
+		Where("created_at < ?", before).Order("id ASC").Limit(limit)).
+		Delete(&domain.TransactionEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -1,8 +1,15 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	dRepo "github.com/NathanGdS/transaction-hub/transaction-ledger/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepositoryGorm struct {
@@ -19,6 +26,91 @@ func (r *TransactionRepositoryGorm) Create(transaction *domain.Transaction) erro
 	return r.db.Create(transaction).Error
 }
 
+func (r *TransactionRepositoryGorm) CreateBatch(creation *dRepo.TransactionCreation) error {
+	if len(creation.Transactions) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// a chave vai primeiro: quem perde a corrida pela mesma chave desiste antes de gravar as transações
+		if creation.BatchRequest != nil {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(creation.BatchRequest)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrorBatchRequestExists
+			}
+		}
+		if err := reserveQuotas(tx, creation.Quotas); err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(creation.Transactions, 500).Error; err != nil {
+			return err
+		}
+		if len(creation.Installments) > 0 {
+			if err := tx.CreateInBatches(creation.Installments, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(creation.Reviews) > 0 {
+			if err := tx.CreateInBatches(creation.Reviews, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(creation.Cards) > 0 {
+			if err := tx.CreateInBatches(creation.Cards, 500).Error; err != nil {
+				return err
+			}
+		}
+		return enqueueWebhookDeliveries(tx, creation.Transactions, "")
+	})
+}
+
+// reserveQuotas trava a linha de cada quota e confere a soma do dia sob a trava. As travas são tomadas
+// sempre na mesma ordem, para duas criações com as mesmas quotas não se bloquearem mutuamente.
+func reserveQuotas(tx *gorm.DB, reservations []dRepo.QuotaReservation) error {
+	sorted := append([]dRepo.QuotaReservation(nil), reservations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].MerchantID != sorted[j].MerchantID {
+			return sorted[i].MerchantID < sorted[j].MerchantID
+		}
+		return sorted[i].CurrencyCode < sorted[j].CurrencyCode
+	})
+
+	for _, reservation := range sorted {
+		quota := domain.DailyQuota{MerchantID: reservation.MerchantID, CurrencyCode: reservation.CurrencyCode, Day: reservation.Day}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&quota).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&quota, "merchant_id = ? AND currency_code = ? AND day = ?", quota.MerchantID, quota.CurrencyCode, quota.Day).Error; err != nil {
+			return err
+		}
+
+		// a soma roda depois da trava, então enxerga o que as criações anteriores já gravaram
+		used, err := sumAmountByMerchant(tx, reservation.MerchantID, reservation.CurrencyCode, reservation.Day)
+		if err != nil {
+			return err
+		}
+		if used+reservation.Amount > reservation.Limit {
+			return &dRepo.QuotaReservationError{Reservation: reservation, Used: used}
+		}
+	}
+	return nil
+}
+
+func (r *TransactionRepositoryGorm) FindBatchRequest(merchantID, idempotencyKey string) (*domain.BatchRequest, error) {
+	var request domain.BatchRequest
+	err := r.db.First(&request, "merchant_id = ? AND idempotency_key = ?", merchantID, idempotencyKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
 func (r *TransactionRepositoryGorm) FindByID(id string) (*domain.Transaction, error) {
 	var transaction domain.Transaction
 	err := r.db.First(&transaction, "id = ?", id).Error
@@ -28,12 +120,111 @@ func (r *TransactionRepositoryGorm) FindByID(id string) (*domain.Transaction, er
 	return &transaction, nil
 }
 
+func (r *TransactionRepositoryGorm) FindExistingIDs(ids []string) ([]string, error) {
+	var existing []string
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&domain.Transaction{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+func (r *TransactionRepositoryGorm) FindByIDs(ids []string) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&transactions).Error
+	return transactions, err
+}
+
+// transactionUpdateOmit são as colunas que Update e UpdateIfStatus nunca gravam: os campos da liquidação
+// só mudam pelo SettlementRepositoryGorm, para uma cópia antiga da transação não tirá-la do lote
+var transactionUpdateOmit = []string{"created_at", "settlement_batch_id", "settlement_locked"}
+
+// Update grava a transação, exceto quando ela pertence a um lote de liquidação já fechado
 func (r *TransactionRepositoryGorm) Update(transaction *domain.Transaction) error {
-	return r.db.Save(transaction).Error
+	result := r.db.Model(transaction).Where("settlement_locked = ?", false).Select("*").Omit(transactionUpdateOmit...).Updates(transaction)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var locked []bool
+		if err := r.db.Model(&domain.Transaction{}).Where("id = ?", transaction.ID).Pluck("settlement_locked", &locked).Error; err != nil {
+			return err
+		}
+		if len(locked) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return domain.ErrorTransactionLocked
+	}
+	return nil
+}
+
+func (r *TransactionRepositoryGorm) UpdateIfStatus(transaction *domain.Transaction, expectedStatus string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return updateTransactionIfStatus(tx, transaction, expectedStatus)
+	})
+}
+
+func (r *TransactionRepositoryGorm) UpdateIfStatusWithSchedule(transaction *domain.Transaction, expectedStatus string, schedule []domain.Installment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateTransactionIfStatus(tx, transaction, expectedStatus); err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&domain.Installment{}).Error; err != nil {
+			return err
+		}
+		if len(schedule) == 0 {
+			return nil
+		}
+		return tx.Create(&schedule).Error
+	})
+}
+
+func updateTransactionIfStatus(db *gorm.DB, transaction *domain.Transaction, expectedStatus string) error {
+	result := db.Model(transaction).Where("status = ? AND settlement_locked = ?", expectedStatus, false).Select("*").Omit(transactionUpdateOmit...).Updates(transaction)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrorTransactionStatusChanged
+	}
+	if transaction.Status == expectedStatus {
+		return nil
+	}
+	return enqueueWebhookDeliveries(db, []*domain.Transaction{transaction}, expectedStatus)
+}
+
+// enqueueWebhookDeliveries grava as entregas de webhook da mudança de status na transação do banco que grava a
+// mudança: a entrega existe se e somente se a mudança foi gravada, mesmo que o processo caia logo depois
+func enqueueWebhookDeliveries(db *gorm.DB, transactions []*domain.Transaction, previousStatus string) error {
+	now := time.Now()
+	webhooksByMerchant := make(map[string][]domain.Webhook)
+	var deliveries []domain.WebhookDelivery
+	for _, transaction := range transactions {
+		webhooks, ok := webhooksByMerchant[transaction.MerchantID]
+		if !ok {
+			if err := db.Where("merchant_id = ? AND active = ?", transaction.MerchantID, true).Order("created_at ASC").Find(&webhooks).Error; err != nil {
+				return err
+			}
+			webhooksByMerchant[transaction.MerchantID] = webhooks
+		}
+
+		created, err := domain.NewStatusChangedDeliveries(webhooks, transaction, previousStatus, now)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, created...)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(deliveries, 500).Error
 }
 
 func (r *TransactionRepositoryGorm) Delete(id string) error {
-	return r.db.Delete(&domain.Transaction{}, "id = ?", id).Error
+	return r.db.Delete(&domain.Transaction{}, "id = ? AND settlement_locked = ?", id, false).Error
 }
 
 func (r *TransactionRepositoryGorm) FindAll() ([]*domain.Transaction, error) {
@@ -45,19 +236,140 @@ func (r *TransactionRepositoryGorm) FindAll() ([]*domain.Transaction, error) {
 	return transactions, nil
 }
 
-func (r *TransactionRepositoryGorm) FindPaginated(page, pageSize int) ([]domain.Transaction, int64, error) {
+func (r *TransactionRepositoryGorm) FindPaginated(filter dRepo.TransactionFilter, page, pageSize int) ([]domain.Transaction, int64, error) {
 	var transactions []domain.Transaction
 	var total int64
 
 	offset := (page - 1) * pageSize
 
-	if err := r.db.Model(&domain.Transaction{}).Count(&total).Error; err != nil {
+	if err := applyTransactionFilter(r.db.Model(&domain.Transaction{}), filter).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&transactions).Error; err != nil {
+	if err := applyTransactionFilter(r.db, filter).Offset(offset).Limit(pageSize).Find(&transactions).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return transactions, total, nil
 }
+
+// exportFetchSize é quantas linhas cada FETCH do cursor traz; só esse bloco fica em memória
+const exportFetchSize = 500
+
+// Stream usa um cursor do Postgres dentro de uma transação somente leitura, para que a memória
+// não cresça com o volume exportado
+func (r *TransactionRepositoryGorm) Stream(filter dRepo.TransactionFilter, fn func(transaction *domain.Transaction) error) error {
+	query := "SELECT * FROM transactions WHERE deleted_at IS NULL"
+	conditions, args := transactionFilterConditions(filter)
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+	query += " ORDER BY created_at ASC, id ASC"
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DECLARE transaction_export NO SCROLL CURSOR FOR "+query, args...).Error; err != nil {
+			return err
+		}
+
+		for {
+			var batch []domain.Transaction
+			// o FETCH não aceita parâmetro no tamanho, por isso a constante vai no texto
+			if err := tx.Raw(fmt.Sprintf("FETCH FORWARD %d FROM transaction_export", exportFetchSize)).Scan(&batch).Error; err != nil {
+				return err
+			}
+			for i := range batch {
+				if err := fn(&batch[i]); err != nil {
+					return err
+				}
+			}
+			if len(batch) < exportFetchSize {
+				return tx.Exec("CLOSE transaction_export").Error
+			}
+		}
+	})
+}
+
+// SumAmountByMerchant soma o valor das transações do merchant criadas a partir de since que ainda consomem a
+// quota, ou seja, fora de domain.QuotaReleasedStatuses
+func (r *TransactionRepositoryGorm) SumAmountByMerchant(merchantID, currencyCode string, since time.Time) (float64, error) {
+	return sumAmountByMerchant(r.db, merchantID, currencyCode, since)
+}
+
+func sumAmountByMerchant(db *gorm.DB, merchantID, currencyCode string, since time.Time) (float64, error) {
+	var total float64
+	err := db.Model(&domain.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("merchant_id = ? AND currency_code = ? AND created_at >= ? AND status NOT IN ?", merchantID, currencyCode, since, domain.QuotaReleasedStatuses).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *TransactionRepositoryGorm) CountByMerchantSince(merchantID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Transaction{}).
+		Where("merchant_id = ? AND created_at >= ?", merchantID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *TransactionRepositoryGorm) CountByCardSince(fingerprint string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Transaction{}).
+		Where("card_fingerprint = ? AND created_at >= ?", fingerprint, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *TransactionRepositoryGorm) FindExpired(now time.Time, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Where("status IN ? AND expires_at IS NOT NULL AND expires_at < ?", []string{domain.TransactionPending, domain.TransactionAuthorized}, now).
+		Order("expires_at ASC").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *TransactionRepositoryGorm) FindStuckProcessing(before time.Time, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	statuses := []string{domain.TransactionPending, domain.TransactionCapturePending, domain.TransactionVoidPending}
+	err := r.db.Where("status IN ? AND updated_at < ?", statuses, before).
+		Order("updated_at ASC").Limit(limit).Find(&transactions).Error
+	return transactions, err
+}
+
+func applyTransactionFilter(query *gorm.DB, filter dRepo.TransactionFilter) *gorm.DB {
+	conditions, args := transactionFilterConditions(filter)
+	for i, condition := range conditions {
+		query = query.Where(condition, args[i])
+	}
+	return query
+}
+
+// transactionFilterConditions devolve uma condição por campo preenchido, cada uma com um único argumento,
+// para servir tanto às consultas do GORM quanto ao SQL do cursor em Stream
+func transactionFilterConditions(filter dRepo.TransactionFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if filter.MerchantID != "" {
+		conditions = append(conditions, "merchant_id = ?")
+		args = append(args, filter.MerchantID)
+	}
+	if filter.TenantID != "" {
+		conditions = append(conditions, "tenant_id = ?")
+		args = append(args, filter.TenantID)
+	}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status IN ?")
+		args = append(args, filter.Statuses)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedTo)
+	}
+	return conditions, args
+}
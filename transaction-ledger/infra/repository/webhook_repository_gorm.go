@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepositoryGorm(db *gorm.DB) *WebhookRepositoryGorm {
+	return &WebhookRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *WebhookRepositoryGorm) Create(webhook *domain.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *WebhookRepositoryGorm) FindByID(id string) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.First(&webhook, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepositoryGorm) FindByMerchant(merchantID, tenantID string) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if tenantID != "" {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	err := query.Order("created_at ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *WebhookRepositoryGorm) Delete(id string) error {
+	return r.db.Delete(&domain.Webhook{}, "id = ?", id).Error
+}
+
+type WebhookDeliveryRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepositoryGorm(db *gorm.DB) *WebhookDeliveryRepositoryGorm {
+	return &WebhookDeliveryRepositoryGorm{
+		db: db,
+	}
+}
+
+func (r *WebhookDeliveryRepositoryGorm) CreateBatch(deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+func (r *WebhookDeliveryRepositoryGorm) FindByID(id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.First(&delivery, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDeliveryRepositoryGorm) FindByWebhook(webhookID string, page, pageSize int) ([]domain.WebhookDelivery, int64, error) {
+	var deliveries []domain.WebhookDelivery
+	var total int64
+
+	if err := r.db.Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Where("webhook_id = ?", webhookID).Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *WebhookDeliveryRepositoryGorm) ClaimDue(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&domain.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+func (r *WebhookDeliveryRepositoryGorm) Update(delivery *domain.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should save, open and delete a file by key", func(t *testing.T) {
+		// Arrange
+		storage, err := NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+
+		// Act
+		written, err := storage.Save(ctx, "disputes/d-1/e-1", strings.NewReader("evidência"))
+		require.NoError(t, err)
+		file, err := storage.Open(ctx, "disputes/d-1/e-1")
+		require.NoError(t, err)
+		content, readErr := io.ReadAll(file)
+		file.Close()
+		deleteErr := storage.Delete(ctx, "disputes/d-1/e-1")
+		_, openErr := storage.Open(ctx, "disputes/d-1/e-1")
+
+		// Assert
+		assert.Equal(t, int64(len("evidência")), written)
+		require.NoError(t, readErr)
+		assert.Equal(t, "evidência", string(content))
+		assert.NoError(t, deleteErr)
+		assert.ErrorIs(t, openErr, ErrNotFound)
+	})
+
+	t.Run("Should reject keys outside the root directory", func(t *testing.T) {
+		// Arrange
+		storage, err := NewLocalStorage(t.TempDir())
+		require.NoError(t, err)
+
+		// Act
+		_, saveErr := storage.Save(ctx, "../escape", strings.NewReader("x"))
+		_, openErr := storage.Open(ctx, "/etc/passwd")
+
+		// Assert
+		assert.Error(t, saveErr)
+		assert.Error(t, openErr)
+	})
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("stored file not found")
+
+// FileStorage guarda arquivos enviados pelos merchants. As chaves são caminhos relativos com "/",
+// gerados pela aplicação, nunca nomes vindos do cliente.
+type FileStorage interface {
+	// Save grava o conteúdo de r na chave e devolve quantos bytes foram gravados
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
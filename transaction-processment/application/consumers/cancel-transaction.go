@@ -0,0 +1,53 @@
+package consumers
+
+import (
+	"encoding/json"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-processment/application/services"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// CancelTransactionConsumer recebe os pedidos de cancelamento em todas as instâncias do processador,
+// já que não dá para saber qual delas está com a transação em andamento
+type CancelTransactionConsumer struct {
+	kafkaBroker akafka.KafkaBroker
+	logger      *zap.Logger
+	service     *services.ProcessTransactionService
+}
+
+func NewCancelTransactionConsumer(broker *akafka.KafkaBroker, service *services.ProcessTransactionService) *CancelTransactionConsumer {
+	return &CancelTransactionConsumer{
+		kafkaBroker: *broker,
+		logger:      logger.Log,
+		service:     service,
+	}
+}
+
+func (c *CancelTransactionConsumer) Start() {
+	msgChan := make(chan *kafka.Message)
+	go c.kafkaBroker.ConsumeBroadcast([]string{domain.CancelTransactionTopic}, msgChan)
+
+	for msg := range msgChan {
+		c.processMessage(msg)
+	}
+}
+
+func (c *CancelTransactionConsumer) processMessage(msg *kafka.Message) {
+	var cancelDto dto.CancelTransactionDto
+	if err := json.Unmarshal(msg.Value, &cancelDto); err != nil {
+		c.logger.Error("erro ao converter para JSON",
+			zap.Error(err),
+		)
+		return
+	}
+
+	c.logger.Info("pedido de cancelamento recebido",
+		zap.String("id", cancelDto.TransactionID),
+	)
+	c.service.CancelTransaction(cancelDto.TransactionID, msg.Time)
+}
@@ -18,17 +18,17 @@ type ProcessTransactionConsumer struct {
 	service     *services.ProcessTransactionService
 }
 
-func NewProcessTransactionConsumer(broker *akafka.KafkaBroker) *ProcessTransactionConsumer {
+func NewProcessTransactionConsumer(broker *akafka.KafkaBroker, service *services.ProcessTransactionService) *ProcessTransactionConsumer {
 	return &ProcessTransactionConsumer{
 		kafkaBroker: *broker,
 		logger:      logger.Log,
-		service:     services.NewProcessTransactionService(*broker),
+		service:     service,
 	}
 }
 
 func (c *ProcessTransactionConsumer) Start() {
 	msgChan := make(chan *kafka.Message)
-	go c.kafkaBroker.Consume([]string{"process-transaction"}, msgChan)
+	go c.kafkaBroker.Consume(domain.PaymentMethods.Topics(), msgChan)
 
 	for msg := range msgChan {
 		go c.processMessage(msg)
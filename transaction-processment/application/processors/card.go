@@ -0,0 +1,31 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+// CardProcessor abre o cartão no cofre e confere a validade antes de autorizar no adquirente.
+// Captura e cancelamento usam a autorização já existente e não precisam dos dados do cartão.
+// Os dados em claro ficam só neste adaptador e nunca voltam para a transação nem para os logs.
+type CardProcessor struct {
+	Detokenizer *cardvault.Detokenizer
+	Next        Processor
+}
+
+func (p *CardProcessor) Process(ctx context.Context, transaction *domain.Transaction) error {
+	operation := transaction.ProcessingOperation()
+	if transaction.CardToken != "" && (operation == domain.OperationSale || operation == domain.OperationAuthorize) {
+		card, err := p.Detokenizer.Detokenize(ctx, transaction.CardToken)
+		if err != nil {
+			return err
+		}
+		if card.Expired(time.Now()) {
+			return cardvault.ErrCardExpired
+		}
+	}
+	return p.Next.Process(ctx, transaction)
+}
@@ -0,0 +1,29 @@
+package processors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NathanGdS/transaction-hub/pkg/pixkey"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+var ErrPixKeyTypeMismatch = errors.New("tipo da chave Pix não confere com a chave informada")
+
+// PixProcessor revalida a chave Pix do recebedor antes de repassar a transação ao adquirente
+type PixProcessor struct {
+	Next Processor
+}
+
+func (p *PixProcessor) Process(ctx context.Context, transaction *domain.Transaction) error {
+	if transaction.PixKey != "" {
+		key, err := pixkey.Parse(transaction.PixKey)
+		if err != nil {
+			return err
+		}
+		if transaction.PixKeyType != "" && transaction.PixKeyType != key.Type {
+			return ErrPixKeyTypeMismatch
+		}
+	}
+	return p.Next.Process(ctx, transaction)
+}
@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+)
+
+var ErrUnsupportedPaymentMethod = errors.New("meio de pagamento não suportado pelo processador")
+
+// Processor é o adaptador responsável por processar um meio de pagamento
+type Processor interface {
+	Process(ctx context.Context, transaction *domain.Transaction) error
+}
+
+// SimulatedProcessor simula a latência de um adquirente sorteando até MaxLatency segundos
+type SimulatedProcessor struct {
+	MaxLatency int
+}
+
+func (p *SimulatedProcessor) Process(ctx context.Context, transaction *domain.Transaction) error {
+	latency := time.Duration(rand.IntN(p.MaxLatency)) * time.Second
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(latency):
+		return nil
+	}
+}
+
+// Router escolhe o processador de acordo com o meio de pagamento da transação
+type Router struct {
+	processors map[string]Processor
+}
+
+func NewRouter() *Router {
+	return &Router{processors: make(map[string]Processor)}
+}
+
+// NewDefaultRouter registra um processador simulado para cada meio de pagamento do registro do domínio.
+// Com o detokenizer (cofre configurado), os cartões são abertos e conferidos antes do processamento.
+func NewDefaultRouter(detokenizer *cardvault.Detokenizer) *Router {
+	router := NewRouter()
+	for _, method := range domain.PaymentMethods.All() {
+		var processor Processor = &SimulatedProcessor{MaxLatency: 5}
+		switch method.Code {
+		case domain.PaymentMethodPIX:
+			processor = &PixProcessor{Next: processor}
+		case domain.PaymentMethodCreditCard, domain.PaymentMethodDebitCard:
+			if detokenizer != nil {
+				processor = &CardProcessor{Detokenizer: detokenizer, Next: processor}
+			}
+		}
+		router.Register(method.Code, processor)
+	}
+	return router
+}
+
+func (r *Router) Register(paymentMethod string, processor Processor) {
+	r.processors[paymentMethod] = processor
+}
+
+func (r *Router) Route(paymentMethod string) (Processor, error) {
+	processor, ok := r.processors[paymentMethod]
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+	return processor, nil
+}
@@ -4,102 +4,243 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"math/rand/v2"
+	"sync"
 	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/akafka"
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
 	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-processment/application/processors"
 	"go.uber.org/zap"
 )
 
+var ErrTransactionCancelled = errors.New("transação cancelada a pedido do merchant")
+
+// ProcessConfig define por quanto tempo o serviço guarda o que já viu de cada transação
+type ProcessConfig struct {
+	// CancelTTL é por quanto tempo um pedido de cancelamento espera a mensagem de processamento chegar
+	CancelTTL time.Duration
+	// OutcomeTTL é por quanto tempo o resultado de uma etapa concluída é lembrado; uma republicação
+	// dentro desse prazo recebe o mesmo resultado, sem processar de novo
+	OutcomeTTL time.Duration
+}
+
+// processOutcome é o resultado publicado para uma etapa concluída da transação
+type processOutcome struct {
+	status   string
+	errorMsg string
+	at       time.Time
+}
+
 type ProcessTransactionService struct {
 	kafkaBroker akafka.KafkaBroker
 	logger      *zap.Logger
+	router      *processors.Router
+	config      ProcessConfig
+
+	mu        sync.Mutex
+	inFlight  map[string]context.CancelCauseFunc
+	cancelled map[string]time.Time
+	outcomes  map[string]processOutcome
+	lastPrune time.Time
 }
 
-func NewProcessTransactionService(kafkaBroker akafka.KafkaBroker) *ProcessTransactionService {
-	return &ProcessTransactionService{kafkaBroker: kafkaBroker, logger: logger.Log}
+func NewProcessTransactionService(kafkaBroker akafka.KafkaBroker, router *processors.Router, config ProcessConfig) *ProcessTransactionService {
+	return &ProcessTransactionService{
+		kafkaBroker: kafkaBroker,
+		logger:      logger.Log,
+		router:      router,
+		config:      config,
+		inFlight:    make(map[string]context.CancelCauseFunc),
+		cancelled:   make(map[string]time.Time),
+		outcomes:    make(map[string]processOutcome),
+	}
 }
 
-func (s *ProcessTransactionService) ProcessTransaction(ctx context.Context, transaction *domain.Transaction) error {
-	s.logger.Info("processando transação",
-		zap.Any("transaction", transaction),
-	)
+// CancelTransaction interrompe o processamento em andamento da transação pelo contexto. Se ele ainda não
+// começou, o pedido fica guardado por CancelTTL e a transação é cancelada assim que a mensagem chegar.
+// Pedidos mais antigos que CancelTTL são descartados.
+func (s *ProcessTransactionService) CancelTransaction(transactionID string, requestedAt time.Time) {
+	now := time.Now()
+	if now.Sub(requestedAt) > s.config.CancelTTL {
+		return
+	}
 
-	// 4 segundos para termos alguns erros de timeout
-	ctx, cancel := context.WithTimeout(ctx, 4*time.Second)
-	defer cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, at := range s.cancelled {
+		if now.Sub(at) > s.config.CancelTTL {
+			delete(s.cancelled, id)
+		}
+	}
+	s.cancelled[transactionID] = requestedAt
 
-	errChan := make(chan error, 1)
-	done := make(chan struct{})
+	if cancel, ok := s.inFlight[transactionID]; ok {
+		cancel(ErrTransactionCancelled)
+		s.logger.Info("processamento em andamento cancelado",
+			zap.String("id", transactionID),
+		)
+	}
+}
 
-	go func() {
-		defer close(done)
+// admission é o que fazer com uma mensagem de processamento recebida
+type admission int
 
-		select {
-		case <-ctx.Done():
-			errChan <- ctx.Err()
-			return
-		default:
-			// Simulação de processamento
-			time.Sleep(time.Duration(rand.IntN(5)) * time.Second)
+const (
+	admitProcess admission = iota
+	admitDuplicate
+	admitReplay
+	admitCancelled
+)
 
-			if ctx.Err() != nil {
-				errChan <- ctx.Err()
-				return
-			}
+// outcomeKey identifica a etapa: a mesma transação passa por AUTHORIZE e depois CAPTURE ou VOID
+func outcomeKey(transaction *domain.Transaction) string {
+	return transaction.ID + "/" + transaction.ProcessingOperation()
+}
 
-			go s.publishResult(transaction, dto.TransactionStatusProcessed, "")
+// begin decide, sob o lock, se a mensagem é processada. Etapas já concluídas devolvem o resultado
+// lembrado, mensagens repetidas durante o processamento são ignoradas e transações com cancelamento
+// pedido não são processadas. O resultado lembrado vem antes do pedido de cancelamento: uma republicação
+// com cancelRequestedAt de uma transação já cobrada recebe a cobrança, e não CANCELLED.
+func (s *ProcessTransactionService) begin(transaction *domain.Transaction, cancel context.CancelCauseFunc) (admission, processOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			errChan <- nil
-		}
-	}()
-
-	select {
-	case err := <-errChan:
-		<-done
-		if err == nil {
-			s.logger.Info("transação processada com sucesso",
-				zap.Any("transaction", transaction),
-			)
-			return nil
+	now := time.Now()
+	if now.Sub(s.lastPrune) > time.Minute {
+		for key, outcome := range s.outcomes {
+			if now.Sub(outcome.at) > s.config.OutcomeTTL {
+				delete(s.outcomes, key)
+			}
 		}
+		s.lastPrune = now
+	}
 
-		errorMsg := err.Error()
-		if err == context.DeadlineExceeded {
-			errorMsg = "timeout ao processar transação"
+	if outcome, ok := s.outcomes[outcomeKey(transaction)]; ok && now.Sub(outcome.at) <= s.config.OutcomeTTL {
+		return admitReplay, outcome
+	}
+	if _, ok := s.inFlight[transaction.ID]; ok {
+		return admitDuplicate, processOutcome{}
+	}
+	if transaction.Status == domain.TransactionPending {
+		if _, ok := s.cancelled[transaction.ID]; ok || transaction.CancelRequestedAt != nil {
+			// lembrado como as conclusões, para uma republicação receber o mesmo CANCELLED
+			s.outcomes[outcomeKey(transaction)] = processOutcome{status: dto.TransactionStatusCancelled, errorMsg: ErrTransactionCancelled.Error(), at: now}
+			return admitCancelled, processOutcome{}
 		}
+	}
+	s.inFlight[transaction.ID] = cancel
+	return admitProcess, processOutcome{}
+}
 
-		s.logger.Error(errorMsg,
-			zap.Any("transaction", transaction),
-		)
+// finish libera a transação e lembra o resultado das etapas concluídas. Falhas não são lembradas:
+// depois de uma captura ou cancelamento da reserva recusados, o ledger pode pedir a mesma etapa de novo.
+func (s *ProcessTransactionService) finish(transaction *domain.Transaction, status, errorMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		if publishErr := s.publishResult(transaction, dto.TransactionStatusFailed, errorMsg); publishErr != nil {
-			return errors.Join(err, publishErr)
-		}
+	delete(s.inFlight, transaction.ID)
+	if status != dto.TransactionStatusFailed {
+		s.outcomes[outcomeKey(transaction)] = processOutcome{status: status, errorMsg: errorMsg, at: time.Now()}
+	}
+}
 
-		return err
+// ProcessTransaction publica um único resultado por mensagem. Quando o cancelamento disputa com o fim
+// do processamento, vale a resposta do processador: se ele concluiu, a transação é processada mesmo com
+// o pedido; o cancelamento só vence se interromper o processamento antes da resposta.
+func (s *ProcessTransactionService) ProcessTransaction(ctx context.Context, transaction *domain.Transaction) error {
+	s.logger.Info("processando transação",
+		zap.String("operation", transaction.ProcessingOperation()),
+		zap.Any("transaction", transaction),
+	)
+
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	defer cancelCause(nil)
+
+	admission, outcome := s.begin(transaction, cancelCause)
+	switch admission {
+	case admitDuplicate:
+		s.logger.Warn("mensagem repetida ignorada: etapa já em processamento",
+			zap.String("id", transaction.ID),
+			zap.String("operation", transaction.ProcessingOperation()),
+		)
+		return nil
+	case admitReplay:
+		// o retorno anterior pode ter se perdido; republica o mesmo resultado em vez de processar de novo
+		s.logger.Warn("etapa já processada, resultado republicado",
+			zap.String("id", transaction.ID),
+			zap.String("operation", transaction.ProcessingOperation()),
+			zap.String("status", outcome.status),
+		)
+		return s.publishResult(transaction, outcome.status, outcome.errorMsg)
+	case admitCancelled:
+		s.logger.Info("transação cancelada antes do processamento",
+			zap.String("id", transaction.ID),
+		)
+		return s.publishResult(transaction, dto.TransactionStatusCancelled, ErrTransactionCancelled.Error())
+	}
 
-	case <-ctx.Done():
-		<-done
-		errorMsg := "timeout ao processar transação"
+	status, errorMsg, err := s.run(ctx, transaction)
+	s.finish(transaction, status, errorMsg)
+
+	switch {
+	case err == nil:
+		s.logger.Info("transação processada com sucesso",
+			zap.Any("transaction", transaction),
+		)
+	case errors.Is(err, ErrTransactionCancelled):
+		s.logger.Info("transação cancelada durante o processamento",
+			zap.String("id", transaction.ID),
+		)
+	default:
 		s.logger.Error(errorMsg,
 			zap.Any("transaction", transaction),
 		)
+	}
 
-		if publishErr := s.publishResult(transaction, dto.TransactionStatusFailed, errorMsg); publishErr != nil {
-			return errors.Join(ctx.Err(), publishErr)
-		}
+	if publishErr := s.publishResult(transaction, status, errorMsg); publishErr != nil {
+		return errors.Join(err, publishErr)
+	}
+	return err
+}
 
-		return ctx.Err()
+// run executa a etapa e traduz o erro no status publicado. Se o processador concluiu, a conclusão vale
+// mesmo que o cancelamento tenha chegado no meio.
+func (s *ProcessTransactionService) run(ctx context.Context, transaction *domain.Transaction) (string, string, error) {
+	// 4 segundos para termos alguns erros de timeout
+	ctx, cancel := context.WithTimeout(ctx, 4*time.Second)
+	defer cancel()
+
+	err := s.process(ctx, transaction)
+	switch {
+	case err == nil:
+		return successStatus(transaction.ProcessingOperation()), "", nil
+	case errors.Is(context.Cause(ctx), ErrTransactionCancelled):
+		return dto.TransactionStatusCancelled, ErrTransactionCancelled.Error(), ErrTransactionCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return dto.TransactionStatusFailed, "timeout ao processar transação", err
 	}
+	return dto.TransactionStatusFailed, err.Error(), err
+}
+
+func (s *ProcessTransactionService) process(ctx context.Context, transaction *domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	processor, err := s.router.Route(transaction.PaymentMethod)
+	if err != nil {
+		return err
+	}
+	return processor.Process(ctx, transaction)
 }
 
 func (s *ProcessTransactionService) publishResult(transaction *domain.Transaction, status string, errorMsg string) error {
 	processTransactionDto := dto.ProcessTransactionDto{
 		TransactionID: transaction.ID,
+		Operation:     transaction.ProcessingOperation(),
 		Status:        status,
 		ErrorMessage:  errorMsg,
 	}
@@ -121,3 +262,16 @@ func (s *ProcessTransactionService) publishResult(transaction *domain.Transactio
 
 	return nil
 }
+
+// successStatus é o status de retorno de cada etapa do fluxo de autorização e captura
+func successStatus(operation string) string {
+	switch operation {
+	case domain.OperationAuthorize:
+		return dto.TransactionStatusAuthorized
+	case domain.OperationCapture:
+		return dto.TransactionStatusCaptured
+	case domain.OperationVoid:
+		return dto.TransactionStatusVoided
+	}
+	return dto.TransactionStatusProcessed
+}
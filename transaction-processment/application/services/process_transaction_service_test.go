@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain/dto"
+	"github.com/NathanGdS/transaction-hub/transaction-processment/application/processors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingKafkaBroker struct {
+	akafka.KafkaBroker
+
+	mu      sync.Mutex
+	results []dto.ProcessTransactionDto
+}
+
+func (r *recordingKafkaBroker) Publish(topic string, message []byte) error {
+	var result dto.ProcessTransactionDto
+	if err := json.Unmarshal(message, &result); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+	return nil
+}
+
+// countingProcessor conta as chamadas; com release, cada chamada espera o canal ou o contexto
+type countingProcessor struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *countingProcessor) Process(ctx context.Context, transaction *domain.Transaction) error {
+	p.calls.Add(1)
+	if p.release == nil {
+		return nil
+	}
+	p.started <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.release:
+		return nil
+	}
+}
+
+// stubbornProcessor ignora o contexto, como um adquirente que já respondeu quando o cancelamento chega
+type stubbornProcessor struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *stubbornProcessor) Process(ctx context.Context, transaction *domain.Transaction) error {
+	p.started <- struct{}{}
+	<-p.release
+	return nil
+}
+
+func newTestService(processor processors.Processor) (*ProcessTransactionService, *recordingKafkaBroker) {
+	router := processors.NewRouter()
+	router.Register(domain.PaymentMethodPIX, processor)
+	broker := &recordingKafkaBroker{}
+	return NewProcessTransactionService(broker, router, ProcessConfig{CancelTTL: time.Minute, OutcomeTTL: time.Minute}), broker
+}
+
+func TestProcessTransactionService_ProcessTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should process a step once and republish its result when the message is delivered again", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{}
+		service, broker := newTestService(processor)
+
+		// Act
+		firstErr := service.ProcessTransaction(ctx, &domain.Transaction{ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX})
+		repeatedErr := service.ProcessTransaction(ctx, &domain.Transaction{ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX})
+
+		// Assert
+		require.NoError(t, firstErr)
+		require.NoError(t, repeatedErr)
+		assert.Equal(t, int32(1), processor.calls.Load())
+		require.Len(t, broker.results, 2)
+		assert.Equal(t, dto.TransactionStatusProcessed, broker.results[0].Status)
+		assert.Equal(t, broker.results[0], broker.results[1])
+	})
+
+	t.Run("Should ignore a repeated message while the step is still running", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{started: make(chan struct{}), release: make(chan struct{})}
+		service, broker := newTestService(processor)
+		done := make(chan error)
+		go func() {
+			done <- service.ProcessTransaction(ctx, &domain.Transaction{ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX})
+		}()
+		<-processor.started
+
+		// Act
+		repeatedErr := service.ProcessTransaction(ctx, &domain.Transaction{ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX})
+		close(processor.release)
+
+		// Assert
+		require.NoError(t, repeatedErr)
+		require.NoError(t, <-done)
+		assert.Equal(t, int32(1), processor.calls.Load())
+		require.Len(t, broker.results, 1)
+		assert.Equal(t, dto.TransactionStatusProcessed, broker.results[0].Status)
+	})
+}
+
+func TestProcessTransactionService_CancelTransaction(t *testing.T) {
+	ctx := context.Background()
+	pending := func() *domain.Transaction {
+		return &domain.Transaction{ID: "tx-1", Status: domain.TransactionPending, PaymentMethod: domain.PaymentMethodPIX}
+	}
+
+	t.Run("Should cancel without processing when the request arrives before the transaction", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{}
+		service, broker := newTestService(processor)
+		service.CancelTransaction("tx-1", time.Now())
+
+		// Act
+		err := service.ProcessTransaction(ctx, pending())
+		repeatedErr := service.ProcessTransaction(ctx, pending())
+
+		// Assert
+		require.NoError(t, err)
+		require.NoError(t, repeatedErr)
+		assert.Zero(t, processor.calls.Load())
+		require.Len(t, broker.results, 2)
+		assert.Equal(t, dto.TransactionStatusCancelled, broker.results[0].Status)
+		assert.Equal(t, dto.TransactionStatusCancelled, broker.results[1].Status)
+	})
+
+	t.Run("Should cancel a redriven transaction that carries the request", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{}
+		service, broker := newTestService(processor)
+		transaction := pending()
+		requestedAt := time.Now()
+		transaction.CancelRequestedAt = &requestedAt
+
+		// Act
+		err := service.ProcessTransaction(ctx, transaction)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, processor.calls.Load())
+		require.Len(t, broker.results, 1)
+		assert.Equal(t, dto.TransactionStatusCancelled, broker.results[0].Status)
+	})
+
+	t.Run("Should interrupt the running step through the context cause", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{started: make(chan struct{}), release: make(chan struct{})}
+		service, broker := newTestService(processor)
+		done := make(chan error)
+		go func() {
+			done <- service.ProcessTransaction(ctx, pending())
+		}()
+		<-processor.started
+
+		// Act
+		service.CancelTransaction("tx-1", time.Now())
+		err := <-done
+
+		// Assert
+		assert.ErrorIs(t, err, ErrTransactionCancelled)
+		require.Len(t, broker.results, 1)
+		assert.Equal(t, dto.TransactionStatusCancelled, broker.results[0].Status)
+		assert.Equal(t, domain.OperationSale, broker.results[0].Operation)
+	})
+
+	t.Run("Should keep the completed result when the processor finishes despite the request", func(t *testing.T) {
+		// Arrange
+		processor := &stubbornProcessor{started: make(chan struct{}), release: make(chan struct{})}
+		service, broker := newTestService(processor)
+		done := make(chan error)
+		go func() {
+			done <- service.ProcessTransaction(ctx, pending())
+		}()
+		<-processor.started
+
+		// Act
+		service.CancelTransaction("tx-1", time.Now())
+		close(processor.release)
+		err := <-done
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, broker.results, 1)
+		assert.Equal(t, dto.TransactionStatusProcessed, broker.results[0].Status)
+	})
+
+	t.Run("Should republish the charge, not CANCELLED, when a processed transaction is redriven with the request", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{}
+		service, broker := newTestService(processor)
+		require.NoError(t, service.ProcessTransaction(ctx, pending()))
+		redriven := pending()
+		requestedAt := time.Now()
+		redriven.CancelRequestedAt = &requestedAt
+		service.CancelTransaction("tx-1", requestedAt)
+
+		// Act
+		err := service.ProcessTransaction(ctx, redriven)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), processor.calls.Load())
+		require.Len(t, broker.results, 2)
+		assert.Equal(t, dto.TransactionStatusProcessed, broker.results[1].Status)
+	})
+
+	t.Run("Should ignore requests older than the tombstone TTL", func(t *testing.T) {
+		// Arrange
+		processor := &countingProcessor{}
+		service, broker := newTestService(processor)
+		service.CancelTransaction("tx-1", time.Now().Add(-time.Hour))
+
+		// Act
+		err := service.ProcessTransaction(ctx, pending())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), processor.calls.Load())
+		require.Len(t, broker.results, 1)
+		assert.Equal(t, dto.TransactionStatusProcessed, broker.results[0].Status)
+	})
+}
@@ -4,10 +4,18 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/NathanGdS/transaction-hub/pkg/akafka"
+	"github.com/NathanGdS/transaction-hub/pkg/cardvault"
+	"github.com/NathanGdS/transaction-hub/pkg/config"
 	"github.com/NathanGdS/transaction-hub/pkg/logger"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/domain"
+	"github.com/NathanGdS/transaction-hub/transaction-ledger/infra/database"
 	"github.com/NathanGdS/transaction-hub/transaction-processment/application/consumers"
+	"github.com/NathanGdS/transaction-hub/transaction-processment/application/processors"
+	"github.com/NathanGdS/transaction-hub/transaction-processment/application/services"
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -15,9 +23,18 @@ func main() {
 	kafkaBroker := akafka.NewKafkaBroker("host.docker.internal:9094")
 	defer kafkaBroker.Close()
 
-	kafkaBroker.CreateTopicsIfNotExists([]string{"process-transaction", "transaction-process-return"})
+	kafkaBroker.CreateTopicsIfNotExists(append(domain.PaymentMethods.Topics(), "transaction-process-return", domain.CancelTransactionTopic))
 
-	transactionConsumer := consumers.NewProcessTransactionConsumer(&kafkaBroker)
+	router := processors.NewDefaultRouter(newCardDetokenizer())
+	service := services.NewProcessTransactionService(kafkaBroker, router, services.ProcessConfig{
+		CancelTTL:  config.GetEnvDuration("CANCEL_TOMBSTONE_TTL", 10*time.Minute),
+		OutcomeTTL: config.GetEnvDuration("PROCESSED_OUTCOME_TTL", time.Hour),
+	})
+
+	cancelConsumer := consumers.NewCancelTransactionConsumer(&kafkaBroker, service)
+	go cancelConsumer.Start()
+
+	transactionConsumer := consumers.NewProcessTransactionConsumer(&kafkaBroker, service)
 	// usando como loop infinito da aplicação
 	transactionConsumer.Start()
 
@@ -28,3 +45,26 @@ func main() {
 	<-quit
 	logger.Log.Info("encerrando o servidor...")
 }
+
+// newCardDetokenizer só abre conexão com o banco quando o cofre de cartões está configurado
+func newCardDetokenizer() *cardvault.Detokenizer {
+	path := config.GetEnv("CARD_VAULT_PRIVATE_KEYFILE", "")
+	if path == "" {
+		return nil
+	}
+
+	keyring, err := cardvault.NewKeyringFromFile(path)
+	if err != nil {
+		logger.Log.Fatal("erro ao carregar chaves do cofre de cartões",
+			zap.Error(err),
+		)
+	}
+
+	db, err := database.NewPostgresConnection()
+	if err != nil {
+		logger.Log.Fatal("erro ao conectar ao banco de dados",
+			zap.Error(err),
+		)
+	}
+	return cardvault.NewDetokenizer(keyring, cardvault.NewGormStore(db))
+}